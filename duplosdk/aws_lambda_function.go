@@ -115,10 +115,13 @@ type DuploLambdaPermissionStatement struct {
 	Principal DuploLambdaPermissionPrincipal `json:"Principal,omitempty"`
 	Action    string                         `json:"Action,omitempty"`
 	Resource  string                         `json:"Resource,omitempty"`
+	// Condition maps an operator like ArnLike to its condition keys, e.g. AWS:SourceArn.
+	Condition map[string]map[string]string `json:"Condition,omitempty"`
 }
 
 type DuploLambdaPermissionPrincipal struct {
 	Service string `json:"Service,omitempty"`
+	AWS     string `json:"AWS,omitempty"`
 }
 
 type DuploLambdaPermissionRequest struct {
@@ -90,4 +90,8 @@ func main() {
 	log.Printf("[TRACE] |==========================================================================|")
 	log.Printf("[TRACE] Terraform projects are generated at - %s", filepath.Join("./target", config.CustomerName, config.TenantName))
 	log.Printf("[TRACE] |==========================================================================|")
+	tfGeneratorService.Report.PrintSummary()
+	if tfGeneratorService.Report.HasFatal() {
+		os.Exit(1)
+	}
 }
@@ -1,6 +1,10 @@
 package common
 
-import "github.com/aws/aws-sdk-go-v2/aws"
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
 
 type Config struct {
 	DuploHost          string
@@ -12,6 +16,10 @@ type Config struct {
 	AdminTenantDir     string
 	AwsProviderVersion string
 	TenantProject      string
+	K8sProject         string
+	K8sProviderVersion string
+	K8sDir             string
+	K8sExportServices  bool
 	GenerateTfState    bool
 	S3Backend          bool
 	S3Bucket           string
@@ -22,6 +30,8 @@ type Config struct {
 	TFVersion          string
 	AwsRegion          string
 	AwsClientConfig    aws.Config
+	EcrDataSourceRepos []string
+	Parallelism        int
 }
 
 type TFContext struct {
@@ -29,4 +39,27 @@ type TFContext struct {
 	InputVars      []VarConfig
 	OutputVars     []OutputVarConfig
 	ImportConfigs  []ImportConfig
+	References     []ResourceReference
+}
+
+// ResourceError is the failure of a generator on a single resource, Resource is the terraform name of that resource.
+// A generator failing on a resource returns it along with the context of the resources generated so far.
+type ResourceError struct {
+	Resource string
+	Cause    error
+}
+
+func (e *ResourceError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Resource, e.Cause)
+}
+
+func (e *ResourceError) Unwrap() error {
+	return e.Cause
+}
+
+func NewResourceError(resource string, cause error) error {
+	return &ResourceError{
+		Resource: resource,
+		Cause:    cause,
+	}
 }
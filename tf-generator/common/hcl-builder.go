@@ -0,0 +1,382 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Lifecycle holds the arguments of a lifecycle block.
+type Lifecycle struct {
+	CreateBeforeDestroy bool
+	IgnoreChanges       []string
+}
+
+// Template is a quoted terraform template, it keeps interpolations like ${local.tenant_name}.
+// Go strings are always written as literals, a value has to be a Template to hold interpolations.
+type Template string
+
+// Interpolation replaces the occurrences of a literal value with an interpolation of a terraform expression,
+// like the tenant name with ${local.tenant_name}.
+type Interpolation struct {
+	Value string
+	Expr  string
+}
+
+// EscapeTemplate escapes the template sequences of a literal value, like an IAM policy variable ${aws:username}.
+func EscapeTemplate(value string) string {
+	value = strings.Replace(value, "${", "$${", -1)
+	return strings.Replace(value, "%{", "%%{", -1)
+}
+
+// Interpolate returns a literal value as a template, escaping it and replacing the occurrences of the interpolation
+// values with their expressions. The longest value wins when values overlap, like a bucket arn and the account id.
+func Interpolate(value string, interpolations ...Interpolation) Template {
+	sorted := []Interpolation{}
+	for _, interpolation := range interpolations {
+		if len(interpolation.Value) > 0 {
+			sorted = append(sorted, interpolation)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i].Value) > len(sorted[j].Value)
+	})
+	template := &strings.Builder{}
+	start := 0
+	for i := 0; i < len(value); i++ {
+		for _, interpolation := range sorted {
+			if strings.HasPrefix(value[i:], interpolation.Value) {
+				template.WriteString(EscapeTemplate(value[start:i]))
+				template.WriteString("${" + interpolation.Expr + "}")
+				i += len(interpolation.Value) - 1
+				start = i + 1
+				break
+			}
+		}
+	}
+	template.WriteString(EscapeTemplate(value[start:]))
+	return Template(template.String())
+}
+
+// InterpolateValues returns a decoded json value with its strings turned into templates, see Interpolate.
+// Map keys stay literals.
+func InterpolateValues(value interface{}, interpolations ...Interpolation) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := map[string]interface{}{}
+		for key, child := range v {
+			result[key] = InterpolateValues(child, interpolations...)
+		}
+		return result
+	case []interface{}:
+		result := []interface{}{}
+		for _, child := range v {
+			result = append(result, InterpolateValues(child, interpolations...))
+		}
+		return result
+	case string:
+		return Interpolate(v, interpolations...)
+	default:
+		return value
+	}
+}
+
+// TokensForExpression returns the tokens of a terraform expression, like a type constraint, a function call or a condition.
+func TokensForExpression(expr string) (hclwrite.Tokens, error) {
+	tokens, err := parseExpression(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %s", expr, err)
+	}
+	return tokens, nil
+}
+
+// TokensForReference returns the tokens of a reference, like var.region or aws_s3_bucket.name.arn.
+func TokensForReference(reference string) (hclwrite.Tokens, error) {
+	traversal, diags := hclsyntax.ParseTraversalAbs([]byte(reference), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("invalid reference %q: %s", reference, diags.Error())
+	}
+	return hclwrite.TokensForTraversal(traversal), nil
+}
+
+// TokensForReferenceList returns the tokens of a list of references, like an ignore_changes list.
+func TokensForReferenceList(references []string) (hclwrite.Tokens, error) {
+	elems := []hclwrite.Tokens{}
+	for _, reference := range references {
+		tokens, err := TokensForReference(reference)
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, tokens)
+	}
+	return hclwrite.TokensForTuple(elems), nil
+}
+
+// TokensForTemplate returns the tokens of a quoted template string.
+// Interpolations are kept, quotes, backslashes and control characters are escaped.
+func TokensForTemplate(template Template) (hclwrite.Tokens, error) {
+	tokens, err := parseExpression(quoteTemplate(string(template)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid template %q: %s", template, err)
+	}
+	return tokens, nil
+}
+
+// TokensForJsonencode returns the tokens of a jsonencode call of a go value, like a decoded policy document.
+// Strings of the value are written as literals, templates of the value keep their interpolations.
+func TokensForJsonencode(value interface{}) (hclwrite.Tokens, error) {
+	normalized, err := normalizeJsonValue(value)
+	if err != nil {
+		return nil, err
+	}
+	src := &strings.Builder{}
+	writeExpression(src, normalized)
+	tokens, err := parseExpression(src.String())
+	if err != nil {
+		return nil, err
+	}
+	return hclwrite.TokensForFunctionCall("jsonencode", tokens), nil
+}
+
+// TokensForTags returns the tokens of a tags map sorted by key, the keys are written as literals.
+func TokensForTags(tags map[string]Template) (hclwrite.Tokens, error) {
+	keys := []string{}
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	attrs := []hclwrite.ObjectAttrTokens{}
+	for _, key := range keys {
+		value, err := TokensForTemplate(tags[key])
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, hclwrite.ObjectAttrTokens{
+			Name:  hclwrite.TokensForValue(cty.StringVal(key)),
+			Value: value,
+		})
+	}
+	return hclwrite.TokensForObject(attrs), nil
+}
+
+// SetAttributeExpression sets an attribute to an expression, see TokensForExpression.
+func SetAttributeExpression(body *hclwrite.Body, name string, expr string) error {
+	tokens, err := TokensForExpression(expr)
+	if err != nil {
+		return err
+	}
+	body.SetAttributeRaw(name, tokens)
+	return nil
+}
+
+// SetAttributeReference sets an attribute to a reference, see TokensForReference.
+func SetAttributeReference(body *hclwrite.Body, name string, reference string) error {
+	tokens, err := TokensForReference(reference)
+	if err != nil {
+		return err
+	}
+	body.SetAttributeRaw(name, tokens)
+	return nil
+}
+
+// SetAttributeReferenceList sets an attribute to a list of references.
+func SetAttributeReferenceList(body *hclwrite.Body, name string, references []string) error {
+	tokens, err := TokensForReferenceList(references)
+	if err != nil {
+		return err
+	}
+	body.SetAttributeRaw(name, tokens)
+	return nil
+}
+
+// SetAttributeTemplate sets an attribute to a quoted template, see TokensForTemplate.
+func SetAttributeTemplate(body *hclwrite.Body, name string, template Template) error {
+	tokens, err := TokensForTemplate(template)
+	if err != nil {
+		return err
+	}
+	body.SetAttributeRaw(name, tokens)
+	return nil
+}
+
+// SetAttributeJsonencode sets an attribute to a jsonencode call of a go value, see TokensForJsonencode.
+func SetAttributeJsonencode(body *hclwrite.Body, name string, value interface{}) error {
+	tokens, err := TokensForJsonencode(value)
+	if err != nil {
+		return err
+	}
+	body.SetAttributeRaw(name, tokens)
+	return nil
+}
+
+// SetAttributeTags sets a tags attribute, see TokensForTags.
+func SetAttributeTags(body *hclwrite.Body, name string, tags map[string]Template) error {
+	tokens, err := TokensForTags(tags)
+	if err != nil {
+		return err
+	}
+	body.SetAttributeRaw(name, tokens)
+	return nil
+}
+
+// AppendLifecycleBlock appends a lifecycle block, references in IgnoreChanges are attribute names like user_data.
+func AppendLifecycleBlock(body *hclwrite.Body, lifecycle Lifecycle) error {
+	lifecycleBody := body.AppendNewBlock("lifecycle",
+		nil).Body()
+	if lifecycle.CreateBeforeDestroy {
+		lifecycleBody.SetAttributeValue("create_before_destroy",
+			cty.BoolVal(true))
+	}
+	if len(lifecycle.IgnoreChanges) > 0 {
+		return SetAttributeReferenceList(lifecycleBody, "ignore_changes", lifecycle.IgnoreChanges)
+	}
+	return nil
+}
+
+// normalizeJsonValue converts a go value to the types of a decoded json document, keeping its templates.
+// Structs and typed maps and slices are round tripped through json.
+func normalizeJsonValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil, Template, string, bool, json.Number:
+		return v, nil
+	case map[string]interface{}:
+		result := map[string]interface{}{}
+		for key, child := range v {
+			normalized, err := normalizeJsonValue(child)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = normalized
+		}
+		return result, nil
+	case []interface{}:
+		result := []interface{}{}
+		for _, child := range v {
+			normalized, err := normalizeJsonValue(child)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, normalized)
+		}
+		return result, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var normalized interface{}
+	if err := decoder.Decode(&normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// parseExpression parses an expression by parsing it as the value of an attribute.
+func parseExpression(expr string) (hclwrite.Tokens, error) {
+	src := hclwrite.Format([]byte("value = " + expr + "\n"))
+	file, diags := hclwrite.ParseConfig(src, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	// hclwrite does not validate expressions, hclsyntax does.
+	if _, diags := hclsyntax.ParseConfig(src, "", hcl.InitialPos); diags.HasErrors() {
+		return nil, diags
+	}
+	attr := file.Body().GetAttribute("value")
+	if attr == nil {
+		return nil, fmt.Errorf("expression %s is not a single value", expr)
+	}
+	return attr.Expr().BuildTokens(nil), nil
+}
+
+// quoteTemplate quotes a template, sequences starting with ${ or %{ are copied as they are.
+func quoteTemplate(template string) string {
+	quoted := &strings.Builder{}
+	quoted.WriteByte('"')
+	for i := 0; i < len(template); {
+		rest := template[i:]
+		if strings.HasPrefix(rest, "$${") || strings.HasPrefix(rest, "%%{") {
+			quoted.WriteString(rest[:3])
+			i += 3
+			continue
+		}
+		if strings.HasPrefix(rest, "${") || strings.HasPrefix(rest, "%{") {
+			if end := strings.IndexByte(rest, '}'); end > 0 {
+				quoted.WriteString(rest[:end+1])
+				i += end + 1
+				continue
+			}
+			// An unterminated sequence is a literal.
+			quoted.WriteString(rest[:1] + rest[:2])
+			i += 2
+			continue
+		}
+		switch c := template[i]; c {
+		case '"':
+			quoted.WriteString(`\"`)
+		case '\\':
+			quoted.WriteString(`\\`)
+		case '\n':
+			quoted.WriteString(`\n`)
+		case '\r':
+			quoted.WriteString(`\r`)
+		case '\t':
+			quoted.WriteString(`\t`)
+		default:
+			if c < 0x20 {
+				fmt.Fprintf(quoted, `\u%04x`, c)
+			} else {
+				quoted.WriteByte(c)
+			}
+		}
+		i++
+	}
+	quoted.WriteByte('"')
+	return quoted.String()
+}
+
+// writeExpression writes a normalized json value as an expression, object keys are sorted.
+func writeExpression(src *strings.Builder, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := []string{}
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		src.WriteString("{\n")
+		for _, key := range keys {
+			src.WriteString(quoteTemplate(EscapeTemplate(key)))
+			src.WriteString(" = ")
+			writeExpression(src, v[key])
+			src.WriteString("\n")
+		}
+		src.WriteString("}")
+	case []interface{}:
+		src.WriteString("[\n")
+		for _, elem := range v {
+			writeExpression(src, elem)
+			src.WriteString(",\n")
+		}
+		src.WriteString("]")
+	case Template:
+		src.WriteString(quoteTemplate(string(v)))
+	case string:
+		src.WriteString(quoteTemplate(EscapeTemplate(v)))
+	case json.Number:
+		src.WriteString(v.String())
+	case bool:
+		fmt.Fprintf(src, "%t", v)
+	default:
+		src.WriteString("null")
+	}
+}
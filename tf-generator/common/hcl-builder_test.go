@@ -0,0 +1,252 @@
+package common
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+// evalTokens evaluates generated tokens the way terraform would, with a few locals and jsonencode.
+func evalTokens(t *testing.T, tokens hclwrite.Tokens) string {
+	t.Helper()
+	expr, diags := hclsyntax.ParseExpression(tokens.Bytes(), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("generated expression %s does not parse: %s", tokens.Bytes(), diags)
+	}
+	val, diags := expr.Value(&hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"local": cty.ObjectVal(map[string]cty.Value{
+				"tenant_name": cty.StringVal("test"),
+				"account_id":  cty.StringVal("999999999999"),
+			}),
+			"aws_s3_bucket": cty.ObjectVal(map[string]cty.Value{
+				"logs": cty.ObjectVal(map[string]cty.Value{
+					"arn": cty.StringVal("arn:aws:s3:::logs-999999999999"),
+				}),
+			}),
+		},
+		Functions: map[string]function.Function{
+			"jsonencode": stdlib.JSONEncodeFunc,
+		},
+	})
+	if diags.HasErrors() {
+		t.Fatalf("generated expression %s does not evaluate: %s", tokens.Bytes(), diags)
+	}
+	return val.AsString()
+}
+
+var literalTestCases = []struct {
+	name  string
+	value string
+}{
+	{"plain", "duploservices-test"},
+	{"quotes", `say "hello"`},
+	{"backslashes", `C:\temp\ and \"`},
+	{"interpolation", "arn:aws:s3:::${aws:username}/*"},
+	{"directive", "%{if true}yes%{endif}"},
+	{"escaped interpolation", "$${already} and %%{escaped}"},
+	{"unterminated", "ends with ${ and %{"},
+	{"dollars", "$$ ${} $"},
+	{"control characters", "line\nbreak\ttab\r\x01"},
+	{"unicode", "naïve ☃"},
+}
+
+func TestTokensForTemplateKeepsLiterals(t *testing.T) {
+	for _, tc := range literalTestCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tokens, err := TokensForTemplate(Interpolate(tc.value))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := evalTokens(t, tokens); got != tc.value {
+				t.Errorf("got %q, want %q", got, tc.value)
+			}
+		})
+	}
+}
+
+func TestTokensForJsonencodeKeepsLiterals(t *testing.T) {
+	for _, tc := range literalTestCases {
+		t.Run(tc.name, func(t *testing.T) {
+			value := map[string]interface{}{
+				tc.value: []interface{}{tc.value, map[string]interface{}{"nested": tc.value}},
+			}
+			tokens, err := TokensForJsonencode(value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got interface{}
+			if err := json.Unmarshal([]byte(evalTokens(t, tokens)), &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, value) {
+				t.Errorf("got %#v, want %#v", got, value)
+			}
+		})
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	interpolations := []Interpolation{
+		{Value: "999999999999", Expr: "local.account_id"},
+		{Value: "arn:aws:s3:::logs-999999999999", Expr: "aws_s3_bucket.logs.arn"},
+		{Value: "test", Expr: "local.tenant_name"},
+		{Value: "", Expr: "local.ignored"},
+	}
+	cases := []struct {
+		value string
+		want  Template
+	}{
+		{"duploservices-test", "duploservices-${local.tenant_name}"},
+		{"arn:aws:s3:::logs-999999999999/*", "${aws_s3_bucket.logs.arn}/*"},
+		{"arn:aws:iam::999999999999:role/${aws:username}", "arn:aws:iam::${local.account_id}:role/$${aws:username}"},
+		{"no match", "no match"},
+		{"", ""},
+	}
+	for _, tc := range cases {
+		got := Interpolate(tc.value, interpolations...)
+		if got != tc.want {
+			t.Errorf("Interpolate(%q) = %q, want %q", tc.value, got, tc.want)
+			continue
+		}
+		tokens, err := TokensForTemplate(got)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if evaluated := evalTokens(t, tokens); evaluated != tc.value {
+			t.Errorf("template %q evaluates to %q, want %q", got, evaluated, tc.value)
+		}
+	}
+}
+
+func TestTokensForJsonencodeNestedTemplates(t *testing.T) {
+	policy := map[string]interface{}{
+		"Version": "2012-10-17",
+		"Statement": []interface{}{
+			map[string]interface{}{
+				"Effect":   "Allow",
+				"Resource": []interface{}{"arn:aws:s3:::logs-999999999999", "arn:aws:s3:::logs-999999999999/${aws:username}/*"},
+				"Condition": map[string]interface{}{
+					"StringEquals": map[string]interface{}{"aws:SourceAccount": "999999999999"},
+				},
+				"Count": json.Number("2"),
+				"Flag":  true,
+				"Empty": nil,
+			},
+		},
+	}
+	templated := InterpolateValues(policy,
+		Interpolation{Value: "arn:aws:s3:::logs-999999999999", Expr: "aws_s3_bucket.logs.arn"},
+		Interpolation{Value: "999999999999", Expr: "local.account_id"})
+	tokens, err := TokensForJsonencode(templated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := string(tokens.Bytes())
+	for _, want := range []string{`"${aws_s3_bucket.logs.arn}"`, `"${aws_s3_bucket.logs.arn}/$${aws:username}/*"`, `"${local.account_id}"`} {
+		if !strings.Contains(src, want) {
+			t.Errorf("expected %s in %s", want, src)
+		}
+	}
+	var got, want interface{}
+	if err := json.Unmarshal([]byte(evalTokens(t, tokens)), &got); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(policy)
+	if err := json.Unmarshal(data, &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestTokensForTags(t *testing.T) {
+	tokens, err := TokensForTags(map[string]Template{
+		"Name":      Interpolate("duploservices-test-app", Interpolation{Value: "test", Expr: "local.tenant_name"}),
+		"${weird}":  Interpolate(`"quoted"`),
+		"aws:owner": "",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "$${weird}" = "\"quoted\""
+  "Name"      = "duploservices-${local.tenant_name}-app"
+  "aws:owner" = ""
+}`
+	if got := string(hclwrite.Format(tokens.Bytes())); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestTokensForReference(t *testing.T) {
+	cases := []struct {
+		reference string
+		valid     bool
+	}{
+		{"var.region", true},
+		{"local.tenant_name", true},
+		{"aws_s3_bucket.logs.arn", true},
+		{"data.aws_iam_role.app.arn", true},
+		{"aws_cloudfront_distribution.*_example_com.domain_name", false},
+		{"aws_s3_bucket.logs.arn}", false},
+		{"${var.region}", false},
+		{"var.region + 1", false},
+		{"", false},
+	}
+	for _, tc := range cases {
+		tokens, err := TokensForReference(tc.reference)
+		if !tc.valid {
+			if err == nil {
+				t.Errorf("expected an error for %q, got %s", tc.reference, tokens.Bytes())
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error for %q: %s", tc.reference, err)
+			continue
+		}
+		if got := string(tokens.Bytes()); got != tc.reference {
+			t.Errorf("got %s, want %s", got, tc.reference)
+		}
+	}
+
+	if _, err := TokensForReferenceList([]string{"user_data", "tags.*"}); err == nil {
+		t.Errorf("expected an error for an invalid reference in a list")
+	}
+	if err := AppendLifecycleBlock(hclwrite.NewEmptyFile().Body(), Lifecycle{IgnoreChanges: []string{"user_data", "tags[\"Name\"]"}}); err != nil {
+		t.Errorf("unexpected error for a valid ignore_changes list: %s", err)
+	}
+}
+
+func TestTokensForExpression(t *testing.T) {
+	cases := []struct {
+		expr  string
+		valid bool
+	}{
+		{"map(string)", true},
+		{`{ "a" = 1 }`, true},
+		{"base64decode(var.k8s_cluster_ca_certificate)", true},
+		{"map(", false},
+		{"a = b", false},
+		{"", false},
+	}
+	for _, tc := range cases {
+		_, err := TokensForExpression(tc.expr)
+		if tc.valid && err != nil {
+			t.Errorf("unexpected error for %q: %s", tc.expr, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("expected an error for %q", tc.expr)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package common
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"tenant-native-terraform-generator/duplosdk"
 
@@ -20,7 +21,7 @@ type ImportConfig struct {
 	WorkingDir      string
 }
 
-func (i *Importer) Import(config *Config, importConfig *ImportConfig) {
+func (i *Importer) Import(config *Config, importConfig *ImportConfig) error {
 	log.Println("[TRACE] <================================== TF Import in progress. ==================================>")
 	log.Printf("[TRACE] Importing terraform resource  : (%s, %s).", importConfig.ResourceAddress, importConfig.ResourceId)
 	tfVersion := GetEnv("tf_version", "0.14.11")
@@ -31,11 +32,11 @@ func (i *Importer) Import(config *Config, importConfig *ImportConfig) {
 
 	execPath, err := installer.Install(context.Background())
 	if err != nil {
-		log.Fatalf("error installing Terraform: %s", err)
+		return fmt.Errorf("error installing Terraform: %s", err)
 	}
 	tf, err := tfexec.NewTerraform(importConfig.WorkingDir, execPath)
 	if err != nil {
-		log.Fatalf("error running NewTerraform: %s", err)
+		return fmt.Errorf("error running NewTerraform: %s", err)
 	}
 	if config.S3Backend {
 		err = tf.Init(context.Background(), tfexec.Upgrade(true), tfexec.BackendConfig("bucket="+config.S3Bucket))
@@ -44,12 +45,12 @@ func (i *Importer) Import(config *Config, importConfig *ImportConfig) {
 	}
 
 	if err != nil {
-		log.Fatalf("error running Init: %s", err)
+		return fmt.Errorf("error running Init: %s", err)
 	}
 
 	workspaceList, activeWorkspace, err := tf.WorkspaceList(context.Background())
 	if err != nil {
-		log.Fatalf("error running tf workspace list: %s", err)
+		return fmt.Errorf("error running tf workspace list: %s", err)
 	}
 	if len(workspaceList) > 0 {
 		log.Printf("[TRACE] Workspace List (%s).", workspaceList)
@@ -59,24 +60,24 @@ func (i *Importer) Import(config *Config, importConfig *ImportConfig) {
 	if duplosdk.Contains(workspaceList, config.TenantName) {
 		err = tf.WorkspaceSelect(context.Background(), config.TenantName)
 		if err != nil {
-			log.Fatalf("error running tf workspace select: %s", err)
+			return fmt.Errorf("error running tf workspace select: %s", err)
 		}
 		log.Printf("[TRACE] (%s) workspace is selected.", config.TenantName)
 	} else {
 		err := tf.WorkspaceNew(context.Background(), config.TenantName)
 		if err != nil {
-			log.Fatalf("error running tf workspace new: %s", err)
+			return fmt.Errorf("error running tf workspace new: %s", err)
 		}
 		log.Printf("[TRACE] (%s) workspace is created.", config.TenantName)
 	}
 
 	err = tf.Import(context.Background(), importConfig.ResourceAddress, importConfig.ResourceId)
 	if err != nil {
-		log.Fatalf("error running Import: %s", err)
+		return fmt.Errorf("error running Import: %s", err)
 	}
 	_, err = tf.Show(context.Background())
 	if err != nil {
-		log.Fatalf("error running Show: %s", err)
+		return fmt.Errorf("error running Show: %s", err)
 	}
 
 	//_, err = json.Marshal(state.Values)
@@ -84,19 +85,20 @@ func (i *Importer) Import(config *Config, importConfig *ImportConfig) {
 
 	log.Printf("[TRACE] Terraform resource (%s, %s) is imported.", importConfig.ResourceAddress, importConfig.ResourceId)
 	log.Println("[TRACE] <============================================================================================>")
+	return nil
 }
 
-func (i *Importer) ImportWithoutInit(config *Config, importConfig *ImportConfig, tf *tfexec.Terraform) {
+func (i *Importer) ImportWithoutInit(config *Config, importConfig *ImportConfig, tf *tfexec.Terraform) error {
 	log.Println("[TRACE] <================================== TF Import in progress. ==================================>")
 	log.Printf("[TRACE] Importing terraform resource  : (%s, %s).", importConfig.ResourceAddress, importConfig.ResourceId)
 
 	err := tf.Import(context.Background(), importConfig.ResourceAddress, importConfig.ResourceId)
 	if err != nil {
-		log.Fatalf("error running Import: %s", err)
+		return fmt.Errorf("error running Import: %s", err)
 	}
 	_, err = tf.Show(context.Background())
 	if err != nil {
-		log.Fatalf("error running Show: %s", err)
+		return fmt.Errorf("error running Show: %s", err)
 	}
 
 	//_, err = json.Marshal(state.Values)
@@ -104,4 +106,5 @@ func (i *Importer) ImportWithoutInit(config *Config, importConfig *ImportConfig,
 
 	log.Printf("[TRACE] Terraform resource (%s, %s) is imported.", importConfig.ResourceAddress, importConfig.ResourceId)
 	log.Println("[TRACE] <=============================================================================================>")
+	return nil
 }
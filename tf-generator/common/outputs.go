@@ -5,8 +5,8 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 
-	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -16,6 +16,7 @@ type OutputVarConfig struct {
 	ActualVal     string
 	DescVal       string
 	RootTraversal bool
+	Sensitive     bool
 }
 
 type OutputVars struct {
@@ -23,10 +24,28 @@ type OutputVars struct {
 	OutputVars     []OutputVarConfig
 }
 
-func (ov *OutputVars) Generate() {
+// Generate writes outputs.tf with the outputs sorted by name, an output declared twice keeps its first declaration.
+func (ov *OutputVars) Generate() error {
 	if len(ov.OutputVars) > 0 {
 		log.Println("[TRACE] <====== Output Variables TF generation started. =====>")
 
+		outputVars := make([]OutputVarConfig, 0, len(ov.OutputVars))
+		names := map[string]bool{}
+		for _, outVarConfig := range ov.OutputVars {
+			if len(outVarConfig.Name) == 0 {
+				continue
+			}
+			if names[outVarConfig.Name] {
+				log.Printf("[WARN] Output %s is declared more than once, the first declaration is kept.", outVarConfig.Name)
+				continue
+			}
+			names[outVarConfig.Name] = true
+			outputVars = append(outputVars, outVarConfig)
+		}
+		sort.SliceStable(outputVars, func(i, j int) bool {
+			return outputVars[i].Name < outputVars[j].Name
+		})
+
 		// create new empty hcl file object
 		hclFile := hclwrite.NewEmptyFile()
 		// create new file on system
@@ -34,42 +53,54 @@ func (ov *OutputVars) Generate() {
 		tfFile, err := os.Create(path)
 		if err != nil {
 			fmt.Println(err)
-			return
+			return err
 		}
 
 		// initialize the body of the new file object
 		rootBody := hclFile.Body()
-		for _, outVarConfig := range ov.OutputVars {
-			if len(outVarConfig.Name) > 0 {
-				outputVarblock := rootBody.AppendNewBlock("output",
-					[]string{outVarConfig.Name})
-				outputVarBody := outputVarblock.Body()
-				if len(outVarConfig.ActualVal) > 0 {
-					if outVarConfig.RootTraversal {
-						outputVarBody.SetAttributeTraversal("value", hcl.Traversal{
-							hcl.TraverseRoot{
-								Name: outVarConfig.ActualVal,
-							},
-						})
-					} else {
-						outputVarBody.SetAttributeValue("value",
-							cty.StringVal(outVarConfig.ActualVal))
-					}
+		// An output with an invalid value is left out, the others are still written.
+		var invalidErr error
+		for _, outVarConfig := range outputVars {
+			var valueTokens hclwrite.Tokens
+			if len(outVarConfig.ActualVal) > 0 && outVarConfig.RootTraversal {
+				valueTokens, err = TokensForExpression(outVarConfig.ActualVal)
+				if err != nil {
+					log.Printf("[WARN] Output %s is left out: %s", outVarConfig.Name, err)
+					invalidErr = fmt.Errorf("output %q: %s", outVarConfig.Name, err)
+					continue
 				}
-
-				if len(outVarConfig.DescVal) > 0 {
-					outputVarBody.SetAttributeValue("description",
-						cty.StringVal(outVarConfig.DescVal))
+			}
+			outputVarblock := rootBody.AppendNewBlock("output",
+				[]string{outVarConfig.Name})
+			outputVarBody := outputVarblock.Body()
+			if len(outVarConfig.ActualVal) > 0 {
+				if outVarConfig.RootTraversal {
+					outputVarBody.SetAttributeRaw("value", valueTokens)
+				} else {
+					outputVarBody.SetAttributeValue("value",
+						cty.StringVal(outVarConfig.ActualVal))
 				}
 			}
+
+			if len(outVarConfig.DescVal) > 0 {
+				outputVarBody.SetAttributeValue("description",
+					cty.StringVal(outVarConfig.DescVal))
+			}
+
+			if outVarConfig.Sensitive {
+				outputVarBody.SetAttributeValue("sensitive",
+					cty.BoolVal(true))
+			}
 		}
 
 		fmt.Printf("%s", hclFile.Bytes())
 		_, err = tfFile.Write(hclFile.Bytes())
 		if err != nil {
 			fmt.Println(err)
-			return
+			return err
 		}
 		log.Println("[TRACE] <====== Output Variables TF generation done. =====>")
+		return invalidErr
 	}
+	return nil
 }
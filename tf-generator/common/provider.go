@@ -6,8 +6,6 @@ import (
 	"os"
 	"path/filepath"
 
-	"github.com/hashicorp/hcl/v2"
-
 	"tenant-native-terraform-generator/duplosdk"
 
 	"github.com/hashicorp/hcl/v2/hclwrite"
@@ -18,7 +16,7 @@ import (
 type Provider struct {
 }
 
-func (p *Provider) Generate(config *Config, client *duplosdk.Client) {
+func (p *Provider) Generate(config *Config, client *duplosdk.Client) error {
 	log.Println("[TRACE] <====== Provider TF generation started. =====>")
 	log.Printf("Config - %s", fmt.Sprintf("%#v", config))
 	// create new empty hcl file object
@@ -29,7 +27,7 @@ func (p *Provider) Generate(config *Config, client *duplosdk.Client) {
 	tenantProjectFile, err := os.Create(tenantProject)
 	if err != nil {
 		fmt.Println(err)
-		return
+		return err
 	}
 
 	// initialize the body of the new file object
@@ -61,19 +59,16 @@ func (p *Provider) Generate(config *Config, client *duplosdk.Client) {
 	awsProvider := rootBody.AppendNewBlock("provider",
 		[]string{"aws"})
 	awsProviderBody := awsProvider.Body()
-	awsProviderBody.SetAttributeTraversal("region", hcl.Traversal{
-		hcl.TraverseRoot{
-			Name: "var",
-		},
-		hcl.TraverseAttr{
-			Name: "region",
-		},
-	})
+	err = SetAttributeReference(awsProviderBody, "region", "var.region")
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
 	fmt.Printf("%s", hclFile.Bytes())
 	_, err = tenantProjectFile.Write(hclFile.Bytes())
 	if err != nil {
 		fmt.Println(err)
-		return
+		return err
 	}
 	reqProvsBlockBody.SetAttributeValue("random",
 		cty.ObjectVal(map[string]cty.Value{
@@ -87,4 +82,5 @@ func (p *Provider) Generate(config *Config, client *duplosdk.Client) {
 	randomProviderBody.AppendNewline()
 
 	log.Println("[TRACE] <====== Provider TF generation done. =====>")
+	return nil
 }
@@ -0,0 +1,445 @@
+package common
+
+import (
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// ResourceReference maps a literal id or arn of a generated resource to the terraform address of that id or arn,
+// like sg-0123 to aws_security_group.duploservices_test.id.
+// Names are not unique across resource types, Attributes limits the rewrite of a name to the listed attributes.
+type ResourceReference struct {
+	Value      string
+	Address    string
+	Attributes []string
+}
+
+// ReferenceResolver rewrites the literal ids and arns of the generated files into references,
+// so that a cloned tenant points at its own resources instead of the resources of the exported tenant.
+// Interpolations are the ones the generators apply to values, like the account ID of an arn with ${local.account_id}.
+type ReferenceResolver struct {
+	TargetLocation string
+	References     []ResourceReference
+	Interpolations []Interpolation
+
+	// forms holds the template sources a reference value can be written as, by reference index.
+	forms [][]string
+	// dependencies maps the address of a resource to the addresses of the resources it refers to.
+	dependencies map[string]map[string]bool
+}
+
+// Blocks which can not refer to resources.
+var referenceSkippedBlocks = []string{"terraform", "provider", "variable"}
+
+// Attributes holding ids or arns besides the ones named *_id, *_ids, *_arn and *_arns, policy documents hold arns.
+// Other attributes, like names, descriptions and tags, are only rewritten for references listing them.
+var referenceAttributes = []string{"id", "arn", "security_groups", "kms_key", "policy", "assume_role_policy", "access_policies", "redrive_policy", "container_definitions"}
+
+var referenceAttributeSuffixes = []string{"_id", "_ids", "_arn", "_arns", "_security_group", "_security_groups"}
+
+func (r *ReferenceResolver) Resolve() error {
+	log.Println("[TRACE] <====== Reference resolution started. =====>")
+	files, err := filepath.Glob(filepath.Join(r.TargetLocation, "*.tf"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+	hclFiles := make([]*hclwrite.File, len(files))
+	declared := map[string]bool{}
+	for i, path := range files {
+		src, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		hclFile, diags := hclwrite.ParseConfig(src, path, hcl.InitialPos)
+		if diags.HasErrors() {
+			return diags
+		}
+		hclFiles[i] = hclFile
+		for _, block := range hclFile.Body().Blocks() {
+			if address := getBlockAddress(block); len(address) > 0 {
+				declared[address] = true
+			}
+		}
+	}
+	// A generator failing on a resource may have registered it without writing it.
+	references := []ResourceReference{}
+	for _, ref := range r.References {
+		if !declared[getResourceAddress(ref.Address)] {
+			log.Printf("[TRACE] Skipping reference %s, the resource is not generated.", ref.Address)
+			continue
+		}
+		references = append(references, ref)
+	}
+	r.References = references
+	r.forms = make([][]string, len(references))
+	for i, ref := range references {
+		r.forms[i] = getTemplateForms(ref.Value, r.Interpolations)
+	}
+	// The references written by the generators are edges of the dependency graph before any rewrite.
+	r.dependencies = map[string]map[string]bool{}
+	for _, hclFile := range hclFiles {
+		r.addBodyDependencies(hclFile.Body(), "", declared)
+	}
+	for i, path := range files {
+		hclFile := hclFiles[i]
+		count, err := r.resolveBody(hclFile.Body(), "")
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			continue
+		}
+		err = ioutil.WriteFile(path, hclFile.Bytes(), 0644)
+		if err != nil {
+			return err
+		}
+		log.Printf("[TRACE] %d literal id(s) are replaced with references in %s.", count, path)
+	}
+	log.Println("[TRACE] <====== Reference resolution done. =====>")
+	return nil
+}
+
+// addBodyDependencies adds the resources referred to by a body and its nested blocks to the dependencies of self.
+func (r *ReferenceResolver) addBodyDependencies(body *hclwrite.Body, self string, declared map[string]bool) {
+	for _, attr := range body.Attributes() {
+		for _, traversal := range attr.Expr().Variables() {
+			address := getResourceAddress(strings.Replace(string(traversal.BuildTokens(nil).Bytes()), " ", "", -1))
+			if declared[address] {
+				r.addDependency(self, address)
+			}
+		}
+	}
+	for _, block := range body.Blocks() {
+		blockSelf := self
+		if address := getBlockAddress(block); len(address) > 0 {
+			blockSelf = address
+		}
+		r.addBodyDependencies(block.Body(), blockSelf, declared)
+	}
+}
+
+// resolveBody rewrites the attributes of a body and its nested blocks, self is the address of the enclosing resource.
+func (r *ReferenceResolver) resolveBody(body *hclwrite.Body, self string) (int, error) {
+	count := 0
+	names := []string{}
+	for name := range body.Attributes() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		tokens := body.GetAttribute(name).Expr().BuildTokens(nil)
+		resolved, n, err := r.resolveTokens(tokens, name, self)
+		if err != nil {
+			return 0, err
+		}
+		if n > 0 {
+			body.SetAttributeRaw(name, resolved)
+			count += n
+		}
+	}
+	for _, block := range body.Blocks() {
+		if Contains(referenceSkippedBlocks, block.Type()) {
+			continue
+		}
+		blockSelf := self
+		if address := getBlockAddress(block); len(address) > 0 {
+			blockSelf = address
+		}
+		n, err := r.resolveBody(block.Body(), blockSelf)
+		if err != nil {
+			return 0, err
+		}
+		count += n
+	}
+	return count, nil
+}
+
+// resolveTokens rewrites the quoted templates of an expression, object keys are kept.
+// Templates holding anything but literals and references, like function calls or directives, are kept too.
+func (r *ReferenceResolver) resolveTokens(tokens hclwrite.Tokens, attrName string, self string) (hclwrite.Tokens, int, error) {
+	resolved := hclwrite.Tokens{}
+	count := 0
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		if token.Type != hclsyntax.TokenOQuote {
+			resolved = append(resolved, token)
+			continue
+		}
+		end := getTemplateEnd(tokens, i)
+		if end < 0 {
+			resolved = append(resolved, tokens[i:]...)
+			break
+		}
+		n := 0
+		var rewritten hclwrite.Tokens
+		if !isObjectKey(tokens, end+1) && isSimpleTemplate(tokens[i+1:end]) {
+			var err error
+			rewritten, n, err = r.resolveTemplate(string(tokens[i+1:end].Bytes()), attrName, self)
+			if err != nil {
+				return nil, 0, err
+			}
+		}
+		if n > 0 {
+			rewritten[0].SpacesBefore = token.SpacesBefore
+			resolved = append(resolved, rewritten...)
+			count += n
+		} else {
+			resolved = append(resolved, tokens[i:end+1]...)
+		}
+		i = end
+	}
+	return resolved, count, nil
+}
+
+// resolveTemplate replaces a template holding a registered value with a reference,
+// and the arns embedded in a longer template, like arn:aws:s3:::bucket/*, with interpolations.
+func (r *ReferenceResolver) resolveTemplate(source string, attrName string, self string) (hclwrite.Tokens, int, error) {
+	if ref, ok := r.lookup(source, attrName, self); ok {
+		tokens, err := TokensForReference(ref.Address)
+		if err != nil {
+			return nil, 0, err
+		}
+		r.addDependency(self, getResourceAddress(ref.Address))
+		return tokens, 1, nil
+	}
+	if !isReferenceAttribute(attrName) {
+		return nil, 0, nil
+	}
+	interpolated, count := r.interpolateArns(source, self)
+	if count == 0 {
+		return nil, 0, nil
+	}
+	tokens, err := parseExpression(`"` + interpolated + `"`)
+	if err != nil {
+		return nil, 0, err
+	}
+	return tokens, count, nil
+}
+
+// lookup returns the reference of a whole value, a reference limited to the attribute wins over a general one.
+func (r *ReferenceResolver) lookup(source string, attrName string, self string) (ResourceReference, bool) {
+	var found *ResourceReference
+	for i, ref := range r.References {
+		if !Contains(r.forms[i], source) {
+			continue
+		}
+		if len(ref.Attributes) > 0 {
+			if Contains(ref.Attributes, attrName) && r.canRefer(ref, self) {
+				return ref, true
+			}
+			continue
+		}
+		if found == nil && isReferenceAttribute(attrName) && r.canRefer(ref, self) {
+			found = &r.References[i]
+		}
+	}
+	if found == nil {
+		return ResourceReference{}, false
+	}
+	return *found, true
+}
+
+// interpolateArns replaces the registered arns in the literal parts of a template source.
+func (r *ReferenceResolver) interpolateArns(source string, self string) (string, int) {
+	type arnForm struct {
+		form string
+		ref  ResourceReference
+	}
+	arnForms := []arnForm{}
+	for i, ref := range r.References {
+		if len(ref.Attributes) > 0 || !strings.HasPrefix(ref.Value, "arn:") {
+			continue
+		}
+		for _, form := range r.forms[i] {
+			arnForms = append(arnForms, arnForm{form: form, ref: ref})
+		}
+	}
+	// Longest first, so that an arn is not cut by a shorter arn it starts with.
+	sort.SliceStable(arnForms, func(i, j int) bool {
+		return len(arnForms[i].form) > len(arnForms[j].form)
+	})
+	interpolated := &strings.Builder{}
+	count := 0
+	start := 0
+	for i := 0; i < len(source); i++ {
+		rest := source[i:]
+		if strings.HasPrefix(rest, "$${") || strings.HasPrefix(rest, "%%{") {
+			i += 2
+			continue
+		}
+		if strings.HasPrefix(rest, "${") {
+			if end := strings.IndexByte(rest, '}'); end > 0 {
+				i += end
+			}
+			continue
+		}
+		if !strings.HasPrefix(rest, "arn:") || (i > 0 && isArnChar(source[i-1])) {
+			continue
+		}
+		for _, arn := range arnForms {
+			end := i + len(arn.form)
+			if !strings.HasPrefix(rest, arn.form) || (end < len(source) && isArnChar(source[end])) || !r.canRefer(arn.ref, self) {
+				continue
+			}
+			interpolated.WriteString(source[start:i])
+			interpolated.WriteString("${" + arn.ref.Address + "}")
+			r.addDependency(self, getResourceAddress(arn.ref.Address))
+			count++
+			start = end
+			i = end - 1
+			break
+		}
+	}
+	if count == 0 {
+		return "", 0
+	}
+	interpolated.WriteString(source[start:])
+	return interpolated.String(), count
+}
+
+// canRefer reports whether self can refer to a reference, a reference to self or to a resource depending on self
+// would be a cycle and the value is kept literal.
+func (r *ReferenceResolver) canRefer(ref ResourceReference, self string) bool {
+	if len(self) == 0 {
+		return true
+	}
+	address := getResourceAddress(ref.Address)
+	if address == self {
+		return false
+	}
+	if r.dependsOn(address, self, map[string]bool{}) {
+		log.Printf("[WARN] Keeping %s literal in %s, a reference to %s would be a dependency cycle.", ref.Value, self, ref.Address)
+		return false
+	}
+	return true
+}
+
+func (r *ReferenceResolver) addDependency(self string, address string) {
+	if len(self) == 0 || address == self {
+		return
+	}
+	if r.dependencies[self] == nil {
+		r.dependencies[self] = map[string]bool{}
+	}
+	r.dependencies[self][address] = true
+}
+
+// dependsOn reports whether a resource refers to another one, directly or through other resources.
+func (r *ReferenceResolver) dependsOn(address string, other string, visited map[string]bool) bool {
+	if address == other {
+		return true
+	}
+	if visited[address] {
+		return false
+	}
+	visited[address] = true
+	for dependency := range r.dependencies[address] {
+		if r.dependsOn(dependency, other, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// getTemplateForms returns the template sources of a value, with every combination of the interpolations applied.
+func getTemplateForms(value string, interpolations []Interpolation) []string {
+	forms := []string{}
+	for mask := 0; mask < 1<<len(interpolations); mask++ {
+		applied := []Interpolation{}
+		for i, interpolation := range interpolations {
+			if mask&(1<<i) != 0 {
+				applied = append(applied, interpolation)
+			}
+		}
+		quoted := quoteTemplate(string(Interpolate(value, applied...)))
+		if form := quoted[1 : len(quoted)-1]; !Contains(forms, form) {
+			forms = append(forms, form)
+		}
+	}
+	return forms
+}
+
+// getTemplateEnd returns the index of the quote closing the template opened at start, or -1.
+func getTemplateEnd(tokens hclwrite.Tokens, start int) int {
+	depth := 0
+	for i := start; i < len(tokens); i++ {
+		switch tokens[i].Type {
+		case hclsyntax.TokenOQuote:
+			depth++
+		case hclsyntax.TokenCQuote:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// isSimpleTemplate reports a template of literals and interpolated references, like arn:aws:iam::${local.account_id}:root.
+func isSimpleTemplate(tokens hclwrite.Tokens) bool {
+	for _, token := range tokens {
+		switch token.Type {
+		case hclsyntax.TokenQuotedLit, hclsyntax.TokenTemplateInterp, hclsyntax.TokenTemplateSeqEnd, hclsyntax.TokenIdent, hclsyntax.TokenDot:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isReferenceAttribute reports an attribute holding ids or arns.
+func isReferenceAttribute(name string) bool {
+	if Contains(referenceAttributes, name) {
+		return true
+	}
+	for _, suffix := range referenceAttributeSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// getBlockAddress returns the address of a resource or data block, like aws_s3_bucket.logs or data.aws_iam_role.app.
+func getBlockAddress(block *hclwrite.Block) string {
+	labels := block.Labels()
+	if len(labels) != 2 {
+		return ""
+	}
+	if block.Type() == "resource" {
+		return labels[0] + "." + labels[1]
+	}
+	if block.Type() == "data" {
+		return "data." + labels[0] + "." + labels[1]
+	}
+	return ""
+}
+
+// getResourceAddress strips the attribute of a reference, like aws_s3_bucket.logs.arn to aws_s3_bucket.logs.
+func getResourceAddress(address string) string {
+	parts := strings.SplitN(address, ".", 4)
+	if parts[0] == "data" && len(parts) >= 3 {
+		return strings.Join(parts[:3], ".")
+	}
+	if len(parts) >= 2 {
+		return strings.Join(parts[:2], ".")
+	}
+	return address
+}
+
+func isObjectKey(tokens hclwrite.Tokens, next int) bool {
+	return next < len(tokens) && (tokens[next].Type == hclsyntax.TokenEqual || tokens[next].Type == hclsyntax.TokenColon)
+}
+
+func isArnChar(c byte) bool {
+	return c == '-' || c == '_' || c == '.' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
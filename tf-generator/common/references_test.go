@@ -0,0 +1,299 @@
+package common
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+var testInterpolations = []Interpolation{
+	{Value: "123456789012", Expr: "local.account_id"},
+	{Value: "test", Expr: "local.tenant_name"},
+	{Value: "duploservices-test", Expr: "local.tenant_iam_role_name"},
+}
+
+var testReferences = []ResourceReference{
+	{Value: "sg-aaa", Address: "aws_security_group.a.id"},
+	{Value: "sg-bbb", Address: "aws_security_group.b.id"},
+	{Value: "arn:aws:iam::123456789012:role/duploservices-test", Address: "aws_iam_role.tenant.arn"},
+	{Value: "arn:aws:s3:::logs", Address: "aws_s3_bucket.logs.arn"},
+	{Value: "arn:aws:kms:us-west-2:123456789012:key/abc", Address: "aws_kms_key.tenant.arn"},
+	{Value: "duploservices-test", Address: "aws_iam_role.tenant.name", Attributes: []string{"iam_instance_profile"}},
+	{Value: "arn:aws:iam::123456789012:role/duploservices-test", Address: "aws_iam_role.tenant.arn", Attributes: []string{"role"}},
+	{Value: "sg-missing", Address: "aws_security_group.missing.id"},
+}
+
+// Resources the test files refer to, declared in a file of their own.
+const testDeclarations = `
+resource "aws_iam_role" "tenant" {
+}
+
+resource "aws_s3_bucket" "logs" {
+}
+`
+
+func TestResolve(t *testing.T) {
+	cases := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "rewrite",
+			src: `
+resource "aws_security_group" "a" {
+}
+
+resource "aws_instance" "app" {
+  vpc_security_group_ids = ["sg-aaa", "sg-other"]
+  iam_instance_profile   = "duploservices-test"
+  subnet_id              = "sg-aaa"
+}
+`,
+			want: `
+resource "aws_security_group" "a" {
+}
+
+resource "aws_instance" "app" {
+  vpc_security_group_ids = [aws_security_group.a.id, "sg-other"]
+  iam_instance_profile   = aws_iam_role.tenant.name
+  subnet_id              = aws_security_group.a.id
+}
+`,
+		},
+		{
+			name: "arn in an attribute listed by a reference",
+			src: `
+resource "aws_lambda_function" "app" {
+  role = "arn:aws:iam::${local.account_id}:role/duploservices-test"
+}
+
+resource "aws_iam_role_policy" "app" {
+  role = "duploservices-test"
+}
+`,
+			want: `
+resource "aws_lambda_function" "app" {
+  role = aws_iam_role.tenant.arn
+}
+
+resource "aws_iam_role_policy" "app" {
+  role = "duploservices-test"
+}
+`,
+		},
+		{
+			name: "names, descriptions, tags and object keys are kept",
+			src: `
+resource "aws_security_group" "a" {
+}
+
+resource "aws_instance" "app" {
+  description = "sg-aaa"
+  role        = "duploservices-test"
+  tags = {
+    Name = "sg-aaa"
+  }
+  tag {
+    key   = "SecurityGroup"
+    value = "sg-aaa"
+  }
+  security_group_id = jsonencode({
+    "sg-aaa" = "x"
+  })
+}
+`,
+			want: `
+resource "aws_security_group" "a" {
+}
+
+resource "aws_instance" "app" {
+  description = "sg-aaa"
+  role        = "duploservices-test"
+  tags = {
+    Name = "sg-aaa"
+  }
+  tag {
+    key   = "SecurityGroup"
+    value = "sg-aaa"
+  }
+  security_group_id = jsonencode({
+    "sg-aaa" = "x"
+  })
+}
+`,
+		},
+		{
+			name: "templates",
+			src: `
+resource "aws_kms_key" "tenant" {
+}
+
+resource "aws_s3_bucket_policy" "logs" {
+  policy = jsonencode({
+    Principal = "arn:aws:iam::${local.account_id}:role/${local.tenant_iam_role_name}"
+    Resource  = ["arn:aws:s3:::logs/$${aws:username}/*", "arn:aws:s3:::logs", "arn:aws:s3:::logs-other"]
+    Key       = "arn:aws:kms:us-west-2:${local.account_id}:key/abc"
+    Condition = "${local.account_id}:arn:aws:s3:::logs/*"
+  })
+}
+`,
+			want: `
+resource "aws_kms_key" "tenant" {
+}
+
+resource "aws_s3_bucket_policy" "logs" {
+  policy = jsonencode({
+    Principal = aws_iam_role.tenant.arn
+    Resource  = ["${aws_s3_bucket.logs.arn}/$${aws:username}/*", aws_s3_bucket.logs.arn, "arn:aws:s3:::logs-other"]
+    Key       = aws_kms_key.tenant.arn
+    Condition = "${local.account_id}:${aws_s3_bucket.logs.arn}/*"
+  })
+}
+`,
+		},
+		{
+			name: "self reference",
+			src: `
+resource "aws_kms_key" "tenant" {
+  policy = jsonencode({
+    Resource = "arn:aws:kms:us-west-2:123456789012:key/abc"
+  })
+}
+
+resource "aws_security_group" "a" {
+  source_security_group_id = "sg-aaa"
+}
+`,
+			want: `
+resource "aws_kms_key" "tenant" {
+  policy = jsonencode({
+    Resource = "arn:aws:kms:us-west-2:123456789012:key/abc"
+  })
+}
+
+resource "aws_security_group" "a" {
+  source_security_group_id = "sg-aaa"
+}
+`,
+		},
+		{
+			name: "cycle",
+			src: `
+resource "aws_security_group" "a" {
+  source_security_group_id = "sg-bbb"
+}
+
+resource "aws_security_group" "b" {
+  source_security_group_id = "sg-aaa"
+}
+`,
+			want: `
+resource "aws_security_group" "a" {
+  source_security_group_id = aws_security_group.b.id
+}
+
+resource "aws_security_group" "b" {
+  source_security_group_id = "sg-aaa"
+}
+`,
+		},
+		{
+			name: "cycle through a generated reference",
+			src: `
+resource "aws_security_group" "a" {
+  source_security_group_id = "sg-bbb"
+}
+
+resource "aws_security_group_rule" "b" {
+  security_group_id = aws_security_group.b.id
+  description       = "${aws_security_group.a.name}"
+}
+
+resource "aws_security_group" "b" {
+  vpc_id = aws_security_group_rule.b.id
+}
+`,
+			want: `
+resource "aws_security_group" "a" {
+  source_security_group_id = "sg-bbb"
+}
+
+resource "aws_security_group_rule" "b" {
+  security_group_id = aws_security_group.b.id
+  description       = "${aws_security_group.a.name}"
+}
+
+resource "aws_security_group" "b" {
+  vpc_id = aws_security_group_rule.b.id
+}
+`,
+		},
+		{
+			name: "no match",
+			src: `
+resource "aws_security_group" "a" {
+}
+
+resource "aws_instance" "app" {
+  vpc_security_group_ids = ["sg-missing", "sg-aaaa", "${var.sg_id}", "sg-${var.sg_suffix}"]
+  kms_key_id             = "arn:aws:kms:us-west-2:123456789012:key/abcd"
+  user_data_base64       = base64encode("sg-aaa")
+}
+`,
+			want: `
+resource "aws_security_group" "a" {
+}
+
+resource "aws_instance" "app" {
+  vpc_security_group_ids = ["sg-missing", "sg-aaaa", "${var.sg_id}", "sg-${var.sg_suffix}"]
+  kms_key_id             = "arn:aws:kms:us-west-2:123456789012:key/abcd"
+  user_data_base64       = base64encode("sg-aaa")
+}
+`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			targetLocation := t.TempDir()
+			path := filepath.Join(targetLocation, "main.tf")
+			if err := ioutil.WriteFile(path, []byte(tc.src), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := ioutil.WriteFile(filepath.Join(targetLocation, "declarations.tf"), []byte(testDeclarations), 0644); err != nil {
+				t.Fatal(err)
+			}
+			resolver := ReferenceResolver{
+				TargetLocation: targetLocation,
+				References:     testReferences,
+				Interpolations: testInterpolations,
+			}
+			if err := resolver.Resolve(); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(hclwrite.Format(got)) != string(hclwrite.Format([]byte(tc.want))) {
+				t.Errorf("got\n%s\nwant\n%s", hclwrite.Format(got), tc.want)
+			}
+		})
+	}
+}
+
+func TestGetTemplateForms(t *testing.T) {
+	forms := getTemplateForms("arn:aws:iam::123456789012:role/duploservices-test", testInterpolations)
+	for _, want := range []string{
+		"arn:aws:iam::123456789012:role/duploservices-test",
+		"arn:aws:iam::${local.account_id}:role/duploservices-test",
+		"arn:aws:iam::${local.account_id}:role/duploservices-${local.tenant_name}",
+		"arn:aws:iam::${local.account_id}:role/${local.tenant_iam_role_name}",
+	} {
+		if !Contains(forms, want) {
+			t.Errorf("expected %s in %v", want, forms)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package common
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"tenant-native-terraform-generator/duplosdk"
 
@@ -16,7 +17,7 @@ type TfInitializer struct {
 	Config     *Config
 }
 
-func (tfi *TfInitializer) InitWithWorkspace() *tfexec.Terraform {
+func (tfi *TfInitializer) InitWithWorkspace() (*tfexec.Terraform, error) {
 	log.Println("[TRACE] <================================== TF init in progress. ==================================>")
 	tfVersion := GetEnv("tf_version", "0.14.11")
 	installer := &releases.ExactVersion{
@@ -26,11 +27,11 @@ func (tfi *TfInitializer) InitWithWorkspace() *tfexec.Terraform {
 
 	execPath, err := installer.Install(context.Background())
 	if err != nil {
-		log.Fatalf("error installing Terraform: %s", err)
+		return nil, fmt.Errorf("error installing Terraform: %s", err)
 	}
 	tf, err := tfexec.NewTerraform(tfi.WorkingDir, execPath)
 	if err != nil {
-		log.Fatalf("error running NewTerraform: %s", err)
+		return nil, fmt.Errorf("error running NewTerraform: %s", err)
 	}
 	if tfi.Config.S3Backend {
 		err = tf.Init(context.Background(), tfexec.Upgrade(true), tfexec.BackendConfig("bucket="+tfi.Config.S3Bucket))
@@ -39,12 +40,12 @@ func (tfi *TfInitializer) InitWithWorkspace() *tfexec.Terraform {
 	}
 
 	if err != nil {
-		log.Fatalf("error running Init: %s", err)
+		return nil, fmt.Errorf("error running Init: %s", err)
 	}
 
 	workspaceList, activeWorkspace, err := tf.WorkspaceList(context.Background())
 	if err != nil {
-		log.Fatalf("error running tf workspace list: %s", err)
+		return nil, fmt.Errorf("error running tf workspace list: %s", err)
 	}
 	if len(workspaceList) > 0 {
 		log.Printf("[TRACE] Workspace List (%s).", workspaceList)
@@ -54,22 +55,22 @@ func (tfi *TfInitializer) InitWithWorkspace() *tfexec.Terraform {
 	if duplosdk.Contains(workspaceList, tfi.Config.TenantName) {
 		err = tf.WorkspaceSelect(context.Background(), tfi.Config.TenantName)
 		if err != nil {
-			log.Fatalf("error running tf workspace select: %s", err)
+			return nil, fmt.Errorf("error running tf workspace select: %s", err)
 		}
 		log.Printf("[TRACE] (%s) workspace is selected.", tfi.Config.TenantName)
 	} else {
 		err := tf.WorkspaceNew(context.Background(), tfi.Config.TenantName)
 		if err != nil {
-			log.Fatalf("error running tf workspace new: %s", err)
+			return nil, fmt.Errorf("error running tf workspace new: %s", err)
 		}
 		log.Printf("[TRACE] (%s) workspace is created.", tfi.Config.TenantName)
 	}
 	log.Printf("[TRACE] Terraform initialized with new workspace - %s", tfi.Config.TenantName)
 	log.Println("[TRACE] <====================================================================>")
-	return tf
+	return tf, nil
 }
 
-func (tfi *TfInitializer) Init(config *Config, workingDir string) *tfexec.Terraform {
+func (tfi *TfInitializer) Init(config *Config, workingDir string) (*tfexec.Terraform, error) {
 	tfVersion := GetEnv("tf_version", "0.14.11")
 	installer := &releases.ExactVersion{
 		Product: product.Terraform,
@@ -78,11 +79,11 @@ func (tfi *TfInitializer) Init(config *Config, workingDir string) *tfexec.Terraf
 
 	execPath, err := installer.Install(context.Background())
 	if err != nil {
-		log.Fatalf("error installing Terraform: %s", err)
+		return nil, fmt.Errorf("error installing Terraform: %s", err)
 	}
 	tf, err := tfexec.NewTerraform(workingDir, execPath)
 	if err != nil {
-		log.Fatalf("error running NewTerraform: %s", err)
+		return nil, fmt.Errorf("error running NewTerraform: %s", err)
 	}
 	if config.S3Backend {
 		if len(config.DynamodbTable) > 0 {
@@ -96,15 +97,15 @@ func (tfi *TfInitializer) Init(config *Config, workingDir string) *tfexec.Terraf
 	}
 
 	if err != nil {
-		log.Fatalf("error running Init: %s", err)
+		return nil, fmt.Errorf("error running Init: %s", err)
 	}
-	return tf
+	return tf, nil
 }
 
-func (tfi *TfInitializer) NewWorkspace(config *Config, tf *tfexec.Terraform) {
+func (tfi *TfInitializer) NewWorkspace(config *Config, tf *tfexec.Terraform) error {
 	workspaceList, activeWorkspace, err := tf.WorkspaceList(context.Background())
 	if err != nil {
-		log.Fatalf("error running tf workspace list: %s", err)
+		return fmt.Errorf("error running tf workspace list: %s", err)
 	}
 	if len(workspaceList) > 0 {
 		log.Printf("[TRACE] Workspace List (%s).", workspaceList)
@@ -113,17 +114,17 @@ func (tfi *TfInitializer) NewWorkspace(config *Config, tf *tfexec.Terraform) {
 	if !duplosdk.Contains(workspaceList, tfi.Config.TenantName) {
 		err := tf.WorkspaceNew(context.Background(), config.TenantName)
 		if err != nil {
-			log.Fatalf("error running tf workspace new: %s", err)
+			return fmt.Errorf("error running tf workspace new: %s", err)
 		}
 		log.Printf("[TRACE] (%s) workspace is created.", config.TenantName)
 	}
-
+	return nil
 }
 
-func (tfi *TfInitializer) DeleteWorkspace(config *Config, tf *tfexec.Terraform) {
+func (tfi *TfInitializer) DeleteWorkspace(config *Config, tf *tfexec.Terraform) error {
 	workspaceList, activeWorkspace, err := tf.WorkspaceList(context.Background())
 	if err != nil {
-		log.Fatalf("error running tf workspace list: %s", err)
+		return fmt.Errorf("error running tf workspace list: %s", err)
 	}
 	if len(workspaceList) > 0 {
 		log.Printf("[TRACE] Workspace List (%s).", workspaceList)
@@ -132,13 +133,13 @@ func (tfi *TfInitializer) DeleteWorkspace(config *Config, tf *tfexec.Terraform)
 	if duplosdk.Contains(workspaceList, tfi.Config.TenantName) {
 		err := tf.WorkspaceSelect(context.Background(), "default")
 		if err != nil {
-			log.Fatalf("error running tf workspace select(default): %s", err)
+			return fmt.Errorf("error running tf workspace select(default): %s", err)
 		}
 		err = tf.WorkspaceDelete(context.Background(), config.TenantName)
 		if err != nil {
-			log.Fatalf("error running tf workspace delete: %s", err)
+			return fmt.Errorf("error running tf workspace delete: %s", err)
 		}
 		log.Printf("[TRACE] Workspace deleted (%s).", config.TenantName)
 	}
-
+	return nil
 }
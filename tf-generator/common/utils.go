@@ -2,6 +2,7 @@ package common
 
 import (
 	"context"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
@@ -10,58 +11,28 @@ import (
 	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/hc-install/product"
 	"github.com/hashicorp/hc-install/releases"
-	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/terraform-exec/tfexec"
-
-	"github.com/hashicorp/hcl/v2/hclwrite"
 )
 
-type ObjectAttrTokens struct {
-	Name  hclwrite.Tokens
-	Value hclwrite.Tokens
-}
-
-func TokensForObject(attrs []ObjectAttrTokens) hclwrite.Tokens {
-	var toks hclwrite.Tokens
-	toks = append(toks, &hclwrite.Token{
-		Type:  hclsyntax.TokenOBrace,
-		Bytes: []byte{'{'},
-	})
-	if len(attrs) > 0 {
-		toks = append(toks, &hclwrite.Token{
-			Type:  hclsyntax.TokenNewline,
-			Bytes: []byte{'\n'},
-		})
-	}
-	for _, attr := range attrs {
-		toks = append(toks, attr.Name...)
-		toks = append(toks, &hclwrite.Token{
-			Type:  hclsyntax.TokenEqual,
-			Bytes: []byte{'='},
-		})
-		toks = append(toks, attr.Value...)
-		toks = append(toks, &hclwrite.Token{
-			Type:  hclsyntax.TokenNewline,
-			Bytes: []byte{'\n'},
-		})
-	}
-	toks = append(toks, &hclwrite.Token{
-		Type:  hclsyntax.TokenCBrace,
-		Bytes: []byte{'}'},
-	})
-
-	//format(toks) // fiddle with the SpacesBefore field to get canonical spacing
-	return toks
-}
-
 const (
 	Host = iota
 	S3
 )
 
+// GetResourceName returns a terraform identifier for a name, characters which are not allowed in identifiers become _.
 func GetResourceName(name string) string {
 	replacer := strings.NewReplacer("/", "_", "-", "_", ".", "_", " ", "_")
-	return strings.ToLower(replacer.Replace(name))
+	resourceName := []rune(strings.ToLower(replacer.Replace(name)))
+	for i, r := range resourceName {
+		if r != '_' && !(r >= 'a' && r <= 'z') && !(r >= '0' && r <= '9') {
+			resourceName[i] = '_'
+		}
+	}
+	// An identifier can not start with a digit.
+	if len(resourceName) > 0 && resourceName[0] >= '0' && resourceName[0] <= '9' {
+		return "_" + string(resourceName)
+	}
+	return string(resourceName)
 }
 
 func GetEnv(key string, defaultVal string) string {
@@ -82,23 +53,20 @@ func Contains(s []string, str string) bool {
 	return false
 }
 
-func RepalceStringInFile(file string, stringsToRepalce map[string]string) {
+func RepalceStringInFile(file string, stringsToRepalce map[string]string) error {
 	input, err := ioutil.ReadFile(file)
 	if err != nil {
-		log.Fatalln(err)
+		return err
 	}
 	newStr := string(input)
 	for key, element := range stringsToRepalce {
 		newStr = strings.Replace(newStr, key, element, -1)
 	}
 
-	err = ioutil.WriteFile(file, []byte(newStr), 0644)
-	if err != nil {
-		log.Fatalln(err)
-	}
+	return ioutil.WriteFile(file, []byte(newStr), 0644)
 }
 
-func ValidateAndFormatTfCode(tfDir, tfVersion string) {
+func ValidateAndFormatTfCode(tfDir, tfVersion string) error {
 	log.Printf("[TRACE] Validation and formatting of terraform code generated at %s is started.", tfDir)
 	installer := &releases.ExactVersion{
 		Product: product.Terraform,
@@ -113,25 +81,26 @@ func ValidateAndFormatTfCode(tfDir, tfVersion string) {
 
 	execPath, err := installer.Install(context.Background())
 	if err != nil {
-		log.Fatalf("error installing Terraform: %s", err)
+		return fmt.Errorf("error installing Terraform: %s", err)
 	}
 	tf, err := tfexec.NewTerraform(tfDir, execPath)
 	if err != nil {
-		log.Fatalf("error running NewTerraform: %s", err)
+		return fmt.Errorf("error running NewTerraform: %s", err)
 	}
 	log.Printf("[TRACE] Validation of terraform code generated at %s is started.", tfDir)
 	_, err = tf.Validate(context.Background())
 	if err != nil {
-		log.Fatalf("error running terraform validate: %s", err)
+		return fmt.Errorf("error running terraform validate: %s", err)
 	}
 	log.Printf("[TRACE] Validation of terraform code generated at %s is done.", tfDir)
 	log.Printf("[TRACE] Formatting of terraform code generated at %s is started.", tfDir)
 	err = tf.FormatWrite(context.Background())
 	if err != nil {
-		log.Fatalf("error running terraform format: %s", err)
+		return fmt.Errorf("error running terraform format: %s", err)
 	}
 	log.Printf("[TRACE] Formatting of terraform code generated at %s is done.", tfDir)
 	log.Printf("[TRACE] Validation and formatting of terraform code generated at %s is done.", tfDir)
+	return nil
 }
 
 func IsTagAwsManaged(tagKey string) bool {
@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 )
 
 type IValidator interface {
@@ -56,6 +57,28 @@ func (envVar *EnvVarValidator) Validate() (*Config, error) {
 		tenantProject = "tenant"
 	}
 
+	k8sProject := os.Getenv("k8s_project")
+	if len(k8sProject) == 0 {
+		k8sProject = "k8s"
+	}
+
+	k8sProviderVersion := os.Getenv("k8s_provider_version")
+	if len(k8sProviderVersion) == 0 {
+		k8sProviderVersion = "2.13.1"
+	}
+
+	k8sExportServices := false
+	k8sExportServicesStr := os.Getenv("k8s_export_services")
+	if len(k8sExportServicesStr) > 0 {
+		k8sExportServicesBool, err := strconv.ParseBool(k8sExportServicesStr)
+		if err != nil {
+			err = fmt.Errorf("error while reading k8s_export_services from env vars %s", err)
+			log.Printf("[TRACE] - %s", err)
+			return nil, err
+		}
+		k8sExportServices = k8sExportServicesBool
+	}
+
 	generateTfState := false
 
 	generateTfStateStr := os.Getenv("generate_tf_state")
@@ -104,6 +127,28 @@ func (envVar *EnvVarValidator) Validate() (*Config, error) {
 		}
 	}
 
+	ecrDataSourceRepos := []string{}
+	ecrDataSourceReposStr := os.Getenv("ecr_data_source_repos")
+	if len(ecrDataSourceReposStr) > 0 {
+		for _, repo := range strings.Split(ecrDataSourceReposStr, ",") {
+			if repo = strings.TrimSpace(repo); len(repo) > 0 {
+				ecrDataSourceRepos = append(ecrDataSourceRepos, repo)
+			}
+		}
+	}
+
+	parallelism := 4
+	parallelismStr := os.Getenv("parallelism")
+	if len(parallelismStr) > 0 {
+		parallelismInt, err := strconv.Atoi(parallelismStr)
+		if err != nil || parallelismInt < 1 {
+			err = fmt.Errorf("error while reading parallelism from env vars, it should be a positive number: %s", parallelismStr)
+			log.Printf("[TRACE] - %s", err)
+			return nil, err
+		}
+		parallelism = parallelismInt
+	}
+
 	return &Config{
 		DuploHost:          host,
 		DuploToken:         token,
@@ -111,11 +156,16 @@ func (envVar *EnvVarValidator) Validate() (*Config, error) {
 		CustomerName:       custName,
 		AwsProviderVersion: awsProviderVersion,
 		TenantProject:      tenantProject,
+		K8sProject:         k8sProject,
+		K8sProviderVersion: k8sProviderVersion,
+		K8sExportServices:  k8sExportServices,
 		GenerateTfState:    generateTfState,
 		S3Backend:          s3Backend,
 		S3Bucket:           s3Bucket,
 		DynamodbTable:      dynamodbTable,
 		ValidateTf:         validateTf,
 		TFVersion:          tfVersion,
+		EcrDataSourceRepos: ecrDataSourceRepos,
+		Parallelism:        parallelism,
 	}, nil
 }
@@ -5,8 +5,8 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 
-	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -16,6 +16,16 @@ type VarConfig struct {
 	TypeVal    string
 	DefaultVal string
 	DescVal    string
+	Sensitive  bool
+	// Nullable is written when set, nullable needs terraform 1.1 or later.
+	Nullable    *bool
+	Validations []VarValidation
+}
+
+// VarValidation is a validation block of a variable, Condition is a terraform expression.
+type VarValidation struct {
+	Condition    string
+	ErrorMessage string
 }
 
 type Vars struct {
@@ -23,11 +33,29 @@ type Vars struct {
 	Vars           []VarConfig
 }
 
-func (v *Vars) Generate() {
+// Generate writes vars.tf with the variables sorted by name, a variable declared twice keeps its first declaration.
+func (v *Vars) Generate() error {
 
 	if len(v.Vars) > 0 {
 		log.Println("[TRACE] <====== Variables TF generation started. =====>")
 
+		vars := make([]VarConfig, 0, len(v.Vars))
+		names := map[string]bool{}
+		for _, varConfig := range v.Vars {
+			if len(varConfig.Name) == 0 {
+				continue
+			}
+			if names[varConfig.Name] {
+				log.Printf("[WARN] Variable %s is declared more than once, the first declaration is kept.", varConfig.Name)
+				continue
+			}
+			names[varConfig.Name] = true
+			vars = append(vars, varConfig)
+		}
+		sort.SliceStable(vars, func(i, j int) bool {
+			return vars[i].Name < vars[j].Name
+		})
+
 		// create new empty hcl file object
 		hclFile := hclwrite.NewEmptyFile()
 		// create new file on system
@@ -35,54 +63,82 @@ func (v *Vars) Generate() {
 		tfFile, err := os.Create(path)
 		if err != nil {
 			fmt.Println(err)
-			return
+			return err
 		}
 
 		// initialize the body of the new file object
 		rootBody := hclFile.Body()
-		for _, varConfig := range v.Vars {
-			if len(varConfig.Name) > 0 {
-				varblock := rootBody.AppendNewBlock("variable",
-					[]string{varConfig.Name})
-				varBody := varblock.Body()
-				if len(varConfig.DefaultVal) > 0 {
-					if varConfig.DefaultVal == "null" {
+		// A variable with an invalid type, default or validation condition is left out, the others are still written.
+		var invalidErr error
+		for _, varConfig := range vars {
+			typeTokens, err := TokensForExpression(varConfig.TypeVal)
+			var defaultTokens hclwrite.Tokens
+			if err == nil && len(varConfig.DefaultVal) > 0 && varConfig.DefaultVal != "null" && ("number" == varConfig.TypeVal || "bool" == varConfig.TypeVal) {
+				defaultTokens, err = TokensForExpression(varConfig.DefaultVal)
+			}
+			conditionTokens := make([]hclwrite.Tokens, len(varConfig.Validations))
+			for i, validation := range varConfig.Validations {
+				if err != nil {
+					break
+				}
+				conditionTokens[i], err = TokensForExpression(validation.Condition)
+			}
+			if err != nil {
+				log.Printf("[WARN] Variable %s is left out: %s", varConfig.Name, err)
+				invalidErr = fmt.Errorf("variable %q: %s", varConfig.Name, err)
+				continue
+			}
+			varblock := rootBody.AppendNewBlock("variable",
+				[]string{varConfig.Name})
+			varBody := varblock.Body()
+			if len(varConfig.DefaultVal) > 0 {
+				if varConfig.DefaultVal == "null" {
+					varBody.SetAttributeValue("default",
+						cty.NullVal(cty.String))
+				} else {
+					if "string" == varConfig.TypeVal {
 						varBody.SetAttributeValue("default",
-							cty.NullVal(cty.String))
-					} else {
-						if "string" == varConfig.TypeVal {
-							varBody.SetAttributeValue("default",
-								cty.StringVal(varConfig.DefaultVal))
-						} else if "number" == varConfig.TypeVal || "bool" == varConfig.TypeVal {
-							varBody.SetAttributeTraversal("default", hcl.Traversal{
-								hcl.TraverseRoot{
-									Name: varConfig.DefaultVal,
-								},
-							})
-						}
+							cty.StringVal(varConfig.DefaultVal))
+					} else if defaultTokens != nil {
+						varBody.SetAttributeRaw("default", defaultTokens)
 					}
 				}
+			}
 
-				if len(varConfig.DescVal) > 0 {
-					varBody.SetAttributeValue("description",
-						cty.StringVal(varConfig.DescVal))
-				}
+			if len(varConfig.DescVal) > 0 {
+				varBody.SetAttributeValue("description",
+					cty.StringVal(varConfig.DescVal))
+			}
 
-				varBody.SetAttributeTraversal("type", hcl.Traversal{
-					hcl.TraverseRoot{
-						Name: varConfig.TypeVal,
-					},
-				})
+			varBody.SetAttributeRaw("type", typeTokens)
+
+			if varConfig.Sensitive {
+				varBody.SetAttributeValue("sensitive",
+					cty.BoolVal(true))
+			}
+
+			if varConfig.Nullable != nil {
+				varBody.SetAttributeValue("nullable",
+					cty.BoolVal(*varConfig.Nullable))
 			}
 
+			for i, validation := range varConfig.Validations {
+				validationBody := varBody.AppendNewBlock("validation",
+					nil).Body()
+				validationBody.SetAttributeRaw("condition", conditionTokens[i])
+				validationBody.SetAttributeValue("error_message",
+					cty.StringVal(validation.ErrorMessage))
+			}
 		}
 
 		fmt.Printf("%s", hclFile.Bytes())
 		_, err = tfFile.Write(hclFile.Bytes())
 		if err != nil {
 			fmt.Println(err)
-			return
+			return err
 		}
 		log.Println("[TRACE] <====== Variables TF generation done. =====>")
+		return invalidErr
 	}
+	return nil
 }
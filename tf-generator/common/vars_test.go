@@ -0,0 +1,67 @@
+package common
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+func TestVarsGenerate(t *testing.T) {
+	nullable := false
+	targetLocation := t.TempDir()
+	vars := Vars{
+		TargetLocation: targetLocation,
+		Vars: []VarConfig{
+			{
+				Name:     "instance_count",
+				TypeVal:  "number",
+				Nullable: &nullable,
+				Validations: []VarValidation{
+					{Condition: "var.instance_count > 0", ErrorMessage: "At least one instance is required."},
+					{Condition: "var.instance_count <= 10", ErrorMessage: "At most ${10} instances are allowed."},
+				},
+			},
+			{
+				Name:       "allocated_storage",
+				TypeVal:    "number",
+				DefaultVal: "20",
+			},
+			{
+				Name:    "invalid",
+				TypeVal: "string",
+				Validations: []VarValidation{
+					{Condition: "length(var.invalid", ErrorMessage: "Invalid condition."},
+				},
+			},
+		},
+	}
+	if err := vars.Generate(); err == nil {
+		t.Errorf("expected an error for an invalid validation condition")
+	}
+	got, err := ioutil.ReadFile(filepath.Join(targetLocation, "vars.tf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `variable "allocated_storage" {
+  default = 20
+  type    = number
+}
+variable "instance_count" {
+  type     = number
+  nullable = false
+  validation {
+    condition     = var.instance_count > 0
+    error_message = "At least one instance is required."
+  }
+  validation {
+    condition     = var.instance_count <= 10
+    error_message = "At most $${10} instances are allowed."
+  }
+}
+`
+	if string(hclwrite.Format(got)) != want {
+		t.Errorf("got\n%s\nwant\n%s", hclwrite.Format(got), want)
+	}
+}
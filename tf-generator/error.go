@@ -1,13 +1,115 @@
 package tfgenerator
 
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"tenant-native-terraform-generator/duplosdk"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+)
+
+// TFGeneratorError describes a failure of a generator, or of a single resource handled by it.
+// Fatal failures leave the generated project unusable, Retriable failures are expected to go away on a rerun.
 type TFGeneratorError struct {
+	Generator    string
+	Resource     string
+	Cause        error
+	Retriable    bool
+	Fatal        bool
 	ErrorMessage string
 }
 
 func (e *TFGeneratorError) Error() string {
-	return e.ErrorMessage
+	if e.Cause == nil {
+		return e.ErrorMessage
+	}
+	if len(e.Resource) > 0 {
+		return fmt.Sprintf("%s (%s): %s", e.Generator, e.Resource, e.Cause)
+	}
+	return fmt.Sprintf("%s: %s", e.Generator, e.Cause)
+}
+
+func (e *TFGeneratorError) Unwrap() error {
+	return e.Cause
 }
 
 func ThrowError(error string) error {
 	return &TFGeneratorError{ErrorMessage: error}
 }
+
+func NewTFGeneratorError(generator string, resource string, cause error, fatal bool) *TFGeneratorError {
+	return &TFGeneratorError{
+		Generator: generator,
+		Resource:  resource,
+		Cause:     cause,
+		Retriable: isRetriableError(cause),
+		Fatal:     fatal,
+	}
+}
+
+// isRetriableError reports throttling, timeouts and server side failures of the duplo and aws apis.
+func isRetriableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var clientErr duplosdk.ClientError
+	if errors.As(err, &clientErr) {
+		return clientErr.Status() == 429 || clientErr.Status() >= 500
+	}
+	var maxAttemptsErr *retry.MaxAttemptsError
+	if errors.As(err, &maxAttemptsErr) {
+		return true
+	}
+	if retry.IsErrorRetryables(retry.DefaultRetryables).IsErrorRetryable(err) == aws.TrueTernary {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// TFGeneratorReport collects the failures of a generation run, so that one failure does not abort the whole export.
+type TFGeneratorReport struct {
+	Errors []*TFGeneratorError
+}
+
+func (r *TFGeneratorReport) Add(err *TFGeneratorError) {
+	log.Printf("[TRACE] - error %s", err)
+	r.Errors = append(r.Errors, err)
+}
+
+func (r *TFGeneratorReport) HasFatal() bool {
+	for _, err := range r.Errors {
+		if err.Fatal {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *TFGeneratorReport) PrintSummary() {
+	if len(r.Errors) == 0 {
+		log.Printf("[TRACE] Terraform generation finished without errors.")
+		return
+	}
+	log.Printf("[TRACE] |==========================================================================|")
+	log.Printf("[TRACE] Terraform generation finished with %d error(s).", len(r.Errors))
+	for _, err := range r.Errors {
+		flags := []string{}
+		if err.Fatal {
+			flags = append(flags, "fatal")
+		}
+		if err.Retriable {
+			flags = append(flags, "retriable")
+		}
+		if len(flags) > 0 {
+			log.Printf("[TRACE]   [%s] %s", strings.Join(flags, ", "), err)
+		} else {
+			log.Printf("[TRACE]   %s", err)
+		}
+	}
+	log.Printf("[TRACE] |==========================================================================|")
+}
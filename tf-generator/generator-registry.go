@@ -1,6 +1,7 @@
 package tfgenerator
 
 import (
+	"tenant-native-terraform-generator/tf-generator/k8s"
 	"tenant-native-terraform-generator/tf-generator/tenant"
 )
 
@@ -11,7 +12,67 @@ var TenantGenerators = []Generator{
 	&tenant.TenantKMS{},
 	&tenant.TenantIAM{},
 	&tenant.TenantSG{},
+	&tenant.TenantSGRule{},
 	&tenant.AwsInstance{},
 	&tenant.AwsASG{},
 	&tenant.AwsElasticacheCluster{},
+	&tenant.AwsRdsInstance{},
+	&tenant.AwsS3Bucket{},
+	&tenant.AwsSqsQueue{},
+	&tenant.AwsSnsTopic{},
+	&tenant.AwsDynamoDBTable{},
+	&tenant.AwsLambdaFunction{},
+	&tenant.AwsEcsTaskDefinition{},
+	&tenant.AwsEcsService{},
+	&tenant.AwsLb{},
+	&tenant.AwsEcrRepository{},
+	&tenant.AwsSsmParameter{},
+	&tenant.AwsCloudwatchEventRule{},
+	&tenant.AwsCloudwatchMetricAlarm{},
+	&tenant.AwsElasticsearchDomain{},
+	&tenant.AwsMskCluster{},
+	&tenant.AwsMwaaEnvironment{},
+	&tenant.AwsEmrCluster{},
+	&tenant.AwsCloudfrontDistribution{},
+	&tenant.AwsApiGatewayRestApi{},
+}
+
+var K8sGenerators = []Generator{
+	&k8s.K8sMain{},
+	&k8s.K8sProvider{},
+	&k8s.K8sSecret{},
+	&k8s.K8sConfigMap{},
+	&k8s.K8sDeployment{},
+	&k8s.K8sIngress{},
+}
+
+// Generators the rest of the tenant project builds upon, the project is unusable when one of them fails.
+var CoreGenerators = []Generator{
+	&tenant.AwsVars{},
+	&tenant.TenantMain{},
+	&tenant.TenantKMS{},
+	&tenant.TenantIAM{},
+	&tenant.TenantSG{},
+}
+
+// Generators writing the project wide files which other generators of the same project build upon or append to.
+// They run one by one before the remaining generators of the project, which run concurrently.
+var SetupGenerators = []Generator{
+	&tenant.AwsVars{},
+	&tenant.TenantMain{},
+	&k8s.K8sMain{},
+	&k8s.K8sProvider{},
+}
+
+// Generators building upon the files of other generators of the same project, they run one by one after them.
+// The k8s secrets and deployments append their secure values to the example tfvars written by the k8s provider,
+// the deployments after the secrets so that the example file is the same on every run.
+var GeneratorDependencies = []GeneratorDependency{
+	{Generator: &k8s.K8sSecret{}, DependsOn: []Generator{&k8s.K8sProvider{}}},
+	{Generator: &k8s.K8sDeployment{}, DependsOn: []Generator{&k8s.K8sProvider{}, &k8s.K8sSecret{}}},
+}
+
+type GeneratorDependency struct {
+	Generator Generator
+	DependsOn []Generator
 }
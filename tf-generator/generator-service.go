@@ -2,12 +2,16 @@ package tfgenerator
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"tenant-native-terraform-generator/duplosdk"
 	"tenant-native-terraform-generator/tf-generator/common"
+	"tenant-native-terraform-generator/tf-generator/k8s"
 	"tenant-native-terraform-generator/tf-generator/tenant"
 )
 
@@ -18,6 +22,7 @@ type IGeneratorService interface {
 }
 
 type TfGeneratorService struct {
+	Report TFGeneratorReport
 }
 
 func (tfg *TfGeneratorService) PreProcess(config *common.Config, client *duplosdk.Client) error {
@@ -26,33 +31,40 @@ func (tfg *TfGeneratorService) PreProcess(config *common.Config, client *duplosd
 	tenantProject := filepath.Join(config.TFCodePath, config.TenantProject)
 	err := os.RemoveAll(filepath.Join("target", config.CustomerName, config.TenantName))
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	err = os.RemoveAll(tenantProject)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	err = os.MkdirAll(tenantProject, os.ModePerm)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	config.AdminTenantDir = tenantProject
 
+	k8sProject := filepath.Join(config.TFCodePath, config.K8sProject)
+	err = os.MkdirAll(k8sProject, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	config.K8sDir = k8sProject
+
 	err = duplosdk.Copy(".gitignore", filepath.Join("target", config.CustomerName, config.TenantName, ".gitignore"))
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	err = duplosdk.Copy(".envrc", filepath.Join("target", config.CustomerName, config.TenantName, ".envrc"))
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	envFile, err := os.OpenFile(filepath.Join("target", config.CustomerName, config.TenantName, ".envrc"), os.O_APPEND|os.O_WRONLY, 0644)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	defer envFile.Close()
 	if _, err := envFile.WriteString("\nexport tenant_id=\"" + config.TenantId + "\""); err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("[TRACE] <====== Initialized target directory with customer name and tenant id. =====>")
 	return nil
@@ -61,7 +73,9 @@ func (tfg *TfGeneratorService) PreProcess(config *common.Config, client *duplosd
 func (tfg *TfGeneratorService) StartTFGeneration(config *common.Config, client *duplosdk.Client) error {
 	// var tf *tfexec.Terraform
 	providerGen := &common.Provider{}
-	providerGen.Generate(config, client)
+	if err := providerGen.Generate(config, client); err != nil {
+		tfg.Report.Add(NewTFGeneratorError("provider", config.AdminTenantDir, err, true))
+	}
 
 	// if config.GenerateTfState {
 	// 	tf := tfInit(config, config.AdminTenantDir)
@@ -75,16 +89,35 @@ func (tfg *TfGeneratorService) StartTFGeneration(config *common.Config, client *
 		tenantGeneratorList = append(tenantGeneratorList, &tenant.TenantBackend{})
 	}
 
-	starTFGenerationForProject(config, client, tenantGeneratorList, config.AdminTenantDir)
+	tfg.starTFGenerationForProject(config, client, tenantGeneratorList, config.AdminTenantDir)
 	if config.ValidateTf {
-		common.ValidateAndFormatTfCode(config.AdminTenantDir, config.TFVersion)
+		err := common.ValidateAndFormatTfCode(config.AdminTenantDir, config.TFVersion)
+		if err != nil {
+			tfg.Report.Add(NewTFGeneratorError("terraform validate", config.AdminTenantDir, err, true))
+		}
 	}
 	log.Println("[TRACE] <====== End TF generation for tenant project. =====>")
 
+	log.Println("[TRACE] <====== Start TF generation for k8s project. =====>")
+	// Register New TF generator for K8s Project
+	k8sGeneratorList := K8sGenerators
+	if config.S3Backend {
+		k8sGeneratorList = append(k8sGeneratorList, &k8s.K8sBackend{})
+	}
+
+	tfg.starTFGenerationForProject(config, client, k8sGeneratorList, config.K8sDir)
+	if config.ValidateTf {
+		err := common.ValidateAndFormatTfCode(config.K8sDir, config.TFVersion)
+		if err != nil {
+			tfg.Report.Add(NewTFGeneratorError("terraform validate", config.K8sDir, err, true))
+		}
+	}
+	log.Println("[TRACE] <====== End TF generation for k8s project. =====>")
+
 	return nil
 }
 
-func starTFGenerationForProject(config *common.Config, client *duplosdk.Client, generatorList []Generator, targetLocation string) {
+func (tfg *TfGeneratorService) starTFGenerationForProject(config *common.Config, client *duplosdk.Client, generatorList []Generator, targetLocation string) {
 
 	tfContext := common.TFContext{
 		TargetLocation: targetLocation,
@@ -92,47 +125,76 @@ func starTFGenerationForProject(config *common.Config, client *duplosdk.Client,
 		OutputVars:     []common.OutputVarConfig{},
 	}
 
-	// 1. Generate Duplo TF resources.
+	// 1. Generate Duplo TF resources, setup generators and generators depending on each other first and one by one,
+	// in the order of the generator list, the rest concurrently.
+	err := checkGeneratorOrder(generatorList)
+	if err != nil {
+		tfg.Report.Add(NewTFGeneratorError("generators", targetLocation, err, true))
+		return
+	}
+	serialGenerators := []Generator{}
+	otherGenerators := []Generator{}
 	for _, g := range generatorList {
-		c, err := g.Generate(config, client)
-		if err != nil {
-			log.Fatalf("error running admin tenant tf generation: %s", err)
+		if containsGenerator(SetupGenerators, g) || isGeneratorDependency(g) {
+			serialGenerators = append(serialGenerators, g)
+		} else {
+			otherGenerators = append(otherGenerators, g)
 		}
-		if c != nil {
-			if len(c.InputVars) > 0 {
-				tfContext.InputVars = append(tfContext.InputVars, c.InputVars...)
-			}
-			if len(c.OutputVars) > 0 {
-				tfContext.OutputVars = append(tfContext.OutputVars, c.OutputVars...)
-			}
-			if len(c.ImportConfigs) > 0 {
-				tfContext.ImportConfigs = append(tfContext.ImportConfigs, c.ImportConfigs...)
-			}
+	}
+	generators := append(serialGenerators, otherGenerators...)
+	results := runGenerators(config, client, serialGenerators, 1)
+	results = append(results, runGenerators(config, client, otherGenerators, config.Parallelism)...)
+	tfg.mergeResults(&tfContext, generators, results)
+
+	// 2. Replace the literal ids of the generated resources with references, before the variables are written.
+	if len(tfContext.References) > 0 {
+		referenceResolver := common.ReferenceResolver{
+			TargetLocation: tfContext.TargetLocation,
+			References:     tfContext.References,
+			Interpolations: []common.Interpolation{
+				{Value: config.AccountID, Expr: "local.account_id"},
+				{Value: config.TenantName, Expr: "local.tenant_name"},
+				{Value: "duploservices-" + config.TenantName, Expr: "local.tenant_iam_role_name"},
+			},
+		}
+		err := referenceResolver.Resolve()
+		if err != nil {
+			tfg.Report.Add(NewTFGeneratorError("references", targetLocation, err, false))
 		}
 	}
-	// 2. Generate input vars.
+	// 3. Generate input vars.
 	if len(tfContext.InputVars) > 0 {
 		varsGenerator := common.Vars{
 			TargetLocation: tfContext.TargetLocation,
 			Vars:           tfContext.InputVars,
 		}
-		varsGenerator.Generate()
+		err := varsGenerator.Generate()
+		if err != nil {
+			tfg.Report.Add(NewTFGeneratorError("variables", targetLocation, err, true))
+		}
 	}
-	// 3. Generate output vars.
+	// 4. Generate output vars.
 	if len(tfContext.OutputVars) > 0 {
 		outVarsGenerator := common.OutputVars{
 			TargetLocation: tfContext.TargetLocation,
 			OutputVars:     tfContext.OutputVars,
 		}
-		outVarsGenerator.Generate()
+		err := outVarsGenerator.Generate()
+		if err != nil {
+			tfg.Report.Add(NewTFGeneratorError("outputs", targetLocation, err, true))
+		}
 	}
-	// 4. Import all resources
+	// 5. Import all resources
 	if config.GenerateTfState && len(tfContext.ImportConfigs) > 0 {
 		tfInitializer := common.TfInitializer{
 			WorkingDir: targetLocation,
 			Config:     config,
 		}
-		tf := tfInitializer.InitWithWorkspace()
+		tf, err := tfInitializer.InitWithWorkspace()
+		if err != nil {
+			tfg.Report.Add(NewTFGeneratorError("terraform init", targetLocation, err, true))
+			return
+		}
 		importer := &common.Importer{}
 		// Get state file if already present.
 		state, err := tf.Show(context.Background())
@@ -152,12 +214,169 @@ func starTFGenerationForProject(config *common.Config, client *duplosdk.Client,
 				log.Printf("[TRACE] Resource %s is already imported.", ic.ResourceAddress)
 				continue
 			}
-			importer.ImportWithoutInit(config, &ic, tf)
+			err = importer.ImportWithoutInit(config, &ic, tf)
+			if err != nil {
+				tfg.Report.Add(NewTFGeneratorError("terraform import", ic.ResourceAddress, err, false))
+			}
 		}
 		//tfInitializer.DeleteWorkspace(config, tf)
 	}
 }
 
+// mergeResults adds the contexts of the generators to the context of the project.
+func (tfg *TfGeneratorService) mergeResults(tfContext *common.TFContext, generators []Generator, results []generatorResult) {
+	// Results are merged in the order of the generators, so the generated files do not depend on the scheduling.
+	// A variable or output declared twice, by two generators or by the same one, is rejected and keeps its first declaration.
+	varOwners := map[string]string{}
+	outputVarOwners := map[string]string{}
+	referenceOwners := map[string]string{}
+	for i, g := range generators {
+		c, err := results[i].tfContext, results[i].err
+		generatorName := getGeneratorName(g)
+		// A failed generator keeps the context of the files it has written, so that they still find their variables.
+		if err != nil {
+			resource := tfContext.TargetLocation
+			var resourceErr *common.ResourceError
+			if errors.As(err, &resourceErr) {
+				resource, err = resourceErr.Resource, resourceErr.Cause
+			}
+			tfg.Report.Add(NewTFGeneratorError(generatorName, resource, err, containsGenerator(CoreGenerators, g)))
+		}
+		if c != nil {
+			for _, v := range c.InputVars {
+				if owner, ok := varOwners[v.Name]; ok {
+					tfg.Report.Add(NewTFGeneratorError(generatorName, v.Name, fmt.Errorf("variable %q is already declared by %s", v.Name, owner), true))
+					continue
+				}
+				varOwners[v.Name] = generatorName
+				tfContext.InputVars = append(tfContext.InputVars, v)
+			}
+			for _, ov := range c.OutputVars {
+				if owner, ok := outputVarOwners[ov.Name]; ok {
+					tfg.Report.Add(NewTFGeneratorError(generatorName, ov.Name, fmt.Errorf("output %q is already declared by %s", ov.Name, owner), true))
+					continue
+				}
+				outputVarOwners[ov.Name] = generatorName
+				tfContext.OutputVars = append(tfContext.OutputVars, ov)
+			}
+			if len(c.ImportConfigs) > 0 {
+				tfContext.ImportConfigs = append(tfContext.ImportConfigs, c.ImportConfigs...)
+			}
+			// A value registered twice, like a target group generated by two generators, keeps its first address.
+			for _, ref := range c.References {
+				if len(ref.Value) == 0 {
+					continue
+				}
+				key := ref.Value + "|" + strings.Join(ref.Attributes, ",")
+				if owner, ok := referenceOwners[key]; ok {
+					if owner != ref.Address {
+						tfg.Report.Add(NewTFGeneratorError(generatorName, ref.Address, fmt.Errorf("%q is already registered as %s", ref.Value, owner), false))
+					}
+					continue
+				}
+				referenceOwners[key] = ref.Address
+				tfContext.References = append(tfContext.References, ref)
+			}
+		}
+	}
+}
+
+type generatorResult struct {
+	tfContext *common.TFContext
+	err       error
+}
+
+// runGenerators runs the generators with a pool of at most parallelism workers.
+// The result of a generator is returned at the same index as the generator in generatorList.
+func runGenerators(config *common.Config, client *duplosdk.Client, generatorList []Generator, parallelism int) []generatorResult {
+	results := make([]generatorResult, len(generatorList))
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	if parallelism > len(generatorList) {
+		parallelism = len(generatorList)
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < parallelism; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				c, err := runGenerator(config, client, generatorList[i])
+				results[i] = generatorResult{tfContext: c, err: err}
+			}
+		}()
+	}
+	for i := range generatorList {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// runGenerator runs a single generator, turning a panic into an error so that the remaining generators still run.
+func runGenerator(config *common.Config, client *duplosdk.Client, g Generator) (c *common.TFContext, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return g.Generate(config, client)
+}
+
+// checkGeneratorOrder fails when a generator comes before a generator it depends on.
+func checkGeneratorOrder(generatorList []Generator) error {
+	for i, g := range generatorList {
+		for _, dependency := range getGeneratorDependencies(g) {
+			found := false
+			for _, previous := range generatorList[:i] {
+				if getGeneratorName(previous) == getGeneratorName(dependency) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("%s has to run after %s", getGeneratorName(g), getGeneratorName(dependency))
+			}
+		}
+	}
+	return nil
+}
+
+func getGeneratorDependencies(g Generator) []Generator {
+	for _, dependency := range GeneratorDependencies {
+		if getGeneratorName(dependency.Generator) == getGeneratorName(g) {
+			return dependency.DependsOn
+		}
+	}
+	return nil
+}
+
+// isGeneratorDependency reports a generator which depends on another generator or which another generator depends on.
+func isGeneratorDependency(g Generator) bool {
+	for _, dependency := range GeneratorDependencies {
+		if getGeneratorName(dependency.Generator) == getGeneratorName(g) || containsGenerator(dependency.DependsOn, g) {
+			return true
+		}
+	}
+	return false
+}
+
+func getGeneratorName(g Generator) string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", g), "*")
+}
+
+func containsGenerator(generatorList []Generator, g Generator) bool {
+	for _, item := range generatorList {
+		if getGeneratorName(item) == getGeneratorName(g) {
+			return true
+		}
+	}
+	return false
+}
+
 func (tfg *TfGeneratorService) PostProcess(config *common.Config, client *duplosdk.Client) error {
 	return nil
 }
@@ -0,0 +1,128 @@
+package tfgenerator
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"tenant-native-terraform-generator/duplosdk"
+	"tenant-native-terraform-generator/tf-generator/common"
+)
+
+// fakeGenerator returns a fixed context after a random delay, so that concurrent runs finish in a random order.
+type fakeGenerator struct {
+	index int
+}
+
+func (g *fakeGenerator) Generate(config *common.Config, client *duplosdk.Client) (*common.TFContext, error) {
+	time.Sleep(time.Duration(rand.Intn(5)) * time.Millisecond)
+	name := fmt.Sprintf("fake_%02d", g.index)
+	return &common.TFContext{
+		InputVars: []common.VarConfig{
+			{Name: name + "_var", TypeVal: "string", DefaultVal: name},
+		},
+		OutputVars: []common.OutputVarConfig{
+			{Name: name + "_output", ActualVal: "local." + name},
+		},
+		ImportConfigs: []common.ImportConfig{
+			{ResourceAddress: "aws_s3_bucket." + name, ResourceId: name},
+		},
+		References: []common.ResourceReference{
+			{Value: "id-" + name, Address: "aws_s3_bucket." + name + ".id"},
+		},
+	}, nil
+}
+
+func TestMergeResultsIsDeterministic(t *testing.T) {
+	generators := []Generator{}
+	for i := 0; i < 20; i++ {
+		generators = append(generators, &fakeGenerator{index: i})
+	}
+	config := &common.Config{Parallelism: 8}
+
+	var first common.TFContext
+	var firstVars []byte
+	for run := 0; run < 10; run++ {
+		targetLocation := t.TempDir()
+		tfg := &TfGeneratorService{}
+		tfContext := common.TFContext{TargetLocation: targetLocation}
+		results := runGenerators(config, nil, generators, config.Parallelism)
+		tfg.mergeResults(&tfContext, generators, results)
+		if len(tfg.Report.Errors) > 0 {
+			t.Fatalf("run %d: unexpected errors %v", run, tfg.Report.Errors)
+		}
+
+		varsGenerator := common.Vars{
+			TargetLocation: targetLocation,
+			Vars:           tfContext.InputVars,
+		}
+		if err := varsGenerator.Generate(); err != nil {
+			t.Fatalf("run %d: %s", run, err)
+		}
+		vars, err := ioutil.ReadFile(filepath.Join(targetLocation, "vars.tf"))
+		if err != nil {
+			t.Fatalf("run %d: %s", run, err)
+		}
+
+		tfContext.TargetLocation = ""
+		if run == 0 {
+			first, firstVars = tfContext, vars
+			continue
+		}
+		if !reflect.DeepEqual(first, tfContext) {
+			t.Fatalf("run %d: merged context differs from the first run\n%+v\n%+v", run, first, tfContext)
+		}
+		if string(firstVars) != string(vars) {
+			t.Fatalf("run %d: vars.tf differs from the first run\n%s\n%s", run, firstVars, vars)
+		}
+	}
+	if first.InputVars[0].Name != "fake_00_var" || first.InputVars[19].Name != "fake_19_var" {
+		t.Fatalf("variables are not merged in the order of the generators: %+v", first.InputVars)
+	}
+}
+
+func TestCheckGeneratorOrder(t *testing.T) {
+	for _, generatorList := range [][]Generator{TenantGenerators, K8sGenerators} {
+		if err := checkGeneratorOrder(generatorList); err != nil {
+			t.Errorf("registered generators are out of order: %s", err)
+		}
+	}
+	reversed := []Generator{}
+	for i := len(K8sGenerators) - 1; i >= 0; i-- {
+		reversed = append(reversed, K8sGenerators[i])
+	}
+	if err := checkGeneratorOrder(reversed); err == nil {
+		t.Errorf("expected an error for generators running before their dependencies")
+	}
+}
+
+func TestMergeResultsRejectsDuplicateVariables(t *testing.T) {
+	first := common.VarConfig{Name: "duplicate", TypeVal: "string", DefaultVal: "first"}
+	second := common.VarConfig{Name: "duplicate", TypeVal: "string", DefaultVal: "second"}
+	generators := []Generator{&fakeGenerator{index: 0}, &fakeGenerator{index: 1}}
+	results := []generatorResult{
+		{tfContext: &common.TFContext{InputVars: []common.VarConfig{first, second}}},
+		{tfContext: &common.TFContext{InputVars: []common.VarConfig{second}}},
+	}
+	tfg := &TfGeneratorService{}
+	tfContext := common.TFContext{TargetLocation: t.TempDir()}
+	tfg.mergeResults(&tfContext, generators, results)
+	if len(tfg.Report.Errors) != 2 {
+		t.Fatalf("expected the duplicates of the same and of another generator to be reported, got %v", tfg.Report.Errors)
+	}
+	if len(tfContext.InputVars) != 1 || tfContext.InputVars[0].DefaultVal != "first" {
+		t.Fatalf("expected the first declaration to be kept, got %+v", tfContext.InputVars)
+	}
+
+	varsGenerator := common.Vars{
+		TargetLocation: tfContext.TargetLocation,
+		Vars:           []common.VarConfig{first, second},
+	}
+	if err := varsGenerator.Generate(); err != nil {
+		t.Fatalf("expected vars.tf to be written despite the duplicate: %s", err)
+	}
+}
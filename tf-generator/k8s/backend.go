@@ -0,0 +1,55 @@
+package k8s
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"tenant-native-terraform-generator/duplosdk"
+	"tenant-native-terraform-generator/tf-generator/common"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type K8sBackend struct {
+}
+
+func (kb *K8sBackend) Generate(config *common.Config, client *duplosdk.Client) (*common.TFContext, error) {
+	log.Println("[TRACE] <====== K8s backend TF generation started. =====>")
+	hclFile := hclwrite.NewEmptyFile()
+
+	path := filepath.Join(config.TFCodePath, config.K8sProject, "backend.tf")
+	tfFile, err := os.Create(path)
+	if err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+	rootBody := hclFile.Body()
+
+	tfBlock := rootBody.AppendNewBlock("terraform",
+		nil)
+	tfBlockBody := tfBlock.Body()
+	s3Backend := tfBlockBody.AppendNewBlock("backend",
+		[]string{"s3"})
+
+	s3BackendBody := s3Backend.Body()
+
+	s3BackendBody.SetAttributeValue("region",
+		cty.StringVal(config.AwsRegion))
+	s3BackendBody.SetAttributeValue("key",
+		cty.StringVal(config.K8sProject))
+
+	s3BackendBody.SetAttributeValue("workspace_key_prefix",
+		cty.StringVal("tenant:"))
+	s3BackendBody.SetAttributeValue("encrypt",
+		cty.True)
+
+	_, err = tfFile.Write(hclFile.Bytes())
+	if err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+	log.Println("[TRACE] <====== K8s backend TF generation done. =====>")
+	return nil, nil
+}
@@ -0,0 +1,108 @@
+package k8s
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"tenant-native-terraform-generator/duplosdk"
+	"tenant-native-terraform-generator/tf-generator/common"
+)
+
+const KUBERNETES_CONFIG_MAP = "kubernetes_config_map"
+const K8S_CONFIG_MAP_FILE_NAME_PREFIX = "k8s-configmap-"
+
+// Config map with the cluster CA which kubernetes publishes into every namespace.
+const K8S_ROOT_CA_CONFIG_MAP = "kube-root-ca.crt"
+
+type K8sConfigMap struct {
+}
+
+func (k8sConfigMap *K8sConfigMap) Generate(config *common.Config, client *duplosdk.Client) (*common.TFContext, error) {
+	workingDir := filepath.Join(config.TFCodePath, config.K8sProject)
+	list, clientErr := client.K8ConfigMapGetList(config.TenantId)
+
+	if clientErr != nil {
+		fmt.Println(clientErr)
+		return nil, clientErr
+	}
+	tfContext := common.TFContext{}
+	importConfigs := []common.ImportConfig{}
+	if list != nil && len(*list) > 0 {
+		log.Println("[TRACE] <====== K8s config map TF generation started. =====>")
+		for _, configMap := range *list {
+			if len(configMap.Name) == 0 || configMap.Name == K8S_ROOT_CA_CONFIG_MAP {
+				continue
+			}
+			resourceName := common.GetResourceName(configMap.Name)
+
+			hclFile := hclwrite.NewEmptyFile()
+			path := filepath.Join(workingDir, K8S_CONFIG_MAP_FILE_NAME_PREFIX+configMap.Name+".tf")
+			tfFile, err := os.Create(path)
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			rootBody := hclFile.Body()
+
+			// Add kubernetes_config_map resource
+			configMapBlock := rootBody.AppendNewBlock("resource",
+				[]string{KUBERNETES_CONFIG_MAP,
+					resourceName})
+			configMapBody := configMapBlock.Body()
+			metadataBlock := configMapBody.AppendNewBlock(K8S_METADATA,
+				nil)
+			metadataBody := metadataBlock.Body()
+			metadataBody.SetAttributeValue(K8S_NAME,
+				cty.StringVal(configMap.Name))
+			if err := setK8sNamespace(metadataBody); err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			setK8sStringMap(metadataBody, K8S_ANNOTATIONS, getK8sStringMap(configMap.Metadata[K8S_ANNOTATIONS]))
+			setK8sStringMap(metadataBody, K8S_LABELS, getK8sStringMap(configMap.Metadata[K8S_LABELS]))
+			setK8sStringMap(configMapBody, K8S_DATA, getK8sStringMap(configMap.Data))
+
+			if config.GenerateTfState {
+				importConfigs = append(importConfigs, common.ImportConfig{
+					ResourceAddress: strings.Join([]string{
+						KUBERNETES_CONFIG_MAP,
+						resourceName,
+					}, "."),
+					ResourceId: getK8sNamespace(config) + "/" + configMap.Name,
+					WorkingDir: workingDir,
+				})
+				tfContext.ImportConfigs = importConfigs
+			}
+
+			_, err = tfFile.Write(hclFile.Bytes())
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			log.Printf("[TRACE] Terraform config is generated for k8s config map : %s", configMap.Name)
+		}
+		log.Println("[TRACE] <====== K8s config map TF generation done. =====>")
+	}
+	return &tfContext, nil
+}
+
+// getK8sStringMap converts a loosely typed map from the duplo API into a map of strings.
+func getK8sStringMap(value interface{}) map[string]string {
+	values := map[string]string{}
+	if m, ok := value.(map[string]interface{}); ok {
+		for key, v := range m {
+			if s, ok := v.(string); ok {
+				values[key] = s
+			} else if v != nil {
+				values[key] = fmt.Sprint(v)
+			}
+		}
+	}
+	return values
+}
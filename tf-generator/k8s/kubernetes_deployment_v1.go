@@ -0,0 +1,730 @@
+package k8s
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"tenant-native-terraform-generator/duplosdk"
+	"tenant-native-terraform-generator/tf-generator/common"
+)
+
+const (
+	DEPLOYMENT_SPEC                 string = "spec"
+	DEPLOYMENT_REPLICAS             string = "replicas"
+	DEPLOYMENT_SELECTOR             string = "selector"
+	DEPLOYMENT_MATCH_LABELS         string = "match_labels"
+	DEPLOYMENT_TEMPLATE             string = "template"
+	DEPLOYMENT_CONTAINER            string = "container"
+	DEPLOYMENT_IMAGE                string = "image"
+	DEPLOYMENT_IMAGE_PULL_POLICY    string = "image_pull_policy"
+	DEPLOYMENT_COMMAND              string = "command"
+	DEPLOYMENT_ARGS                 string = "args"
+	DEPLOYMENT_PORT                 string = "port"
+	DEPLOYMENT_CONTAINER_PORT       string = "container_port"
+	DEPLOYMENT_PROTOCOL             string = "protocol"
+	DEPLOYMENT_ENV                  string = "env"
+	DEPLOYMENT_ENV_FROM             string = "env_from"
+	DEPLOYMENT_VALUE                string = "value"
+	DEPLOYMENT_VALUE_FROM           string = "value_from"
+	DEPLOYMENT_SECRET_KEY_REF       string = "secret_key_ref"
+	DEPLOYMENT_CONFIG_MAP_KEY_REF   string = "config_map_key_ref"
+	DEPLOYMENT_SECRET_REF           string = "secret_ref"
+	DEPLOYMENT_CONFIG_MAP_REF       string = "config_map_ref"
+	DEPLOYMENT_KEY                  string = "key"
+	DEPLOYMENT_FIELD_REF            string = "field_ref"
+	DEPLOYMENT_API_VERSION          string = "api_version"
+	DEPLOYMENT_FIELD_PATH           string = "field_path"
+	DEPLOYMENT_RESOURCE_FIELD_REF   string = "resource_field_ref"
+	DEPLOYMENT_CONTAINER_NAME       string = "container_name"
+	DEPLOYMENT_RESOURCE             string = "resource"
+	DEPLOYMENT_DIVISOR              string = "divisor"
+	DEPLOYMENT_RESOURCES            string = "resources"
+	DEPLOYMENT_LIMITS               string = "limits"
+	DEPLOYMENT_REQUESTS             string = "requests"
+	DEPLOYMENT_VOLUME_MOUNT         string = "volume_mount"
+	DEPLOYMENT_MOUNT_PATH           string = "mount_path"
+	DEPLOYMENT_SUB_PATH             string = "sub_path"
+	DEPLOYMENT_READ_ONLY            string = "read_only"
+	DEPLOYMENT_VOLUME               string = "volume"
+	DEPLOYMENT_SECRET               string = "secret"
+	DEPLOYMENT_SECRET_NAME          string = "secret_name"
+	DEPLOYMENT_CONFIG_MAP           string = "config_map"
+	DEPLOYMENT_PVC                  string = "persistent_volume_claim"
+	DEPLOYMENT_CLAIM_NAME           string = "claim_name"
+	DEPLOYMENT_HOST_PATH            string = "host_path"
+	DEPLOYMENT_PATH                 string = "path"
+	DEPLOYMENT_EMPTY_DIR            string = "empty_dir"
+	SERVICE_TYPE                    string = "type"
+	SERVICE_TARGET_PORT             string = "target_port"
+	SERVICE_NODE_PORT               string = "node_port"
+	SERVICE_EXTERNAL_TRAFFIC_POLICY string = "external_traffic_policy"
+)
+
+const KUBERNETES_DEPLOYMENT_V1 = "kubernetes_deployment_v1"
+const KUBERNETES_SERVICE_V1 = "kubernetes_service_v1"
+const K8S_DEPLOYMENT_FILE_NAME_PREFIX = "k8s-deployment-"
+const K8S_DEPLOYMENT_VAR_PREFIX = "k8s_deployment_"
+
+// Label duplo selects the pods of a service by, its value is the service name.
+const K8S_APP_LABEL = "app"
+
+// Duplo lb type which is served by a cluster internal kubernetes service, every other lb type targets node ports.
+const K8S_LB_TYPE_CLUSTER_IP = 3
+
+type K8sDeployment struct {
+}
+
+func (k8sDeployment *K8sDeployment) Generate(config *common.Config, client *duplosdk.Client) (*common.TFContext, error) {
+	workingDir := filepath.Join(config.TFCodePath, config.K8sProject)
+	if !config.K8sExportServices {
+		return nil, nil
+	}
+	list, lbConfigs, err := getK8sServices(config, client)
+	if err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+	tfContext := common.TFContext{}
+	importConfigs := []common.ImportConfig{}
+	if len(list) > 0 {
+		log.Println("[TRACE] <====== K8s deployment TF generation started. =====>")
+		secretNames, configMapNames, err := getK8sGeneratedNames(config, client)
+		if err != nil {
+			fmt.Println(err)
+			return &tfContext, err
+		}
+		secretsFile := hclwrite.NewEmptyFile()
+		secretsBody := secretsFile.Body()
+		for _, rc := range list {
+			resourceName := common.GetResourceName(rc.Name)
+			labels := map[string]string{K8S_APP_LABEL: rc.Name}
+
+			hclFile := hclwrite.NewEmptyFile()
+			path := filepath.Join(workingDir, K8S_DEPLOYMENT_FILE_NAME_PREFIX+rc.Name+".tf")
+			tfFile, err := os.Create(path)
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			rootBody := hclFile.Body()
+
+			// Add kubernetes_deployment_v1 resource
+			deploymentBlock := rootBody.AppendNewBlock("resource",
+				[]string{KUBERNETES_DEPLOYMENT_V1,
+					resourceName})
+			deploymentBody := deploymentBlock.Body()
+			metadataBlock := deploymentBody.AppendNewBlock(K8S_METADATA,
+				nil)
+			metadataBody := metadataBlock.Body()
+			metadataBody.SetAttributeValue(K8S_NAME,
+				cty.StringVal(rc.Name))
+			if err := setK8sNamespace(metadataBody); err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			setK8sStringMap(metadataBody, K8S_LABELS, labels)
+
+			specBlock := deploymentBody.AppendNewBlock(DEPLOYMENT_SPEC,
+				nil)
+			specBody := specBlock.Body()
+			specBody.SetAttributeValue(DEPLOYMENT_REPLICAS,
+				cty.StringVal(strconv.Itoa(rc.Replicas)))
+			selectorBlock := specBody.AppendNewBlock(DEPLOYMENT_SELECTOR,
+				nil)
+			setK8sStringMap(selectorBlock.Body(), DEPLOYMENT_MATCH_LABELS, labels)
+
+			templateBlock := specBody.AppendNewBlock(DEPLOYMENT_TEMPLATE,
+				nil)
+			templateBody := templateBlock.Body()
+			templateMetadataBlock := templateBody.AppendNewBlock(K8S_METADATA,
+				nil)
+			setK8sStringMap(templateMetadataBlock.Body(), K8S_LABELS, labels)
+			podSpecBlock := templateBody.AppendNewBlock(DEPLOYMENT_SPEC,
+				nil)
+			podSpecBody := podSpecBlock.Body()
+
+			dockerConfig := map[string]interface{}{}
+			if len(rc.Template.OtherDockerConfig) > 0 {
+				err = json.Unmarshal([]byte(rc.Template.OtherDockerConfig), &dockerConfig)
+				if err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+			}
+			// The other docker config describes the primary container, the other pod config the additional containers.
+			podConfig := map[string]interface{}{}
+			if len(rc.Template.OtherDockerHostConfig) > 0 {
+				err = json.Unmarshal([]byte(rc.Template.OtherDockerHostConfig), &podConfig)
+				if err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+			}
+			for i, container := range getK8sContainers(rc) {
+				containerBlock := podSpecBody.AppendNewBlock(DEPLOYMENT_CONTAINER,
+					nil)
+				containerBody := containerBlock.Body()
+				containerBody.SetAttributeValue(K8S_NAME,
+					cty.StringVal(container.Name))
+				containerBody.SetAttributeValue(DEPLOYMENT_IMAGE,
+					cty.StringVal(container.Image))
+				containerConfig := dockerConfig
+				envVarName := K8S_DEPLOYMENT_VAR_PREFIX + resourceName + "_env"
+				containerPorts := map[int]string{}
+				if i > 0 {
+					containerConfig = getK8sAdditionalContainer(podConfig, container.Name)
+					if containerConfig == nil {
+						log.Printf("[WARN] Container (%s) of k8s deployment (%s) is exported with its image only, its config is not in the other pod config.", container.Name, rc.Name)
+						continue
+					}
+					envVarName = K8S_DEPLOYMENT_VAR_PREFIX + resourceName + "_" + common.GetResourceName(container.Name) + "_env"
+					containerPorts = getK8sContainerPorts(getK8sConfigValue(containerConfig, "Ports"))
+				} else {
+					for _, lbConfig := range lbConfigs[rc.Name] {
+						if port, err := strconv.Atoi(lbConfig.Port); err == nil {
+							containerPorts[port] = getK8sProtocol(lbConfig.Protocol)
+						}
+					}
+				}
+				if pullPolicy, ok := getK8sConfigValue(containerConfig, "ImagePullPolicy").(string); ok && len(pullPolicy) > 0 {
+					containerBody.SetAttributeValue(DEPLOYMENT_IMAGE_PULL_POLICY,
+						cty.StringVal(pullPolicy))
+				}
+				setK8sStringList(containerBody, DEPLOYMENT_COMMAND, getK8sConfigValue(containerConfig, "Command"))
+				setK8sStringList(containerBody, DEPLOYMENT_ARGS, getK8sConfigValue(containerConfig, "Args"))
+
+				ports := []int{}
+				for port := range containerPorts {
+					ports = append(ports, port)
+				}
+				sort.Ints(ports)
+				for _, port := range ports {
+					portBlock := containerBody.AppendNewBlock(DEPLOYMENT_PORT,
+						nil)
+					portBody := portBlock.Body()
+					portBody.SetAttributeValue(DEPLOYMENT_CONTAINER_PORT,
+						cty.NumberIntVal(int64(port)))
+					portBody.SetAttributeValue(DEPLOYMENT_PROTOCOL,
+						cty.StringVal(containerPorts[port]))
+				}
+
+				// Env values may hold credentials, they are never written to the generated code and have to be supplied as input.
+				envValues, err := appendK8sContainerEnv(containerBody, getK8sConfigValue(containerConfig, "Env"), "var."+envVarName, secretNames, configMapNames)
+				if err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				if len(envValues) > 0 {
+					tfContext.InputVars = append(tfContext.InputVars, common.VarConfig{
+						Name:      envVarName,
+						TypeVal:   "map(string)",
+						DescVal:   "Environment variables of the container " + container.Name + " of the kubernetes deployment " + rc.Name + ".",
+						Sensitive: true,
+					})
+					exampleValues := map[string]cty.Value{}
+					for name := range envValues {
+						exampleValues[name] = cty.StringVal("")
+					}
+					secretsBody.SetAttributeValue(envVarName,
+						cty.MapVal(exampleValues))
+				}
+				if err := appendK8sContainerEnvFrom(containerBody, getK8sConfigValue(containerConfig, "EnvFrom"), secretNames, configMapNames); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				if resources, ok := getK8sConfigValue(containerConfig, "Resources").(map[string]interface{}); ok {
+					limits := getK8sStringMap(getK8sConfigValue(resources, "Limits"))
+					requests := getK8sStringMap(getK8sConfigValue(resources, "Requests"))
+					if len(limits) > 0 || len(requests) > 0 {
+						resourcesBlock := containerBody.AppendNewBlock(DEPLOYMENT_RESOURCES,
+							nil)
+						setK8sStringMap(resourcesBlock.Body(), DEPLOYMENT_LIMITS, limits)
+						setK8sStringMap(resourcesBlock.Body(), DEPLOYMENT_REQUESTS, requests)
+					}
+				}
+
+				// The pod volumes are added with the primary container, additional containers only mount them.
+				volumes := ""
+				if i == 0 {
+					volumes = rc.Template.Volumes
+					if len(volumes) == 0 {
+						volumes = rc.Volumes
+					}
+				}
+				err = appendK8sVolumes(containerBody, podSpecBody, volumes, getK8sConfigValue(containerConfig, "VolumeMounts"), secretNames, configMapNames)
+				if err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+			}
+
+			if config.GenerateTfState {
+				importConfigs = append(importConfigs, common.ImportConfig{
+					ResourceAddress: strings.Join([]string{
+						KUBERNETES_DEPLOYMENT_V1,
+						resourceName,
+					}, "."),
+					ResourceId: getK8sNamespace(config) + "/" + rc.Name,
+					WorkingDir: workingDir,
+				})
+				tfContext.ImportConfigs = importConfigs
+			}
+
+			if len(lbConfigs[rc.Name]) > 0 {
+				// Add kubernetes_service_v1 resource
+				rootBody.AppendNewline()
+				serviceBlock := rootBody.AppendNewBlock("resource",
+					[]string{KUBERNETES_SERVICE_V1,
+						resourceName})
+				serviceBody := serviceBlock.Body()
+				serviceMetadataBlock := serviceBody.AppendNewBlock(K8S_METADATA,
+					nil)
+				serviceMetadataBody := serviceMetadataBlock.Body()
+				serviceMetadataBody.SetAttributeValue(K8S_NAME,
+					cty.StringVal(rc.Name))
+				if err := setK8sNamespace(serviceMetadataBody); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				serviceSpecBlock := serviceBody.AppendNewBlock(DEPLOYMENT_SPEC,
+					nil)
+				serviceSpecBody := serviceSpecBlock.Body()
+				selector := map[string]string{K8S_APP_LABEL: rc.Name}
+				serviceType := "ClusterIP"
+				externalTrafficPolicy := ""
+				for _, lbConfig := range lbConfigs[rc.Name] {
+					if lbConfig.LbType != K8S_LB_TYPE_CLUSTER_IP {
+						serviceType = "NodePort"
+					}
+					if len(lbConfig.ExternalTrafficPolicy) > 0 {
+						externalTrafficPolicy = lbConfig.ExternalTrafficPolicy
+					}
+					if lbConfig.ExtraSelectorLabels != nil {
+						for _, label := range *lbConfig.ExtraSelectorLabels {
+							selector[label.Key] = label.Value
+						}
+					}
+				}
+				setK8sStringMap(serviceSpecBody, DEPLOYMENT_SELECTOR, selector)
+				serviceSpecBody.SetAttributeValue(SERVICE_TYPE,
+					cty.StringVal(serviceType))
+				if serviceType != "ClusterIP" && len(externalTrafficPolicy) > 0 {
+					serviceSpecBody.SetAttributeValue(SERVICE_EXTERNAL_TRAFFIC_POLICY,
+						cty.StringVal(externalTrafficPolicy))
+				}
+				generatedPorts := map[int]bool{}
+				for _, lbConfig := range lbConfigs[rc.Name] {
+					if generatedPorts[lbConfig.ExternalPort] {
+						continue
+					}
+					generatedPorts[lbConfig.ExternalPort] = true
+					portBlock := serviceSpecBody.AppendNewBlock(DEPLOYMENT_PORT,
+						nil)
+					portBody := portBlock.Body()
+					// Port names are required as soon as a service exposes more than one port, so every port is named.
+					portBody.SetAttributeValue(K8S_NAME,
+						cty.StringVal("port-"+strconv.Itoa(lbConfig.ExternalPort)))
+					portBody.SetAttributeValue(DEPLOYMENT_PORT,
+						cty.NumberIntVal(int64(lbConfig.ExternalPort)))
+					portBody.SetAttributeValue(SERVICE_TARGET_PORT,
+						cty.StringVal(lbConfig.Port))
+					portBody.SetAttributeValue(DEPLOYMENT_PROTOCOL,
+						cty.StringVal(getK8sProtocol(lbConfig.Protocol)))
+					// Duplo reports the node port of a NodePort service as its host port.
+					if serviceType == "NodePort" && lbConfig.HostPort != 0 {
+						portBody.SetAttributeValue(SERVICE_NODE_PORT,
+							cty.NumberIntVal(int64(lbConfig.HostPort)))
+					}
+				}
+
+				if config.GenerateTfState {
+					importConfigs = append(importConfigs, common.ImportConfig{
+						ResourceAddress: strings.Join([]string{
+							KUBERNETES_SERVICE_V1,
+							resourceName,
+						}, "."),
+						ResourceId: getK8sNamespace(config) + "/" + rc.Name,
+						WorkingDir: workingDir,
+					})
+					tfContext.ImportConfigs = importConfigs
+				}
+			}
+
+			_, err = tfFile.Write(hclFile.Bytes())
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			log.Printf("[TRACE] Terraform config is generated for k8s deployment : %s", rc.Name)
+		}
+
+		// Append the env values to be supplied to the example tfvars.
+		if len(secretsBody.Attributes()) > 0 {
+			err = appendK8sSecretsExample(workingDir, secretsFile)
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, err
+			}
+		}
+		log.Println("[TRACE] <====== K8s deployment TF generation done. =====>")
+	}
+	return &tfContext, nil
+}
+
+// getK8sServices returns the duplo services exported as deployments along with their lb configurations by service name.
+func getK8sServices(config *common.Config, client *duplosdk.Client) ([]duplosdk.DuploReplicationController, map[string][]duplosdk.DuploLbConfiguration, error) {
+	list, clientErr := client.ReplicationControllerList(config.TenantId)
+	if clientErr != nil {
+		return nil, nil, clientErr
+	}
+	services := []duplosdk.DuploReplicationController{}
+	lbConfigs := map[string][]duplosdk.DuploLbConfiguration{}
+	if list == nil {
+		return services, lbConfigs, nil
+	}
+	for _, rc := range *list {
+		if rc.IsDaemonset || rc.Template == nil || rc.Template.Containers == nil || len(*rc.Template.Containers) == 0 {
+			log.Printf("[TRACE] Skipping duplo service (%s), only services running a deployment are exported.", rc.Name)
+			continue
+		}
+		rcLbConfigs, clientErr := client.ReplicationControllerLbConfigurationList(config.TenantId, rc.Name)
+		if clientErr != nil {
+			return nil, nil, clientErr
+		}
+		services = append(services, rc)
+		lbConfigs[rc.Name] = *rcLbConfigs
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+	return services, lbConfigs, nil
+}
+
+// getK8sGeneratedNames returns the names of the secrets and config maps generated in the k8s project.
+func getK8sGeneratedNames(config *common.Config, client *duplosdk.Client) (map[string]bool, map[string]bool, error) {
+	secretNames := map[string]bool{}
+	secrets, clientErr := client.K8SecretGetList(config.TenantId)
+	if clientErr != nil {
+		return nil, nil, clientErr
+	}
+	for _, secret := range *secrets {
+		if !isK8sSecretSkipped(&secret) {
+			secretNames[secret.SecretName] = true
+		}
+	}
+	configMapNames := map[string]bool{}
+	configMaps, clientErr := client.K8ConfigMapGetList(config.TenantId)
+	if clientErr != nil {
+		return nil, nil, clientErr
+	}
+	for _, configMap := range *configMaps {
+		if len(configMap.Name) > 0 && configMap.Name != K8S_ROOT_CA_CONFIG_MAP {
+			configMapNames[configMap.Name] = true
+		}
+	}
+	return secretNames, configMapNames, nil
+}
+
+// getK8sConfigValue looks up a key of the duplo docker config, which is not consistent about casing.
+func getK8sConfigValue(config map[string]interface{}, key string) interface{} {
+	for k, v := range config {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return nil
+}
+
+func getK8sConfigString(config map[string]interface{}, key string) string {
+	if value, ok := getK8sConfigValue(config, key).(string); ok {
+		return value
+	}
+	return ""
+}
+
+func getK8sProtocol(protocol string) string {
+	if strings.EqualFold(protocol, "udp") {
+		return "UDP"
+	}
+	return "TCP"
+}
+
+func setK8sStringList(body *hclwrite.Body, attrName string, value interface{}) {
+	list, ok := value.([]interface{})
+	if !ok || len(list) == 0 {
+		return
+	}
+	var vals []cty.Value
+	for _, item := range list {
+		vals = append(vals, cty.StringVal(fmt.Sprint(item)))
+	}
+	body.SetAttributeValue(attrName,
+		cty.ListVal(vals))
+}
+
+// setK8sRefName references a generated secret or config map by name, or keeps the literal name otherwise.
+func setK8sRefName(body *hclwrite.Body, attrName string, name string, resourceType string, generatedNames map[string]bool) error {
+	if generatedNames[name] {
+		return setK8sNameReference(body, attrName, resourceType, common.GetResourceName(name))
+	}
+	body.SetAttributeValue(attrName,
+		cty.StringVal(name))
+	return nil
+}
+
+// getK8sContainers returns the distinct containers of a duplo service, duplo lists a container once per running pod.
+func getK8sContainers(rc duplosdk.DuploReplicationController) []duplosdk.DuploPodContainer {
+	containers := []duplosdk.DuploPodContainer{}
+	names := map[string]bool{}
+	for i, container := range *rc.Template.Containers {
+		if len(container.Name) == 0 {
+			container.Name = rc.Name
+			if i > 0 {
+				container.Name = rc.Name + "-" + strconv.Itoa(i)
+			}
+		}
+		if names[container.Name] {
+			continue
+		}
+		names[container.Name] = true
+		containers = append(containers, container)
+	}
+	return containers
+}
+
+// getK8sAdditionalContainer returns the config of an additional container from the other pod config.
+func getK8sAdditionalContainer(podConfig map[string]interface{}, name string) map[string]interface{} {
+	containers, ok := getK8sConfigValue(podConfig, "AdditionalContainers").([]interface{})
+	if !ok {
+		return nil
+	}
+	for _, item := range containers {
+		if container, ok := item.(map[string]interface{}); ok && getK8sConfigString(container, "Name") == name {
+			return container
+		}
+	}
+	return nil
+}
+
+// getK8sContainerPorts returns the protocols of the ports of a container config by port.
+func getK8sContainerPorts(value interface{}) map[int]string {
+	containerPorts := map[int]string{}
+	ports, ok := value.([]interface{})
+	if !ok {
+		return containerPorts
+	}
+	for _, item := range ports {
+		if port, ok := item.(map[string]interface{}); ok {
+			if containerPort, ok := getK8sConfigValue(port, "ContainerPort").(float64); ok {
+				containerPorts[int(containerPort)] = getK8sProtocol(getK8sConfigString(port, "Protocol"))
+			}
+		}
+	}
+	return containerPorts
+}
+
+// appendK8sContainerEnv adds the env of a container, plain values are read from the map variable valuesRef and returned by name.
+func appendK8sContainerEnv(containerBody *hclwrite.Body, value interface{}, valuesRef string, secretNames map[string]bool, configMapNames map[string]bool) (map[string]string, error) {
+	values := map[string]string{}
+	envs, ok := value.([]interface{})
+	if !ok {
+		return values, nil
+	}
+	for _, item := range envs {
+		env, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name := getK8sConfigString(env, "Name")
+		valueFrom, hasValueFrom := getK8sConfigValue(env, "ValueFrom").(map[string]interface{})
+		if hasValueFrom && !isK8sEnvSourceSupported(valueFrom) {
+			log.Printf("[WARN] Env (%s) is left out, its value source is not supported.", name)
+			continue
+		}
+		envBlock := containerBody.AppendNewBlock(DEPLOYMENT_ENV,
+			nil)
+		envBody := envBlock.Body()
+		envBody.SetAttributeValue(K8S_NAME,
+			cty.StringVal(name))
+		if !hasValueFrom {
+			values[name] = getK8sConfigString(env, "Value")
+			if err := common.SetAttributeExpression(envBody, DEPLOYMENT_VALUE, valuesRef+"["+strconv.Quote(name)+"]"); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		valueFromBlock := envBody.AppendNewBlock(DEPLOYMENT_VALUE_FROM,
+			nil)
+		if ref, ok := getK8sConfigValue(valueFrom, "SecretKeyRef").(map[string]interface{}); ok {
+			refBlock := valueFromBlock.Body().AppendNewBlock(DEPLOYMENT_SECRET_KEY_REF,
+				nil)
+			if err := setK8sRefName(refBlock.Body(), K8S_NAME, getK8sConfigString(ref, "Name"), KUBERNETES_SECRET, secretNames); err != nil {
+				return nil, err
+			}
+			refBlock.Body().SetAttributeValue(DEPLOYMENT_KEY,
+				cty.StringVal(getK8sConfigString(ref, "Key")))
+		} else if ref, ok := getK8sConfigValue(valueFrom, "ConfigMapKeyRef").(map[string]interface{}); ok {
+			refBlock := valueFromBlock.Body().AppendNewBlock(DEPLOYMENT_CONFIG_MAP_KEY_REF,
+				nil)
+			if err := setK8sRefName(refBlock.Body(), K8S_NAME, getK8sConfigString(ref, "Name"), KUBERNETES_CONFIG_MAP, configMapNames); err != nil {
+				return nil, err
+			}
+			refBlock.Body().SetAttributeValue(DEPLOYMENT_KEY,
+				cty.StringVal(getK8sConfigString(ref, "Key")))
+		} else if ref, ok := getK8sConfigValue(valueFrom, "FieldRef").(map[string]interface{}); ok {
+			refBody := valueFromBlock.Body().AppendNewBlock(DEPLOYMENT_FIELD_REF,
+				nil).Body()
+			if apiVersion := getK8sConfigString(ref, "ApiVersion"); len(apiVersion) > 0 {
+				refBody.SetAttributeValue(DEPLOYMENT_API_VERSION,
+					cty.StringVal(apiVersion))
+			}
+			refBody.SetAttributeValue(DEPLOYMENT_FIELD_PATH,
+				cty.StringVal(getK8sConfigString(ref, "FieldPath")))
+		} else if ref, ok := getK8sConfigValue(valueFrom, "ResourceFieldRef").(map[string]interface{}); ok {
+			refBody := valueFromBlock.Body().AppendNewBlock(DEPLOYMENT_RESOURCE_FIELD_REF,
+				nil).Body()
+			if containerName := getK8sConfigString(ref, "ContainerName"); len(containerName) > 0 {
+				refBody.SetAttributeValue(DEPLOYMENT_CONTAINER_NAME,
+					cty.StringVal(containerName))
+			}
+			refBody.SetAttributeValue(DEPLOYMENT_RESOURCE,
+				cty.StringVal(getK8sConfigString(ref, "Resource")))
+			if divisor := getK8sConfigString(ref, "Divisor"); len(divisor) > 0 {
+				refBody.SetAttributeValue(DEPLOYMENT_DIVISOR,
+					cty.StringVal(divisor))
+			}
+		}
+	}
+	return values, nil
+}
+
+// isK8sEnvSourceSupported reports a value source the generated env can be written with.
+func isK8sEnvSourceSupported(valueFrom map[string]interface{}) bool {
+	for _, source := range []string{"SecretKeyRef", "ConfigMapKeyRef", "FieldRef", "ResourceFieldRef"} {
+		if _, ok := getK8sConfigValue(valueFrom, source).(map[string]interface{}); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func appendK8sContainerEnvFrom(containerBody *hclwrite.Body, value interface{}, secretNames map[string]bool, configMapNames map[string]bool) error {
+	envFroms, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+	for _, item := range envFroms {
+		envFrom, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if ref, ok := getK8sConfigValue(envFrom, "SecretRef").(map[string]interface{}); ok {
+			envFromBlock := containerBody.AppendNewBlock(DEPLOYMENT_ENV_FROM,
+				nil)
+			refBlock := envFromBlock.Body().AppendNewBlock(DEPLOYMENT_SECRET_REF,
+				nil)
+			if err := setK8sRefName(refBlock.Body(), K8S_NAME, getK8sConfigString(ref, "Name"), KUBERNETES_SECRET, secretNames); err != nil {
+				return err
+			}
+		} else if ref, ok := getK8sConfigValue(envFrom, "ConfigMapRef").(map[string]interface{}); ok {
+			envFromBlock := containerBody.AppendNewBlock(DEPLOYMENT_ENV_FROM,
+				nil)
+			refBlock := envFromBlock.Body().AppendNewBlock(DEPLOYMENT_CONFIG_MAP_REF,
+				nil)
+			if err := setK8sRefName(refBlock.Body(), K8S_NAME, getK8sConfigString(ref, "Name"), KUBERNETES_CONFIG_MAP, configMapNames); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// appendK8sVolumes adds the duplo volumes to the pod, duplo keeps the mount path next to the volume source.
+func appendK8sVolumes(containerBody *hclwrite.Body, podSpecBody *hclwrite.Body, volumesJson string, volumeMounts interface{}, secretNames map[string]bool, configMapNames map[string]bool) error {
+	if mounts, ok := volumeMounts.([]interface{}); ok {
+		for _, item := range mounts {
+			mount, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			appendK8sVolumeMount(containerBody, getK8sConfigString(mount, "Name"), getK8sConfigString(mount, "MountPath"), getK8sConfigString(mount, "SubPath"), getK8sConfigValue(mount, "ReadOnly") == true)
+		}
+	}
+	if len(volumesJson) == 0 {
+		return nil
+	}
+	volumes := []map[string]interface{}{}
+	err := json.Unmarshal([]byte(volumesJson), &volumes)
+	if err != nil {
+		return err
+	}
+	for _, volume := range volumes {
+		name := getK8sConfigString(volume, "Name")
+		if mountPath := getK8sConfigString(volume, "Path"); len(mountPath) > 0 {
+			appendK8sVolumeMount(containerBody, name, mountPath, "", getK8sConfigValue(volume, "ReadOnly") == true)
+		}
+		spec, ok := getK8sConfigValue(volume, "Spec").(map[string]interface{})
+		if !ok {
+			continue
+		}
+		volumeBlock := podSpecBody.AppendNewBlock(DEPLOYMENT_VOLUME,
+			nil)
+		volumeBody := volumeBlock.Body()
+		volumeBody.SetAttributeValue(K8S_NAME,
+			cty.StringVal(name))
+		if source, ok := getK8sConfigValue(spec, "Secret").(map[string]interface{}); ok {
+			sourceBlock := volumeBody.AppendNewBlock(DEPLOYMENT_SECRET,
+				nil)
+			if err := setK8sRefName(sourceBlock.Body(), DEPLOYMENT_SECRET_NAME, getK8sConfigString(source, "SecretName"), KUBERNETES_SECRET, secretNames); err != nil {
+				return err
+			}
+		} else if source, ok := getK8sConfigValue(spec, "ConfigMap").(map[string]interface{}); ok {
+			sourceBlock := volumeBody.AppendNewBlock(DEPLOYMENT_CONFIG_MAP,
+				nil)
+			if err := setK8sRefName(sourceBlock.Body(), K8S_NAME, getK8sConfigString(source, "Name"), KUBERNETES_CONFIG_MAP, configMapNames); err != nil {
+				return err
+			}
+		} else if source, ok := getK8sConfigValue(spec, "PersistentVolumeClaim").(map[string]interface{}); ok {
+			sourceBlock := volumeBody.AppendNewBlock(DEPLOYMENT_PVC,
+				nil)
+			sourceBlock.Body().SetAttributeValue(DEPLOYMENT_CLAIM_NAME,
+				cty.StringVal(getK8sConfigString(source, "ClaimName")))
+		} else if source, ok := getK8sConfigValue(spec, "HostPath").(map[string]interface{}); ok {
+			sourceBlock := volumeBody.AppendNewBlock(DEPLOYMENT_HOST_PATH,
+				nil)
+			sourceBlock.Body().SetAttributeValue(DEPLOYMENT_PATH,
+				cty.StringVal(getK8sConfigString(source, "Path")))
+		} else if _, ok := getK8sConfigValue(spec, "EmptyDir").(map[string]interface{}); ok {
+			volumeBody.AppendNewBlock(DEPLOYMENT_EMPTY_DIR,
+				nil)
+		} else {
+			log.Printf("[TRACE] Volume (%s) has an unsupported source, it has to be completed manually.", name)
+		}
+	}
+	return nil
+}
+
+func appendK8sVolumeMount(containerBody *hclwrite.Body, name string, mountPath string, subPath string, readOnly bool) {
+	mountBlock := containerBody.AppendNewBlock(DEPLOYMENT_VOLUME_MOUNT,
+		nil)
+	mountBody := mountBlock.Body()
+	mountBody.SetAttributeValue(K8S_NAME,
+		cty.StringVal(name))
+	mountBody.SetAttributeValue(DEPLOYMENT_MOUNT_PATH,
+		cty.StringVal(mountPath))
+	if len(subPath) > 0 {
+		mountBody.SetAttributeValue(DEPLOYMENT_SUB_PATH,
+			cty.StringVal(subPath))
+	}
+	if readOnly {
+		mountBody.SetAttributeValue(DEPLOYMENT_READ_ONLY,
+			cty.BoolVal(readOnly))
+	}
+}
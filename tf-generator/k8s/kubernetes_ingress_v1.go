@@ -0,0 +1,283 @@
+package k8s
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"tenant-native-terraform-generator/duplosdk"
+	"tenant-native-terraform-generator/tf-generator/common"
+)
+
+const (
+	INGRESS_SPEC              string = "spec"
+	INGRESS_CLASS_NAME        string = "ingress_class_name"
+	INGRESS_RULE              string = "rule"
+	INGRESS_HOST              string = "host"
+	INGRESS_HTTP              string = "http"
+	INGRESS_PATH              string = "path"
+	INGRESS_PATH_TYPE         string = "path_type"
+	INGRESS_BACKEND           string = "backend"
+	INGRESS_SERVICE           string = "service"
+	INGRESS_PORT              string = "port"
+	INGRESS_NUMBER            string = "number"
+	INGRESS_TLS               string = "tls"
+	INGRESS_HOSTS             string = "hosts"
+	INGRESS_WAIT_FOR_BALANCER string = "wait_for_load_balancer"
+)
+
+// Annotations of the aws load balancer controller which the duplo lb config translates to.
+const (
+	INGRESS_ALB_SCHEME         string = "alb.ingress.kubernetes.io/scheme"
+	INGRESS_ALB_CERT_ARN       string = "alb.ingress.kubernetes.io/certificate-arn"
+	INGRESS_ALB_LISTEN_PORTS   string = "alb.ingress.kubernetes.io/listen-ports"
+	INGRESS_ALB_WAF_ACL_ARN    string = "alb.ingress.kubernetes.io/wafv2-acl-arn"
+	INGRESS_ALB_LB_ATTRIBUTES  string = "alb.ingress.kubernetes.io/load-balancer-attributes"
+	INGRESS_ALB_DROP_INVALID   string = "routing.http.drop_invalid_header_fields.enabled=true"
+	INGRESS_ALB_INTERNET       string = "internet-facing"
+	INGRESS_ALB_INTERNAL       string = "internal"
+	INGRESS_ALB_LISTENER_HTTP  string = "HTTP"
+	INGRESS_ALB_LISTENER_HTTPS string = "HTTPS"
+)
+
+const KUBERNETES_INGRESS_V1 = "kubernetes_ingress_v1"
+const INGRESS_DEFAULT_PATH_TYPE = "Prefix"
+const K8S_INGRESS_FILE_NAME_PREFIX = "k8s-ingress-"
+
+type K8sIngress struct {
+}
+
+func (k8sIngress *K8sIngress) Generate(config *common.Config, client *duplosdk.Client) (*common.TFContext, error) {
+	workingDir := filepath.Join(config.TFCodePath, config.K8sProject)
+	list, clientErr := client.DuploK8sIngressGetList(config.TenantId)
+
+	if clientErr != nil {
+		fmt.Println(clientErr)
+		return nil, clientErr
+	}
+	tfContext := common.TFContext{}
+	importConfigs := []common.ImportConfig{}
+	if list != nil && len(*list) > 0 {
+		log.Println("[TRACE] <====== K8s ingress TF generation started. =====>")
+		serviceLbConfigs := map[string][]duplosdk.DuploLbConfiguration{}
+		if config.K8sExportServices {
+			var err error
+			_, serviceLbConfigs, err = getK8sServices(config, client)
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, err
+			}
+		}
+		for _, ingress := range *list {
+			resourceName := common.GetResourceName(ingress.Name)
+
+			hclFile := hclwrite.NewEmptyFile()
+			path := filepath.Join(workingDir, K8S_INGRESS_FILE_NAME_PREFIX+ingress.Name+".tf")
+			tfFile, err := os.Create(path)
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			rootBody := hclFile.Body()
+
+			// Add kubernetes_ingress_v1 resource
+			ingressBlock := rootBody.AppendNewBlock("resource",
+				[]string{KUBERNETES_INGRESS_V1,
+					resourceName})
+			ingressBody := ingressBlock.Body()
+			metadataBlock := ingressBody.AppendNewBlock(K8S_METADATA,
+				nil)
+			metadataBody := metadataBlock.Body()
+			metadataBody.SetAttributeValue(K8S_NAME,
+				cty.StringVal(ingress.Name))
+			if err := setK8sNamespace(metadataBody); err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			annotations, err := getK8sIngressAnnotations(&ingress)
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			setK8sStringMap(metadataBody, K8S_ANNOTATIONS, annotations)
+			setK8sStringMap(metadataBody, K8S_LABELS, ingress.Labels)
+
+			specBlock := ingressBody.AppendNewBlock(INGRESS_SPEC,
+				nil)
+			specBody := specBlock.Body()
+			if len(ingress.IngressClassName) > 0 {
+				specBody.SetAttributeValue(INGRESS_CLASS_NAME,
+					cty.StringVal(ingress.IngressClassName))
+			}
+			hosts := []string{}
+			dataServiceNames := []string{}
+			if ingress.Rules != nil && len(*ingress.Rules) > 0 {
+				// Duplo keeps a flat list of paths, kubernetes groups them by host.
+				rulesByHost := map[string][]duplosdk.DuploK8sIngressRule{}
+				for _, rule := range *ingress.Rules {
+					if _, ok := rulesByHost[rule.Host]; !ok {
+						hosts = append(hosts, rule.Host)
+					}
+					rulesByHost[rule.Host] = append(rulesByHost[rule.Host], rule)
+				}
+				for _, host := range hosts {
+					ruleBlock := specBody.AppendNewBlock(INGRESS_RULE,
+						nil)
+					ruleBody := ruleBlock.Body()
+					if len(host) > 0 {
+						ruleBody.SetAttributeValue(INGRESS_HOST,
+							cty.StringVal(host))
+					}
+					httpBlock := ruleBody.AppendNewBlock(INGRESS_HTTP,
+						nil)
+					httpBody := httpBlock.Body()
+					for _, rule := range rulesByHost[host] {
+						pathBlock := httpBody.AppendNewBlock(INGRESS_PATH,
+							nil)
+						pathBody := pathBlock.Body()
+						if len(rule.Path) > 0 {
+							pathBody.SetAttributeValue(INGRESS_PATH,
+								cty.StringVal(rule.Path))
+						}
+						pathType := rule.PathType
+						if len(pathType) == 0 {
+							pathType = INGRESS_DEFAULT_PATH_TYPE
+						}
+						pathBody.SetAttributeValue(INGRESS_PATH_TYPE,
+							cty.StringVal(pathType))
+						backendBlock := pathBody.AppendNewBlock(INGRESS_BACKEND,
+							nil)
+						serviceBlock := backendBlock.Body().AppendNewBlock(INGRESS_SERVICE,
+							nil)
+						serviceBody := serviceBlock.Body()
+						if len(serviceLbConfigs[rule.ServiceName]) > 0 {
+							if err := setK8sNameReference(serviceBody, K8S_NAME, KUBERNETES_SERVICE_V1, common.GetResourceName(rule.ServiceName)); err != nil {
+								fmt.Println(err)
+								return &tfContext, common.NewResourceError(resourceName, err)
+							}
+						} else {
+							// A service which is not generated is read through a data source, so that a missing service fails the plan.
+							dataName := resourceName + "_" + common.GetResourceName(rule.ServiceName)
+							if !common.Contains(dataServiceNames, rule.ServiceName) {
+								dataServiceNames = append(dataServiceNames, rule.ServiceName)
+							}
+							if err := setK8sNameReference(serviceBody, K8S_NAME, "data."+KUBERNETES_SERVICE_V1, dataName); err != nil {
+								fmt.Println(err)
+								return &tfContext, common.NewResourceError(resourceName, err)
+							}
+						}
+						if rule.Port > 0 {
+							portBlock := serviceBody.AppendNewBlock(INGRESS_PORT,
+								nil)
+							portBlock.Body().SetAttributeValue(INGRESS_NUMBER,
+								cty.NumberIntVal(int64(rule.Port)))
+						}
+					}
+				}
+			}
+			// Without an explicit certificate the ALB controller discovers certificates from the tls hosts.
+			if ingress.LbConfig != nil && len(ingress.LbConfig.CertArn) == 0 && ingress.LbConfig.Listeners != nil && len(ingress.LbConfig.Listeners.Https) > 0 {
+				var vals []cty.Value
+				for _, host := range hosts {
+					if len(host) > 0 {
+						vals = append(vals, cty.StringVal(host))
+					}
+				}
+				if len(vals) > 0 {
+					tlsBlock := specBody.AppendNewBlock(INGRESS_TLS,
+						nil)
+					tlsBlock.Body().SetAttributeValue(INGRESS_HOSTS,
+						cty.ListVal(vals))
+				}
+			}
+			ingressBody.SetAttributeValue(INGRESS_WAIT_FOR_BALANCER,
+				cty.BoolVal(true))
+
+			// Add kubernetes_service_v1 data sources
+			for _, serviceName := range dataServiceNames {
+				rootBody.AppendNewline()
+				dataBlock := rootBody.AppendNewBlock("data",
+					[]string{KUBERNETES_SERVICE_V1,
+						resourceName + "_" + common.GetResourceName(serviceName)})
+				dataMetadataBody := dataBlock.Body().AppendNewBlock(K8S_METADATA,
+					nil).Body()
+				dataMetadataBody.SetAttributeValue(K8S_NAME,
+					cty.StringVal(serviceName))
+				if err := setK8sNamespace(dataMetadataBody); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+			}
+
+			if config.GenerateTfState {
+				importConfigs = append(importConfigs, common.ImportConfig{
+					ResourceAddress: strings.Join([]string{
+						KUBERNETES_INGRESS_V1,
+						resourceName,
+					}, "."),
+					ResourceId: getK8sNamespace(config) + "/" + ingress.Name,
+					WorkingDir: workingDir,
+				})
+				tfContext.ImportConfigs = importConfigs
+			}
+
+			_, err = tfFile.Write(hclFile.Bytes())
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			log.Printf("[TRACE] Terraform config is generated for k8s ingress : %s", ingress.Name)
+		}
+		log.Println("[TRACE] <====== K8s ingress TF generation done. =====>")
+	}
+	return &tfContext, nil
+}
+
+// getK8sIngressAnnotations translates the duplo lb config into the annotations understood by the aws load balancer controller.
+// Annotations already present on the ingress are kept as they are.
+func getK8sIngressAnnotations(ingress *duplosdk.DuploK8sIngress) (map[string]string, error) {
+	annotations := map[string]string{}
+	lbConfig := ingress.LbConfig
+	if lbConfig != nil {
+		if lbConfig.IsPublic {
+			annotations[INGRESS_ALB_SCHEME] = INGRESS_ALB_INTERNET
+		} else {
+			annotations[INGRESS_ALB_SCHEME] = INGRESS_ALB_INTERNAL
+		}
+		if len(lbConfig.CertArn) > 0 {
+			annotations[INGRESS_ALB_CERT_ARN] = lbConfig.CertArn
+		}
+		if len(lbConfig.WafArn) > 0 {
+			annotations[INGRESS_ALB_WAF_ACL_ARN] = lbConfig.WafArn
+		}
+		if lbConfig.DropInvalidHeader {
+			annotations[INGRESS_ALB_LB_ATTRIBUTES] = INGRESS_ALB_DROP_INVALID
+		}
+		if lbConfig.Listeners != nil {
+			listenPorts := []map[string]int{}
+			for _, port := range lbConfig.Listeners.Http {
+				listenPorts = append(listenPorts, map[string]int{INGRESS_ALB_LISTENER_HTTP: port})
+			}
+			for _, port := range lbConfig.Listeners.Https {
+				listenPorts = append(listenPorts, map[string]int{INGRESS_ALB_LISTENER_HTTPS: port})
+			}
+			if len(listenPorts) > 0 {
+				listenPortsJson, err := json.Marshal(listenPorts)
+				if err != nil {
+					return nil, err
+				}
+				annotations[INGRESS_ALB_LISTEN_PORTS] = string(listenPortsJson)
+			}
+		}
+	}
+	for key, value := range ingress.Annotations {
+		annotations[key] = value
+	}
+	return annotations, nil
+}
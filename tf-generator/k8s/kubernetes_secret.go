@@ -0,0 +1,189 @@
+package k8s
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"tenant-native-terraform-generator/duplosdk"
+	"tenant-native-terraform-generator/tf-generator/common"
+)
+
+const (
+	K8S_METADATA    string = "metadata"
+	K8S_NAME        string = "name"
+	K8S_NAMESPACE   string = "namespace"
+	K8S_ANNOTATIONS string = "annotations"
+	K8S_LABELS      string = "labels"
+	K8S_DATA        string = "data"
+	K8S_TYPE        string = "type"
+)
+
+const KUBERNETES_SECRET = "kubernetes_secret"
+const K8S_SECRET_VAR_PREFIX = "k8s_secret_"
+const K8S_SECRET_FILE_NAME_PREFIX = "k8s-secret-"
+
+// Annotation written by kubectl apply, it holds a full copy of the object including secret data.
+const K8S_LAST_APPLIED_ANNOTATION = "kubectl.kubernetes.io/last-applied-configuration"
+
+type K8sSecret struct {
+}
+
+func (k8sSecret *K8sSecret) Generate(config *common.Config, client *duplosdk.Client) (*common.TFContext, error) {
+	workingDir := filepath.Join(config.TFCodePath, config.K8sProject)
+	list, clientErr := client.K8SecretGetList(config.TenantId)
+
+	if clientErr != nil {
+		fmt.Println(clientErr)
+		return nil, clientErr
+	}
+	tfContext := common.TFContext{}
+	importConfigs := []common.ImportConfig{}
+	if list != nil && len(*list) > 0 {
+		log.Println("[TRACE] <====== K8s secret TF generation started. =====>")
+		secretsFile := hclwrite.NewEmptyFile()
+		secretsBody := secretsFile.Body()
+		for _, secret := range *list {
+			if isK8sSecretSkipped(&secret) {
+				continue
+			}
+			resourceName := common.GetResourceName(secret.SecretName)
+			varFullPrefix := K8S_SECRET_VAR_PREFIX + resourceName + "_"
+
+			hclFile := hclwrite.NewEmptyFile()
+			path := filepath.Join(workingDir, K8S_SECRET_FILE_NAME_PREFIX+secret.SecretName+".tf")
+			tfFile, err := os.Create(path)
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			rootBody := hclFile.Body()
+
+			// Add kubernetes_secret resource
+			secretBlock := rootBody.AppendNewBlock("resource",
+				[]string{KUBERNETES_SECRET,
+					resourceName})
+			secretBody := secretBlock.Body()
+			metadataBlock := secretBody.AppendNewBlock(K8S_METADATA,
+				nil)
+			metadataBody := metadataBlock.Body()
+			metadataBody.SetAttributeValue(K8S_NAME,
+				cty.StringVal(secret.SecretName))
+			if err := setK8sNamespace(metadataBody); err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			annotations := map[string]string{}
+			for key, value := range secret.SecretAnnotations {
+				annotations[key] = value
+			}
+			setK8sStringMap(metadataBody, K8S_ANNOTATIONS, annotations)
+			if len(secret.SecretType) > 0 {
+				secretBody.SetAttributeValue(K8S_TYPE,
+					cty.StringVal(secret.SecretType))
+			}
+
+			if len(secret.SecretData) > 0 {
+				// Secret data is never written to the generated code, it has to be supplied as input.
+				if err := common.SetAttributeReference(secretBody, K8S_DATA, "var."+varFullPrefix+"data"); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				tfContext.InputVars = append(tfContext.InputVars, common.VarConfig{
+					Name:      varFullPrefix + "data",
+					TypeVal:   "map(string)",
+					DescVal:   "Data of the kubernetes secret " + secret.SecretName + ".",
+					Sensitive: true,
+				})
+				exampleData := map[string]cty.Value{}
+				for key := range secret.SecretData {
+					exampleData[key] = cty.StringVal("")
+				}
+				secretsBody.SetAttributeValue(varFullPrefix+"data",
+					cty.MapVal(exampleData))
+			}
+
+			if config.GenerateTfState {
+				importConfigs = append(importConfigs, common.ImportConfig{
+					ResourceAddress: strings.Join([]string{
+						KUBERNETES_SECRET,
+						resourceName,
+					}, "."),
+					ResourceId: getK8sNamespace(config) + "/" + secret.SecretName,
+					WorkingDir: workingDir,
+				})
+				tfContext.ImportConfigs = importConfigs
+			}
+
+			_, err = tfFile.Write(hclFile.Bytes())
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			log.Printf("[TRACE] Terraform config is generated for k8s secret : %s", secret.SecretName)
+		}
+
+		// Append the secret data to be supplied to the example tfvars.
+		if len(secretsBody.Attributes()) > 0 {
+			err := appendK8sSecretsExample(workingDir, secretsFile)
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, err
+			}
+		}
+		log.Println("[TRACE] <====== K8s secret TF generation done. =====>")
+	}
+	return &tfContext, nil
+}
+
+// appendK8sSecretsExample appends the secure values to be supplied to the example tfvars written by the provider.
+func appendK8sSecretsExample(workingDir string, secretsFile *hclwrite.File) error {
+	path := filepath.Join(workingDir, K8S_SECRETS_EXAMPLE_FILE_NAME)
+	secretsTfFile, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer secretsTfFile.Close()
+	_, err = secretsTfFile.Write(secretsFile.Bytes())
+	return err
+}
+
+// isK8sSecretSkipped reports secrets managed by kubernetes and helm themselves, like service account tokens and helm releases.
+func isK8sSecretSkipped(secret *duplosdk.DuploK8sSecret) bool {
+	return secret.SecretType == "kubernetes.io/service-account-token" || secret.SecretType == "helm.sh/release.v1"
+}
+
+func setK8sNamespace(body *hclwrite.Body) error {
+	return common.SetAttributeReference(body, K8S_NAMESPACE, "local.namespace")
+}
+
+// setK8sNameReference points an attribute at the name of another generated kubernetes resource.
+func setK8sNameReference(body *hclwrite.Body, attrName string, resourceType string, resourceName string) error {
+	return common.SetAttributeReference(body, attrName, resourceType+"."+resourceName+".metadata[0]."+K8S_NAME)
+}
+
+func setK8sStringMap(body *hclwrite.Body, attrName string, values map[string]string) {
+	keys := []string{}
+	for key := range values {
+		if key == K8S_LAST_APPLIED_ANNOTATION {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return
+	}
+	sort.Strings(keys)
+	vals := map[string]cty.Value{}
+	for _, key := range keys {
+		vals[key] = cty.StringVal(values[key])
+	}
+	body.SetAttributeValue(attrName,
+		cty.MapVal(vals))
+}
@@ -0,0 +1,70 @@
+package k8s
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"tenant-native-terraform-generator/duplosdk"
+	"tenant-native-terraform-generator/tf-generator/common"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+type K8sMain struct {
+}
+
+func (km *K8sMain) Generate(config *common.Config, client *duplosdk.Client) (*common.TFContext, error) {
+	workingDir := filepath.Join(config.TFCodePath, config.K8sProject)
+
+	log.Println("[TRACE] <====== K8s main TF generation started. =====>")
+
+	hclFile := hclwrite.NewEmptyFile()
+
+	path := filepath.Join(workingDir, "main.tf")
+	tfFile, err := os.Create(path)
+	if err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+	rootBody := hclFile.Body()
+
+	localsBlock := rootBody.AppendNewBlock("locals",
+		nil)
+	localsBlockBody := localsBlock.Body()
+
+	if err := common.SetAttributeReference(localsBlockBody, "tenant_name", "var.tenant_name"); err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+
+	namespace := common.Template("duploservices-${var.tenant_name}")
+	if err := common.SetAttributeTemplate(localsBlockBody, "namespace", namespace); err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+	rootBody.AppendNewline()
+
+	_, err = tfFile.Write(hclFile.Bytes())
+	if err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+
+	tfContext := common.TFContext{
+		InputVars: []common.VarConfig{
+			{
+				Name:       "tenant_name",
+				DefaultVal: config.TenantName,
+				TypeVal:    "string",
+			},
+		},
+	}
+	log.Println("[TRACE] <====== K8s main TF generation done. =====>")
+	return &tfContext, nil
+}
+
+// getK8sNamespace returns the namespace duplo creates kubernetes resources in for the tenant.
+func getK8sNamespace(config *common.Config) string {
+	return "duploservices-" + config.TenantName
+}
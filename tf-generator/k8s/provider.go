@@ -0,0 +1,133 @@
+package k8s
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"tenant-native-terraform-generator/duplosdk"
+	"tenant-native-terraform-generator/tf-generator/common"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const K8S_SECRETS_EXAMPLE_FILE_NAME = "secrets.auto.tfvars.example"
+
+type K8sProvider struct {
+}
+
+func (kp *K8sProvider) Generate(config *common.Config, client *duplosdk.Client) (*common.TFContext, error) {
+	workingDir := filepath.Join(config.TFCodePath, config.K8sProject)
+
+	log.Println("[TRACE] <====== K8s provider TF generation started. =====>")
+	creds, clientErr := client.GetTenantK8sCredentials(config.TenantId)
+	if clientErr != nil {
+		fmt.Println(clientErr)
+		return nil, clientErr
+	}
+
+	hclFile := hclwrite.NewEmptyFile()
+
+	path := filepath.Join(workingDir, "providers.tf")
+	tfFile, err := os.Create(path)
+	if err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+	rootBody := hclFile.Body()
+
+	tfBlock := rootBody.AppendNewBlock("terraform",
+		nil)
+	tfBlockBody := tfBlock.Body()
+	tfBlockBody.SetAttributeValue("required_version",
+		cty.StringVal(">= "+config.TFVersion))
+
+	reqProvsBlock := tfBlockBody.AppendNewBlock("required_providers",
+		nil)
+	reqProvsBlockBody := reqProvsBlock.Body()
+	reqProvsBlockBody.SetAttributeValue("kubernetes",
+		cty.ObjectVal(map[string]cty.Value{
+			"source":  cty.StringVal("hashicorp/kubernetes"),
+			"version": cty.StringVal("~> " + config.K8sProviderVersion),
+		}))
+	rootBody.AppendNewline()
+
+	k8sProvider := rootBody.AppendNewBlock("provider",
+		[]string{"kubernetes"})
+	k8sProviderBody := k8sProvider.Body()
+	if err := common.SetAttributeReference(k8sProviderBody, "host", "var.k8s_host"); err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+	if len(creds.CertificateAuthorityDataBase64) > 0 {
+		if err := common.SetAttributeExpression(k8sProviderBody, "cluster_ca_certificate", "base64decode(var.k8s_cluster_ca_certificate)"); err != nil {
+			fmt.Println(err)
+			return nil, err
+		}
+	} else {
+		// Skipping the TLS verification has to be an explicit choice of the user.
+		log.Printf("[WARN] Duplo returned no certificate authority data for the kubernetes cluster, set k8s_insecure to true to connect without TLS verification.")
+		if err := common.SetAttributeReference(k8sProviderBody, "insecure", "var.k8s_insecure"); err != nil {
+			fmt.Println(err)
+			return nil, err
+		}
+	}
+	if err := common.SetAttributeReference(k8sProviderBody, "token", "var.k8s_token"); err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+
+	_, err = tfFile.Write(hclFile.Bytes())
+	if err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+
+	// Write the example tfvars listing the secure values to be supplied, secrets are appended to it later.
+	secretsFile := hclwrite.NewEmptyFile()
+	secretsFile.Body().SetAttributeValue("k8s_token",
+		cty.StringVal(""))
+	secretsTfFile, err := os.Create(filepath.Join(workingDir, K8S_SECRETS_EXAMPLE_FILE_NAME))
+	if err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+	_, err = secretsTfFile.Write(secretsFile.Bytes())
+	if err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+
+	tfContext := common.TFContext{}
+	tfContext.InputVars = append(tfContext.InputVars, common.VarConfig{
+		Name:       "k8s_host",
+		DefaultVal: creds.APIServer,
+		TypeVal:    "string",
+		DescVal:    "Endpoint of the kubernetes API server.",
+	})
+	if len(creds.CertificateAuthorityDataBase64) > 0 {
+		tfContext.InputVars = append(tfContext.InputVars, common.VarConfig{
+			Name:       "k8s_cluster_ca_certificate",
+			DefaultVal: creds.CertificateAuthorityDataBase64,
+			TypeVal:    "string",
+			DescVal:    "Base64 encoded certificate authority data of the kubernetes cluster.",
+		})
+	} else {
+		tfContext.InputVars = append(tfContext.InputVars, common.VarConfig{
+			Name:       "k8s_insecure",
+			DefaultVal: "false",
+			TypeVal:    "bool",
+			DescVal:    "Whether to connect to the kubernetes API server without TLS verification, the cluster has no certificate authority data.",
+		})
+	}
+	// The token handed out by duplo is short lived, it is never written to the generated code.
+	tfContext.InputVars = append(tfContext.InputVars, common.VarConfig{
+		Name:      "k8s_token",
+		TypeVal:   "string",
+		DescVal:   "Token used to authenticate with the kubernetes cluster.",
+		Sensitive: true,
+	})
+	log.Println("[TRACE] <====== K8s provider TF generation done. =====>")
+	return &tfContext, nil
+}
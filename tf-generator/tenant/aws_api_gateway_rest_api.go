@@ -0,0 +1,426 @@
+package tenant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/apigateway"
+	"github.com/aws/aws-sdk-go-v2/service/apigateway/types"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"tenant-native-terraform-generator/duplosdk"
+	"tenant-native-terraform-generator/tf-generator/common"
+)
+
+const (
+	APIGW_NAME                   string = "name"
+	APIGW_DESCRIPTION            string = "description"
+	APIGW_BODY                   string = "body"
+	APIGW_POLICY                 string = "policy"
+	APIGW_API_KEY_SOURCE         string = "api_key_source"
+	APIGW_BINARY_MEDIA_TYPES     string = "binary_media_types"
+	APIGW_MIN_COMPRESSION_SIZE   string = "minimum_compression_size"
+	APIGW_DISABLE_EXECUTE_API    string = "disable_execute_api_endpoint"
+	APIGW_ENDPOINT_CONFIGURATION string = "endpoint_configuration"
+	APIGW_TYPES                  string = "types"
+	APIGW_VPC_ENDPOINT_IDS       string = "vpc_endpoint_ids"
+	APIGW_REST_API_ID            string = "rest_api_id"
+	APIGW_TRIGGERS               string = "triggers"
+	APIGW_DEPLOYMENT_ID          string = "deployment_id"
+	APIGW_STAGE_NAME             string = "stage_name"
+	APIGW_VARIABLES              string = "variables"
+	APIGW_XRAY_TRACING_ENABLED   string = "xray_tracing_enabled"
+	APIGW_CACHE_CLUSTER_ENABLED  string = "cache_cluster_enabled"
+	APIGW_CACHE_CLUSTER_SIZE     string = "cache_cluster_size"
+	APIGW_CLIENT_CERTIFICATE_ID  string = "client_certificate_id"
+	APIGW_ACCESS_LOG_SETTINGS    string = "access_log_settings"
+	APIGW_DESTINATION_ARN        string = "destination_arn"
+	APIGW_FORMAT                 string = "format"
+)
+
+const AWS_API_GATEWAY_REST_API = "aws_api_gateway_rest_api"
+const AWS_API_GATEWAY_DEPLOYMENT = "aws_api_gateway_deployment"
+const AWS_API_GATEWAY_STAGE = "aws_api_gateway_stage"
+const APIGW_VAR_PREFIX = "apigw_"
+const APIGW_FILE_NAME_PREFIX = "aws-apigw-"
+
+type AwsApiGatewayRestApi struct {
+}
+
+func (awsApiGatewayRestApi *AwsApiGatewayRestApi) Generate(config *common.Config, client *duplosdk.Client) (*common.TFContext, error) {
+	workingDir := filepath.Join(config.TFCodePath, config.TenantProject)
+	list, clientErr := client.TenantGetApplicationApiGatewayList(config.TenantId)
+
+	if clientErr != nil {
+		fmt.Println(clientErr)
+		return nil, clientErr
+	}
+	tfContext := common.TFContext{}
+	importConfigs := []common.ImportConfig{}
+	if list != nil && len(*list) > 0 {
+		log.Println("[TRACE] <====== API gateway TF generation started. =====>")
+		lbList, clientErr := client.TenantGetApplicationLBList(config.TenantId)
+		if clientErr != nil {
+			fmt.Println(clientErr)
+			return &tfContext, clientErr
+		}
+		apigwClient := apigateway.NewFromConfig(config.AwsClientConfig)
+		restApis := []types.RestApi{}
+		restApisPaginator := apigateway.NewGetRestApisPaginator(apigwClient, &apigateway.GetRestApisInput{})
+		for restApisPaginator.HasMorePages() {
+			restApisOutput, err := restApisPaginator.NextPage(context.TODO())
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, err
+			}
+			restApis = append(restApis, restApisOutput.Items...)
+		}
+		apis := *list
+		sort.Slice(apis, func(i, j int) bool { return apis[i].Name < apis[j].Name })
+		for _, api := range apis {
+			restApi := getApiGatewayRestApiByName(restApis, api.Name)
+			if restApi == nil {
+				log.Printf("[TRACE] Skipping api gateway (%s), it is not found in aws.", api.Name)
+				continue
+			}
+			shortName := strings.TrimPrefix(api.Name, "duploservices-"+config.TenantName+"-")
+			resourceName := common.GetResourceName(shortName)
+			varFullPrefix := APIGW_VAR_PREFIX + resourceName + "_"
+			apiAddress := AWS_API_GATEWAY_REST_API + "." + resourceName
+
+			stagesOutput, err := apigwClient.GetStages(context.TODO(), &apigateway.GetStagesInput{RestApiId: restApi.Id})
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			stages := stagesOutput.Item
+			sort.Slice(stages, func(i, j int) bool { return *stages[i].StageName < *stages[j].StageName })
+
+			hclFile := hclwrite.NewEmptyFile()
+			path := filepath.Join(workingDir, APIGW_FILE_NAME_PREFIX+shortName+".tf")
+			tfFile, err := os.Create(path)
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			rootBody := hclFile.Body()
+
+			// Add aws_api_gateway_rest_api resource
+			apiBlock := rootBody.AppendNewBlock("resource",
+				[]string{AWS_API_GATEWAY_REST_API,
+					resourceName})
+			apiBody := apiBlock.Body()
+			apiName := common.Interpolate(api.Name)
+			if shortName != api.Name {
+				apiName = common.Template("${local.tenant_prefix}-" + common.EscapeTemplate(shortName))
+			}
+			if err := common.SetAttributeTemplate(apiBody, APIGW_NAME, apiName); err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			if restApi.Description != nil && len(*restApi.Description) > 0 {
+				apiBody.SetAttributeValue(APIGW_DESCRIPTION,
+					cty.StringVal(*restApi.Description))
+			}
+			if len(restApi.ApiKeySource) > 0 {
+				apiBody.SetAttributeValue(APIGW_API_KEY_SOURCE,
+					cty.StringVal(string(restApi.ApiKeySource)))
+			}
+			if len(restApi.BinaryMediaTypes) > 0 {
+				var vals []cty.Value
+				for _, mediaType := range restApi.BinaryMediaTypes {
+					vals = append(vals, cty.StringVal(mediaType))
+				}
+				apiBody.SetAttributeValue(APIGW_BINARY_MEDIA_TYPES,
+					cty.ListVal(vals))
+			}
+			if restApi.MinimumCompressionSize != nil {
+				apiBody.SetAttributeValue(APIGW_MIN_COMPRESSION_SIZE,
+					cty.NumberIntVal(int64(*restApi.MinimumCompressionSize)))
+			}
+			if restApi.DisableExecuteApiEndpoint {
+				apiBody.SetAttributeValue(APIGW_DISABLE_EXECUTE_API,
+					cty.BoolVal(restApi.DisableExecuteApiEndpoint))
+			}
+			if restApi.EndpointConfiguration != nil && len(restApi.EndpointConfiguration.Types) > 0 {
+				endpointBlock := apiBody.AppendNewBlock(APIGW_ENDPOINT_CONFIGURATION,
+					nil)
+				endpointBody := endpointBlock.Body()
+				var vals []cty.Value
+				for _, endpointType := range restApi.EndpointConfiguration.Types {
+					vals = append(vals, cty.StringVal(string(endpointType)))
+				}
+				endpointBody.SetAttributeValue(APIGW_TYPES,
+					cty.ListVal(vals))
+				if len(restApi.EndpointConfiguration.VpcEndpointIds) > 0 {
+					var vpcEndpointVals []cty.Value
+					for _, vpcEndpointId := range restApi.EndpointConfiguration.VpcEndpointIds {
+						vpcEndpointVals = append(vpcEndpointVals, cty.StringVal(vpcEndpointId))
+					}
+					endpointBody.SetAttributeValue(APIGW_VPC_ENDPOINT_IDS,
+						cty.SetVal(vpcEndpointVals))
+				}
+			}
+			if restApi.Policy != nil && len(*restApi.Policy) > 0 {
+				var policyMap interface{}
+				err = json.Unmarshal([]byte(strings.Replace(*restApi.Policy, `\"`, `"`, -1)), &policyMap)
+				if err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				policy := common.InterpolateValues(policyMap,
+					common.Interpolation{Value: config.AccountID, Expr: "local.account_id"})
+				if err := common.SetAttributeJsonencode(apiBody, APIGW_POLICY, policy); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+			}
+
+			// The OpenAPI export is taken from the most recently updated stage, it reflects what is deployed.
+			var exportStage *types.Stage
+			for i := range stages {
+				if exportStage == nil || (stages[i].LastUpdatedDate != nil && exportStage.LastUpdatedDate != nil && stages[i].LastUpdatedDate.After(*exportStage.LastUpdatedDate)) {
+					exportStage = &stages[i]
+				}
+			}
+			if exportStage != nil {
+				exportOutput, err := apigwClient.GetExport(context.TODO(), &apigateway.GetExportInput{
+					RestApiId:  restApi.Id,
+					StageName:  exportStage.StageName,
+					ExportType: aws.String("oas30"),
+					Accepts:    aws.String("application/json"),
+					Parameters: map[string]string{"extensions": "integrations"},
+				})
+				if err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				var bodyMap interface{}
+				err = json.Unmarshal(exportOutput.Body, &bodyMap)
+				if err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				bodyMap = rewriteApiGatewayBody(config, bodyMap, lbList)
+				if err := common.SetAttributeJsonencode(apiBody, APIGW_BODY, bodyMap); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+			} else {
+				log.Printf("[TRACE] Api gateway (%s) has no stages, its body is not exported.", api.Name)
+			}
+			if config.GenerateTfState {
+				importConfigs = append(importConfigs, common.ImportConfig{
+					ResourceAddress: strings.Join([]string{
+						AWS_API_GATEWAY_REST_API,
+						resourceName,
+					}, "."),
+					ResourceId: *restApi.Id,
+					WorkingDir: workingDir,
+				})
+				tfContext.ImportConfigs = importConfigs
+			}
+
+			if exportStage != nil {
+				// Add aws_api_gateway_deployment resource
+				rootBody.AppendNewline()
+				deploymentBlock := rootBody.AppendNewBlock("resource",
+					[]string{AWS_API_GATEWAY_DEPLOYMENT,
+						resourceName})
+				deploymentBody := deploymentBlock.Body()
+				if err := setApiGatewayRestApiIdReference(deploymentBody, apiAddress); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				bodyTokens, err := common.TokensForReference(apiAddress + ".body")
+				if err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				deploymentBody.SetAttributeRaw(APIGW_TRIGGERS, hclwrite.TokensForObject([]hclwrite.ObjectAttrTokens{
+					{
+						Name:  hclwrite.TokensForIdentifier("redeployment"),
+						Value: hclwrite.TokensForFunctionCall("sha1", hclwrite.TokensForFunctionCall("jsonencode", bodyTokens)),
+					},
+				}))
+				if err := common.AppendLifecycleBlock(deploymentBody, common.Lifecycle{
+					CreateBeforeDestroy: true,
+				}); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				if config.GenerateTfState && exportStage.DeploymentId != nil {
+					importConfigs = append(importConfigs, common.ImportConfig{
+						ResourceAddress: strings.Join([]string{
+							AWS_API_GATEWAY_DEPLOYMENT,
+							resourceName,
+						}, "."),
+						ResourceId: *restApi.Id + "/" + *exportStage.DeploymentId,
+						WorkingDir: workingDir,
+					})
+					tfContext.ImportConfigs = importConfigs
+				}
+			}
+
+			for _, stage := range stages {
+				// The generated deployment is built from the exported body, stages serving another deployment would not get what they serve now.
+				if !isApiGatewayStageOfDeployment(stage, exportStage) {
+					log.Printf("[WARN] Skipping api gateway (%s) stage (%s), it is not on the deployment of the exported stage (%s).", api.Name, *stage.StageName, *exportStage.StageName)
+					continue
+				}
+				stageResourceName := resourceName + "_" + common.GetResourceName(*stage.StageName)
+
+				// Add aws_api_gateway_stage resource
+				rootBody.AppendNewline()
+				stageBlock := rootBody.AppendNewBlock("resource",
+					[]string{AWS_API_GATEWAY_STAGE,
+						stageResourceName})
+				stageBody := stageBlock.Body()
+				if err := setApiGatewayRestApiIdReference(stageBody, apiAddress); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				if err := common.SetAttributeReference(stageBody, APIGW_DEPLOYMENT_ID, AWS_API_GATEWAY_DEPLOYMENT+"."+resourceName+".id"); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				stageBody.SetAttributeValue(APIGW_STAGE_NAME,
+					cty.StringVal(*stage.StageName))
+				if stage.Description != nil && len(*stage.Description) > 0 {
+					stageBody.SetAttributeValue(APIGW_DESCRIPTION,
+						cty.StringVal(*stage.Description))
+				}
+				if len(stage.Variables) > 0 {
+					variables := map[string]cty.Value{}
+					for key, value := range stage.Variables {
+						variables[key] = cty.StringVal(value)
+					}
+					stageBody.SetAttributeValue(APIGW_VARIABLES,
+						cty.MapVal(variables))
+				}
+				stageBody.SetAttributeValue(APIGW_XRAY_TRACING_ENABLED,
+					cty.BoolVal(stage.TracingEnabled))
+				if stage.CacheClusterEnabled {
+					stageBody.SetAttributeValue(APIGW_CACHE_CLUSTER_ENABLED,
+						cty.BoolVal(stage.CacheClusterEnabled))
+					stageBody.SetAttributeValue(APIGW_CACHE_CLUSTER_SIZE,
+						cty.StringVal(string(stage.CacheClusterSize)))
+				}
+				if stage.ClientCertificateId != nil && len(*stage.ClientCertificateId) > 0 {
+					stageBody.SetAttributeValue(APIGW_CLIENT_CERTIFICATE_ID,
+						cty.StringVal(*stage.ClientCertificateId))
+				}
+				if stage.AccessLogSettings != nil && stage.AccessLogSettings.DestinationArn != nil {
+					accessLogBlock := stageBody.AppendNewBlock(APIGW_ACCESS_LOG_SETTINGS,
+						nil)
+					accessLogBody := accessLogBlock.Body()
+					accessLogBody.SetAttributeValue(APIGW_DESTINATION_ARN,
+						cty.StringVal(*stage.AccessLogSettings.DestinationArn))
+					if stage.AccessLogSettings.Format != nil {
+						accessLogBody.SetAttributeValue(APIGW_FORMAT,
+							cty.StringVal(*stage.AccessLogSettings.Format))
+					}
+				}
+				if config.GenerateTfState {
+					importConfigs = append(importConfigs, common.ImportConfig{
+						ResourceAddress: strings.Join([]string{
+							AWS_API_GATEWAY_STAGE,
+							stageResourceName,
+						}, "."),
+						ResourceId: *restApi.Id + "/" + *stage.StageName,
+						WorkingDir: workingDir,
+					})
+					tfContext.ImportConfigs = importConfigs
+				}
+			}
+
+			_, err = tfFile.Write(hclFile.Bytes())
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			log.Printf("[TRACE] Terraform config is generated for api gateway : %s", shortName)
+
+			tfContext.OutputVars = append(tfContext.OutputVars, common.OutputVarConfig{
+				Name: varFullPrefix + "id",
+				ActualVal: strings.Join([]string{
+					AWS_API_GATEWAY_REST_API,
+					resourceName,
+					"id",
+				}, "."),
+				DescVal:       "The ID of the REST API.",
+				RootTraversal: true,
+			})
+		}
+		log.Println("[TRACE] <====== API gateway TF generation done. =====>")
+	}
+	return &tfContext, nil
+}
+
+func getApiGatewayRestApiByName(restApis []types.RestApi, name string) *types.RestApi {
+	for i := range restApis {
+		if restApis[i].Name != nil && *restApis[i].Name == name {
+			return &restApis[i]
+		}
+	}
+	return nil
+}
+
+func setApiGatewayRestApiIdReference(body *hclwrite.Body, apiAddress string) error {
+	return common.SetAttributeReference(body, APIGW_REST_API_ID, apiAddress+".id")
+}
+
+// isApiGatewayStageOfDeployment reports whether a stage serves the same deployment as the exported stage.
+func isApiGatewayStageOfDeployment(stage types.Stage, exportStage *types.Stage) bool {
+	if stage.DeploymentId == nil || exportStage.DeploymentId == nil {
+		return stage.StageName != nil && exportStage.StageName != nil && *stage.StageName == *exportStage.StageName
+	}
+	return *stage.DeploymentId == *exportStage.DeploymentId
+}
+
+// rewriteApiGatewayBody points the integration uris of the OpenAPI export at generated load balancers,
+// lambda integration uris are written literally and rewritten by the ReferenceResolver.
+func rewriteApiGatewayBody(config *common.Config, value interface{}, lbList *[]duplosdk.DuploApplicationLB) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if uri, ok := child.(string); ok && key == "uri" {
+				if reference, ok := getApiGatewayIntegrationUriReference(config, uri, lbList); ok {
+					v[key] = reference
+					continue
+				}
+			}
+			v[key] = rewriteApiGatewayBody(config, child, lbList)
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = rewriteApiGatewayBody(config, child, lbList)
+		}
+		return v
+	}
+	return value
+}
+
+func getApiGatewayIntegrationUriReference(config *common.Config, uri string, lbList *[]duplosdk.DuploApplicationLB) (common.Template, bool) {
+	if lbList != nil {
+		lbPrefix := "duplo3-" + config.TenantName + "-"
+		for _, lb := range *lbList {
+			if !strings.HasPrefix(lb.Name, lbPrefix) || len(lb.DNSName) == 0 || !strings.Contains(uri, "://"+lb.DNSName) {
+				continue
+			}
+			lbAddress := AWS_LB + "." + common.GetResourceName(lb.Name[len(lbPrefix):])
+			index := strings.Index(uri, "://"+lb.DNSName) + len("://")
+			return common.Template(common.EscapeTemplate(uri[:index]) + "${" + lbAddress + ".dns_name}" + common.EscapeTemplate(uri[index+len(lb.DNSName):])), true
+		}
+	}
+	return "", false
+}
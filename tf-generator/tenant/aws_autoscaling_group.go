@@ -10,8 +10,6 @@ import (
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
-	"github.com/hashicorp/hcl/v2"
-	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/zclconf/go-cty/cty"
 
@@ -63,7 +61,7 @@ func (awsASG *AwsASG) Generate(config *common.Config, client *duplosdk.Client) (
 
 	if clientErr != nil {
 		fmt.Println(clientErr)
-		return nil, nil
+		return nil, clientErr
 	}
 	tfContext := common.TFContext{}
 	importConfigs := []common.ImportConfig{}
@@ -81,7 +79,7 @@ func (awsASG *AwsASG) Generate(config *common.Config, client *duplosdk.Client) (
 		autoScalingGroupsOutput, err := asgClient.DescribeAutoScalingGroups(context.TODO(), input)
 		if err != nil {
 			fmt.Println(err)
-			return nil, err
+			return &tfContext, err
 		}
 
 		if autoScalingGroupsOutput != nil && len(autoScalingGroupsOutput.AutoScalingGroups) > 0 {
@@ -100,7 +98,7 @@ func (awsASG *AwsASG) Generate(config *common.Config, client *duplosdk.Client) (
 				tfFile, err := os.Create(path)
 				if err != nil {
 					fmt.Println(err)
-					return nil, err
+					return &tfContext, common.NewResourceError(resourceName, err)
 				}
 				rootBody := hclFile.Body()
 
@@ -108,14 +106,21 @@ func (awsASG *AwsASG) Generate(config *common.Config, client *duplosdk.Client) (
 					[]string{AWS_AUTOSCALING_GROUP,
 						resourceName})
 				asgBody := asgBlock.Body()
-				asgBody.SetAttributeTraversal(ASG_NAME, hcl.Traversal{
-					hcl.TraverseRoot{
-						Name: "var",
-					},
-					hcl.TraverseAttr{
-						Name: varFullPrefix + "name",
-					},
+				tfContext.References = append(tfContext.References, common.ResourceReference{
+					Value:      *asgGroup.AutoScalingGroupName,
+					Address:    AWS_AUTOSCALING_GROUP + "." + resourceName + ".name",
+					Attributes: []string{"autoscaling_group_name"},
 				})
+				if asgGroup.AutoScalingGroupARN != nil {
+					tfContext.References = append(tfContext.References, common.ResourceReference{
+						Value:   *asgGroup.AutoScalingGroupARN,
+						Address: AWS_AUTOSCALING_GROUP + "." + resourceName + ".arn",
+					})
+				}
+				if err := common.SetAttributeReference(asgBody, ASG_NAME, "var."+varFullPrefix+"name"); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
 
 				asgBody.SetAttributeValue(MAX_SIZE,
 					cty.NumberIntVal(int64(*asgGroup.MaxSize)))
@@ -170,22 +175,16 @@ func (awsASG *AwsASG) Generate(config *common.Config, client *duplosdk.Client) (
 						tagBody.SetAttributeValue(KEY,
 							cty.StringVal(*tag.Key))
 						if config.TenantName == *tag.Value {
-							tagBody.SetAttributeTraversal(VALUE, hcl.Traversal{
-								hcl.TraverseRoot{
-									Name: "local",
-								},
-								hcl.TraverseAttr{
-									Name: "tenant_name",
-								},
-							})
+							if err := common.SetAttributeReference(tagBody, VALUE, "local.tenant_name"); err != nil {
+								fmt.Println(err)
+								return &tfContext, common.NewResourceError(resourceName, err)
+							}
 						} else {
-							tagValue := strings.Replace(*tag.Value, config.TenantName, "${local.tenant_name}", -1)
-							tagTokens := hclwrite.Tokens{
-								{Type: hclsyntax.TokenOQuote, Bytes: []byte(`"`)},
-								{Type: hclsyntax.TokenIdent, Bytes: []byte(tagValue)},
-								{Type: hclsyntax.TokenCQuote, Bytes: []byte(`"`)},
+							tagValue := common.Interpolate(*tag.Value, common.Interpolation{Value: config.TenantName, Expr: "local.tenant_name"})
+							if err := common.SetAttributeTemplate(tagBody, VALUE, tagValue); err != nil {
+								fmt.Println(err)
+								return &tfContext, common.NewResourceError(resourceName, err)
 							}
-							tagBody.SetAttributeRaw(VALUE, tagTokens)
 						}
 
 						tagBody.SetAttributeValue(PROPAGATE_AT_LAUNCH,
@@ -194,21 +193,17 @@ func (awsASG *AwsASG) Generate(config *common.Config, client *duplosdk.Client) (
 				}
 
 				if asgGroup.LaunchConfigurationName != nil {
-					asgBody.SetAttributeTraversal(LAUNCH_CONFIGURATION, hcl.Traversal{
-						hcl.TraverseRoot{
-							Name: AWS_LAUNCH_CONFIGURATION + "." + resourceName + "_lc",
-						},
-						hcl.TraverseAttr{
-							Name: "name",
-						},
-					})
+					if err := common.SetAttributeReference(asgBody, LAUNCH_CONFIGURATION, AWS_LAUNCH_CONFIGURATION+"."+resourceName+"_lc.name"); err != nil {
+						fmt.Println(err)
+						return &tfContext, common.NewResourceError(resourceName, err)
+					}
 
 					launchConfigurationsOutput, err := asgClient.DescribeLaunchConfigurations(context.TODO(), &autoscaling.DescribeLaunchConfigurationsInput{
 						LaunchConfigurationNames: []string{*asgGroup.LaunchConfigurationName},
 					})
 					if err != nil {
 						fmt.Println(err)
-						return nil, err
+						return &tfContext, common.NewResourceError(resourceName, err)
 					}
 					b, err := json.Marshal(launchConfigurationsOutput)
 					if err != nil {
@@ -223,15 +218,17 @@ func (awsASG *AwsASG) Generate(config *common.Config, client *duplosdk.Client) (
 							[]string{AWS_LAUNCH_CONFIGURATION,
 								resourceName + "_lc"})
 						lcBody := lcBlock.Body()
+						if lc.LaunchConfigurationARN != nil {
+							tfContext.References = append(tfContext.References, common.ResourceReference{
+								Value:   *lc.LaunchConfigurationARN,
+								Address: AWS_LAUNCH_CONFIGURATION + "." + resourceName + "_lc.arn",
+							})
+						}
 
-						lcBody.SetAttributeTraversal(ASG_NAME, hcl.Traversal{
-							hcl.TraverseRoot{
-								Name: "var",
-							},
-							hcl.TraverseAttr{
-								Name: varFullPrefix + "name",
-							},
-						})
+						if err := common.SetAttributeReference(lcBody, ASG_NAME, "var."+varFullPrefix+"name"); err != nil {
+							fmt.Println(err)
+							return &tfContext, common.NewResourceError(resourceName, err)
+						}
 						lcBody.SetAttributeValue(IMAGE_ID,
 							cty.StringVal(*lc.ImageId))
 						lcBody.SetAttributeValue(INSTANCE_TYPE,
@@ -241,34 +238,12 @@ func (awsASG *AwsASG) Generate(config *common.Config, client *duplosdk.Client) (
 								cty.BoolVal(*lc.AssociatePublicIpAddress))
 						}
 						if lc.IamInstanceProfile != nil {
-							if "duploservices-"+config.TenantName == *lc.IamInstanceProfile {
-								lcBody.SetAttributeTraversal(IAM_INSTANCE_PROFILE, hcl.Traversal{
-									hcl.TraverseRoot{
-										Name: AWS_IAM_ROLE + "." + TENANT_IAM,
-									},
-									hcl.TraverseAttr{
-										Name: "name",
-									},
-								})
-							} else {
-								lcBody.SetAttributeValue(IAM_INSTANCE_PROFILE,
-									cty.StringVal(*lc.IamInstanceProfile))
-							}
+							lcBody.SetAttributeValue(IAM_INSTANCE_PROFILE,
+								cty.StringVal(*lc.IamInstanceProfile))
 						}
 						if lc.KeyName != nil {
-							if "duploservices-"+config.TenantName == *lc.KeyName {
-								lcBody.SetAttributeTraversal(KEY_NAME, hcl.Traversal{
-									hcl.TraverseRoot{
-										Name: AWS_KEY_PAIR + ".tenant_keypair",
-									},
-									hcl.TraverseAttr{
-										Name: "key_name",
-									},
-								})
-							} else {
-								lcBody.SetAttributeValue(KEY_NAME,
-									cty.StringVal(*lc.KeyName))
-							}
+							lcBody.SetAttributeValue(KEY_NAME,
+								cty.StringVal(*lc.KeyName))
 						}
 						if lc.EbsOptimized != nil && *lc.EbsOptimized {
 							lcBody.SetAttributeValue(EBS_OPTIMIZED,
@@ -349,15 +324,12 @@ func (awsASG *AwsASG) Generate(config *common.Config, client *duplosdk.Client) (
 							}
 
 						}
-						lifecycleBlock := lcBody.AppendNewBlock("lifecycle", nil)
-						lifecycleBody := lifecycleBlock.Body()
-						ignoreChanges := "user_data, user_data_base64"
-						ignoreChangesTokens := hclwrite.Tokens{
-							{Type: hclsyntax.TokenOQuote, Bytes: []byte(`[`)},
-							{Type: hclsyntax.TokenIdent, Bytes: []byte(ignoreChanges)},
-							{Type: hclsyntax.TokenCQuote, Bytes: []byte(`]`)},
+						if err := common.AppendLifecycleBlock(lcBody, common.Lifecycle{
+							IgnoreChanges: []string{"user_data", "user_data_base64"},
+						}); err != nil {
+							fmt.Println(err)
+							return &tfContext, common.NewResourceError(resourceName, err)
 						}
-						lifecycleBody.SetAttributeRaw("ignore_changes", ignoreChangesTokens)
 						if config.GenerateTfState {
 							importConfigs = append(importConfigs, common.ImportConfig{
 								ResourceAddress: strings.Join([]string{
@@ -372,20 +344,17 @@ func (awsASG *AwsASG) Generate(config *common.Config, client *duplosdk.Client) (
 					}
 				}
 
-				lifecycleBlock := asgBody.AppendNewBlock("lifecycle", nil)
-				lifecycleBody := lifecycleBlock.Body()
-				ignoreChanges := "force_delete,force_delete_warm_pool,wait_for_capacity_timeout"
-				ignoreChangesTokens := hclwrite.Tokens{
-					{Type: hclsyntax.TokenOQuote, Bytes: []byte(`[`)},
-					{Type: hclsyntax.TokenIdent, Bytes: []byte(ignoreChanges)},
-					{Type: hclsyntax.TokenCQuote, Bytes: []byte(`]`)},
+				if err := common.AppendLifecycleBlock(asgBody, common.Lifecycle{
+					IgnoreChanges: []string{"force_delete", "force_delete_warm_pool", "wait_for_capacity_timeout"},
+				}); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
 				}
-				lifecycleBody.SetAttributeRaw("ignore_changes", ignoreChangesTokens)
 
 				_, err = tfFile.Write(hclFile.Bytes())
 				if err != nil {
 					fmt.Println(err)
-					return nil, err
+					return &tfContext, common.NewResourceError(resourceName, err)
 				}
 
 				if config.GenerateTfState {
@@ -416,7 +385,7 @@ func generateASGVars(asg types.AutoScalingGroup, prefix string) []common.VarConf
 	}
 	varConfigs["name"] = imageIdVar
 
-	vars := make([]common.VarConfig, len(varConfigs))
+	vars := make([]common.VarConfig, 0, len(varConfigs))
 	for _, v := range varConfigs {
 		vars = append(vars, v)
 	}
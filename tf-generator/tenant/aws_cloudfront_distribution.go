@@ -0,0 +1,557 @@
+package tenant
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"tenant-native-terraform-generator/duplosdk"
+	"tenant-native-terraform-generator/tf-generator/common"
+)
+
+const (
+	CF_ENABLED                        string = "enabled"
+	CF_IS_IPV6_ENABLED                string = "is_ipv6_enabled"
+	CF_COMMENT                        string = "comment"
+	CF_DEFAULT_ROOT_OBJECT            string = "default_root_object"
+	CF_ALIASES                        string = "aliases"
+	CF_HTTP_VERSION                   string = "http_version"
+	CF_PRICE_CLASS                    string = "price_class"
+	CF_WEB_ACL_ID                     string = "web_acl_id"
+	CF_ORIGIN                         string = "origin"
+	CF_DOMAIN_NAME                    string = "domain_name"
+	CF_ORIGIN_ID                      string = "origin_id"
+	CF_ORIGIN_PATH                    string = "origin_path"
+	CF_CONNECTION_ATTEMPTS            string = "connection_attempts"
+	CF_CONNECTION_TIMEOUT             string = "connection_timeout"
+	CF_CUSTOM_HEADER                  string = "custom_header"
+	CF_NAME                           string = "name"
+	CF_VALUE                          string = "value"
+	CF_S3_ORIGIN_CONFIG               string = "s3_origin_config"
+	CF_ORIGIN_ACCESS_IDENTITY         string = "origin_access_identity"
+	CF_CUSTOM_ORIGIN_CONFIG           string = "custom_origin_config"
+	CF_HTTP_PORT                      string = "http_port"
+	CF_HTTPS_PORT                     string = "https_port"
+	CF_ORIGIN_PROTOCOL_POLICY         string = "origin_protocol_policy"
+	CF_ORIGIN_SSL_PROTOCOLS           string = "origin_ssl_protocols"
+	CF_ORIGIN_KEEPALIVE_TIMEOUT       string = "origin_keepalive_timeout"
+	CF_ORIGIN_READ_TIMEOUT            string = "origin_read_timeout"
+	CF_ORIGIN_SHIELD                  string = "origin_shield"
+	CF_ORIGIN_SHIELD_REGION           string = "origin_shield_region"
+	CF_ORIGIN_GROUP                   string = "origin_group"
+	CF_FAILOVER_CRITERIA              string = "failover_criteria"
+	CF_STATUS_CODES                   string = "status_codes"
+	CF_MEMBER                         string = "member"
+	CF_DEFAULT_CACHE_BEHAVIOR         string = "default_cache_behavior"
+	CF_ORDERED_CACHE_BEHAVIOR         string = "ordered_cache_behavior"
+	CF_PATH_PATTERN                   string = "path_pattern"
+	CF_ALLOWED_METHODS                string = "allowed_methods"
+	CF_CACHED_METHODS                 string = "cached_methods"
+	CF_TARGET_ORIGIN_ID               string = "target_origin_id"
+	CF_VIEWER_PROTOCOL_POLICY         string = "viewer_protocol_policy"
+	CF_COMPRESS                       string = "compress"
+	CF_SMOOTH_STREAMING               string = "smooth_streaming"
+	CF_CACHE_POLICY_ID                string = "cache_policy_id"
+	CF_ORIGIN_REQUEST_POLICY_ID       string = "origin_request_policy_id"
+	CF_FIELD_LEVEL_ENCRYPTION_ID      string = "field_level_encryption_id"
+	CF_TRUSTED_SIGNERS                string = "trusted_signers"
+	CF_MIN_TTL                        string = "min_ttl"
+	CF_DEFAULT_TTL                    string = "default_ttl"
+	CF_MAX_TTL                        string = "max_ttl"
+	CF_FORWARDED_VALUES               string = "forwarded_values"
+	CF_QUERY_STRING                   string = "query_string"
+	CF_QUERY_STRING_CACHE_KEYS        string = "query_string_cache_keys"
+	CF_HEADERS                        string = "headers"
+	CF_COOKIES                        string = "cookies"
+	CF_FORWARD                        string = "forward"
+	CF_WHITELISTED_NAMES              string = "whitelisted_names"
+	CF_LAMBDA_FUNCTION_ASSOCIATION    string = "lambda_function_association"
+	CF_EVENT_TYPE                     string = "event_type"
+	CF_LAMBDA_ARN                     string = "lambda_arn"
+	CF_INCLUDE_BODY                   string = "include_body"
+	CF_RESTRICTIONS                   string = "restrictions"
+	CF_GEO_RESTRICTION                string = "geo_restriction"
+	CF_RESTRICTION_TYPE               string = "restriction_type"
+	CF_LOCATIONS                      string = "locations"
+	CF_VIEWER_CERTIFICATE             string = "viewer_certificate"
+	CF_ACM_CERTIFICATE_ARN            string = "acm_certificate_arn"
+	CF_CLOUDFRONT_DEFAULT_CERTIFICATE string = "cloudfront_default_certificate"
+	CF_IAM_CERTIFICATE_ID             string = "iam_certificate_id"
+	CF_MINIMUM_PROTOCOL_VERSION       string = "minimum_protocol_version"
+	CF_SSL_SUPPORT_METHOD             string = "ssl_support_method"
+	CF_CUSTOM_ERROR_RESPONSE          string = "custom_error_response"
+	CF_ERROR_CODE                     string = "error_code"
+	CF_RESPONSE_CODE                  string = "response_code"
+	CF_RESPONSE_PAGE_PATH             string = "response_page_path"
+	CF_ERROR_CACHING_MIN_TTL          string = "error_caching_min_ttl"
+	CF_LOGGING_CONFIG                 string = "logging_config"
+	CF_BUCKET                         string = "bucket"
+	CF_INCLUDE_COOKIES                string = "include_cookies"
+	CF_PREFIX                         string = "prefix"
+)
+
+const AWS_CLOUDFRONT_DISTRIBUTION = "aws_cloudfront_distribution"
+const CF_VAR_PREFIX = "cloudfront_"
+const CF_FILE_NAME_PREFIX = "aws-cloudfront-"
+const CF_VARS_EXAMPLE_FILE_NAME = "cloudfront.auto.tfvars.example"
+
+type AwsCloudfrontDistribution struct {
+}
+
+func (awsCloudfrontDistribution *AwsCloudfrontDistribution) Generate(config *common.Config, client *duplosdk.Client) (*common.TFContext, error) {
+	workingDir := filepath.Join(config.TFCodePath, config.TenantProject)
+	list, clientErr := client.AwsCloudfrontDistributionList(config.TenantId)
+
+	if clientErr != nil {
+		fmt.Println(clientErr)
+		return nil, clientErr
+	}
+	tfContext := common.TFContext{}
+	importConfigs := []common.ImportConfig{}
+	if list != nil && len(*list) > 0 {
+		log.Println("[TRACE] <====== CloudFront distribution TF generation started. =====>")
+		varsFile := hclwrite.NewEmptyFile()
+		varsBody := varsFile.Body()
+		for _, distribution := range *list {
+			// Distributions have no name, the first alias is the most recognizable handle.
+			// A wildcard alias like *.example.com is used as wildcard.example.com, * is not allowed in names.
+			shortName := strings.ToLower(distribution.Id)
+			if distribution.Aliases != nil && len(distribution.Aliases.Items) > 0 {
+				shortName = strings.Replace(distribution.Aliases.Items[0], "*", "wildcard", -1)
+			}
+			resourceName := common.GetResourceName(shortName)
+			varFullPrefix := CF_VAR_PREFIX + resourceName + "_"
+
+			hclFile := hclwrite.NewEmptyFile()
+			path := filepath.Join(workingDir, CF_FILE_NAME_PREFIX+shortName+".tf")
+			tfFile, err := os.Create(path)
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			rootBody := hclFile.Body()
+
+			// Add aws_cloudfront_distribution resource
+			cfBlock := rootBody.AppendNewBlock("resource",
+				[]string{AWS_CLOUDFRONT_DISTRIBUTION,
+					resourceName})
+			cfBody := cfBlock.Body()
+			cfBody.SetAttributeValue(CF_ENABLED,
+				cty.BoolVal(distribution.Enabled))
+			cfBody.SetAttributeValue(CF_IS_IPV6_ENABLED,
+				cty.BoolVal(distribution.IsIPV6Enabled))
+			if len(distribution.Comment) > 0 {
+				cfBody.SetAttributeValue(CF_COMMENT,
+					cty.StringVal(distribution.Comment))
+			}
+			if len(distribution.DefaultRootObject) > 0 {
+				cfBody.SetAttributeValue(CF_DEFAULT_ROOT_OBJECT,
+					cty.StringVal(distribution.DefaultRootObject))
+			}
+			if distribution.Aliases != nil && len(distribution.Aliases.Items) > 0 {
+				cfBody.SetAttributeValue(CF_ALIASES,
+					getCloudfrontStringSet(distribution.Aliases.Items))
+			}
+			if distribution.HttpVersion != nil && len(distribution.HttpVersion.Value) > 0 {
+				cfBody.SetAttributeValue(CF_HTTP_VERSION,
+					cty.StringVal(distribution.HttpVersion.Value))
+			}
+			if distribution.PriceClass != nil && len(distribution.PriceClass.Value) > 0 {
+				cfBody.SetAttributeValue(CF_PRICE_CLASS,
+					cty.StringVal(distribution.PriceClass.Value))
+			}
+			if len(distribution.WebACLId) > 0 {
+				cfBody.SetAttributeValue(CF_WEB_ACL_ID,
+					cty.StringVal(distribution.WebACLId))
+			}
+
+			if distribution.Origins != nil && distribution.Origins.Items != nil {
+				for _, origin := range *distribution.Origins.Items {
+					originBlock := cfBody.AppendNewBlock(CF_ORIGIN,
+						nil)
+					originBody := originBlock.Body()
+					originBody.SetAttributeValue(CF_DOMAIN_NAME,
+						cty.StringVal(origin.DomainName))
+					originBody.SetAttributeValue(CF_ORIGIN_ID,
+						cty.StringVal(origin.Id))
+					if len(origin.OriginPath) > 0 {
+						originBody.SetAttributeValue(CF_ORIGIN_PATH,
+							cty.StringVal(origin.OriginPath))
+					}
+					if origin.ConnectionAttempts > 0 {
+						originBody.SetAttributeValue(CF_CONNECTION_ATTEMPTS,
+							cty.NumberIntVal(int64(origin.ConnectionAttempts)))
+					}
+					if origin.ConnectionTimeout > 0 {
+						originBody.SetAttributeValue(CF_CONNECTION_TIMEOUT,
+							cty.NumberIntVal(int64(origin.ConnectionTimeout)))
+					}
+					if origin.CustomHeaders != nil && origin.CustomHeaders.Items != nil {
+						for _, header := range *origin.CustomHeaders.Items {
+							headerBlock := originBody.AppendNewBlock(CF_CUSTOM_HEADER,
+								nil)
+							headerBlock.Body().SetAttributeValue(CF_NAME,
+								cty.StringVal(header.HeaderName))
+							headerBlock.Body().SetAttributeValue(CF_VALUE,
+								cty.StringVal(header.HeaderValue))
+						}
+					}
+					if origin.S3OriginConfig != nil {
+						s3ConfigBlock := originBody.AppendNewBlock(CF_S3_ORIGIN_CONFIG,
+							nil)
+						s3ConfigBlock.Body().SetAttributeValue(CF_ORIGIN_ACCESS_IDENTITY,
+							cty.StringVal(origin.S3OriginConfig.OriginAccessIdentity))
+					}
+					if origin.CustomOriginConfig != nil {
+						customConfig := origin.CustomOriginConfig
+						customConfigBlock := originBody.AppendNewBlock(CF_CUSTOM_ORIGIN_CONFIG,
+							nil)
+						customConfigBody := customConfigBlock.Body()
+						customConfigBody.SetAttributeValue(CF_HTTP_PORT,
+							cty.NumberIntVal(int64(customConfig.HTTPPort)))
+						customConfigBody.SetAttributeValue(CF_HTTPS_PORT,
+							cty.NumberIntVal(int64(customConfig.HTTPSPort)))
+						if customConfig.OriginProtocolPolicy != nil {
+							customConfigBody.SetAttributeValue(CF_ORIGIN_PROTOCOL_POLICY,
+								cty.StringVal(customConfig.OriginProtocolPolicy.Value))
+						}
+						if customConfig.OriginSslProtocols != nil && len(customConfig.OriginSslProtocols.Items) > 0 {
+							customConfigBody.SetAttributeValue(CF_ORIGIN_SSL_PROTOCOLS,
+								getCloudfrontStringSet(customConfig.OriginSslProtocols.Items))
+						}
+						if customConfig.OriginKeepaliveTimeout > 0 {
+							customConfigBody.SetAttributeValue(CF_ORIGIN_KEEPALIVE_TIMEOUT,
+								cty.NumberIntVal(int64(customConfig.OriginKeepaliveTimeout)))
+						}
+						if customConfig.OriginReadTimeout > 0 {
+							customConfigBody.SetAttributeValue(CF_ORIGIN_READ_TIMEOUT,
+								cty.NumberIntVal(int64(customConfig.OriginReadTimeout)))
+						}
+					}
+					if origin.OriginShield != nil && origin.OriginShield.Enabled {
+						shieldBlock := originBody.AppendNewBlock(CF_ORIGIN_SHIELD,
+							nil)
+						shieldBlock.Body().SetAttributeValue(CF_ENABLED,
+							cty.BoolVal(origin.OriginShield.Enabled))
+						shieldBlock.Body().SetAttributeValue(CF_ORIGIN_SHIELD_REGION,
+							cty.StringVal(origin.OriginShield.OriginShieldRegion))
+					}
+				}
+			}
+
+			if distribution.OriginGroups != nil && distribution.OriginGroups.Items != nil {
+				for _, originGroup := range *distribution.OriginGroups.Items {
+					groupBlock := cfBody.AppendNewBlock(CF_ORIGIN_GROUP,
+						nil)
+					groupBody := groupBlock.Body()
+					groupBody.SetAttributeValue(CF_ORIGIN_ID,
+						cty.StringVal(originGroup.Id))
+					if originGroup.FailoverCriteria != nil && originGroup.FailoverCriteria.StatusCodes != nil {
+						var vals []cty.Value
+						for _, statusCode := range originGroup.FailoverCriteria.StatusCodes.Items {
+							vals = append(vals, cty.NumberIntVal(int64(statusCode)))
+						}
+						if len(vals) > 0 {
+							failoverBlock := groupBody.AppendNewBlock(CF_FAILOVER_CRITERIA,
+								nil)
+							failoverBlock.Body().SetAttributeValue(CF_STATUS_CODES,
+								cty.SetVal(vals))
+						}
+					}
+					if originGroup.Members != nil && originGroup.Members.Items != nil {
+						for _, member := range *originGroup.Members.Items {
+							memberBlock := groupBody.AppendNewBlock(CF_MEMBER,
+								nil)
+							memberBlock.Body().SetAttributeValue(CF_ORIGIN_ID,
+								cty.StringVal(member.OriginId))
+						}
+					}
+				}
+			}
+
+			if distribution.DefaultCacheBehavior != nil {
+				defaultBehavior := distribution.DefaultCacheBehavior
+				appendCloudfrontCacheBehavior(cfBody, CF_DEFAULT_CACHE_BEHAVIOR, duplosdk.DuploAwsCloudfrontCacheBehavior{
+					AllowedMethods:             defaultBehavior.AllowedMethods,
+					CachePolicyId:              defaultBehavior.CachePolicyId,
+					Compress:                   defaultBehavior.Compress,
+					DefaultTTL:                 defaultBehavior.DefaultTTL,
+					FieldLevelEncryptionId:     defaultBehavior.FieldLevelEncryptionId,
+					OriginRequestPolicyId:      defaultBehavior.OriginRequestPolicyId,
+					LambdaFunctionAssociations: defaultBehavior.LambdaFunctionAssociations,
+					MaxTTL:                     defaultBehavior.MaxTTL,
+					MinTTL:                     defaultBehavior.MinTTL,
+					SmoothStreaming:            defaultBehavior.SmoothStreaming,
+					TargetOriginId:             defaultBehavior.TargetOriginId,
+					TrustedSigners:             defaultBehavior.TrustedSigners,
+					ViewerProtocolPolicy:       defaultBehavior.ViewerProtocolPolicy,
+					ForwardedValues:            defaultBehavior.ForwardedValues,
+				})
+			}
+			if distribution.CacheBehaviors != nil && distribution.CacheBehaviors.Items != nil {
+				for _, behavior := range *distribution.CacheBehaviors.Items {
+					appendCloudfrontCacheBehavior(cfBody, CF_ORDERED_CACHE_BEHAVIOR, behavior)
+				}
+			}
+
+			restrictionsBlock := cfBody.AppendNewBlock(CF_RESTRICTIONS,
+				nil)
+			geoRestrictionBlock := restrictionsBlock.Body().AppendNewBlock(CF_GEO_RESTRICTION,
+				nil)
+			geoRestrictionBody := geoRestrictionBlock.Body()
+			restrictionType := "none"
+			if distribution.Restrictions != nil && distribution.Restrictions.GeoRestriction != nil {
+				geoRestriction := distribution.Restrictions.GeoRestriction
+				if geoRestriction.RestrictionType != nil && len(geoRestriction.RestrictionType.Value) > 0 {
+					restrictionType = geoRestriction.RestrictionType.Value
+				}
+				if len(geoRestriction.Items) > 0 {
+					geoRestrictionBody.SetAttributeValue(CF_LOCATIONS,
+						getCloudfrontStringSet(geoRestriction.Items))
+				}
+			}
+			geoRestrictionBody.SetAttributeValue(CF_RESTRICTION_TYPE,
+				cty.StringVal(restrictionType))
+
+			viewerCertificateBlock := cfBody.AppendNewBlock(CF_VIEWER_CERTIFICATE,
+				nil)
+			viewerCertificateBody := viewerCertificateBlock.Body()
+			if distribution.ViewerCertificate != nil {
+				viewerCertificate := distribution.ViewerCertificate
+				if len(viewerCertificate.ACMCertificateArn) > 0 {
+					// Certificates are issued per domain, a cloned tenant has to supply its own.
+					if err := common.SetAttributeReference(viewerCertificateBody, CF_ACM_CERTIFICATE_ARN, "var."+varFullPrefix+CF_ACM_CERTIFICATE_ARN); err != nil {
+						fmt.Println(err)
+						return &tfContext, common.NewResourceError(resourceName, err)
+					}
+					tfContext.InputVars = append(tfContext.InputVars, common.VarConfig{
+						Name:    varFullPrefix + CF_ACM_CERTIFICATE_ARN,
+						TypeVal: "string",
+						DescVal: "ACM certificate ARN of the CloudFront distribution " + shortName + ".",
+					})
+					varsBody.SetAttributeValue(varFullPrefix+CF_ACM_CERTIFICATE_ARN,
+						cty.StringVal(""))
+				}
+				if len(viewerCertificate.IAMCertificateId) > 0 {
+					viewerCertificateBody.SetAttributeValue(CF_IAM_CERTIFICATE_ID,
+						cty.StringVal(viewerCertificate.IAMCertificateId))
+				}
+				viewerCertificateBody.SetAttributeValue(CF_CLOUDFRONT_DEFAULT_CERTIFICATE,
+					cty.BoolVal(viewerCertificate.CloudFrontDefaultCertificate))
+				if viewerCertificate.MinimumProtocolVersion != nil && len(viewerCertificate.MinimumProtocolVersion.Value) > 0 {
+					viewerCertificateBody.SetAttributeValue(CF_MINIMUM_PROTOCOL_VERSION,
+						cty.StringVal(viewerCertificate.MinimumProtocolVersion.Value))
+				}
+				if !viewerCertificate.CloudFrontDefaultCertificate && viewerCertificate.SSLSupportMethod != nil && len(viewerCertificate.SSLSupportMethod.Value) > 0 {
+					viewerCertificateBody.SetAttributeValue(CF_SSL_SUPPORT_METHOD,
+						cty.StringVal(viewerCertificate.SSLSupportMethod.Value))
+				}
+			} else {
+				viewerCertificateBody.SetAttributeValue(CF_CLOUDFRONT_DEFAULT_CERTIFICATE,
+					cty.BoolVal(true))
+			}
+
+			if distribution.CustomErrorResponses != nil && distribution.CustomErrorResponses.Items != nil {
+				for _, errorResponse := range *distribution.CustomErrorResponses.Items {
+					errorResponseBlock := cfBody.AppendNewBlock(CF_CUSTOM_ERROR_RESPONSE,
+						nil)
+					errorResponseBody := errorResponseBlock.Body()
+					errorResponseBody.SetAttributeValue(CF_ERROR_CODE,
+						cty.NumberIntVal(int64(errorResponse.ErrorCode)))
+					if responseCode, err := strconv.Atoi(errorResponse.ResponseCode); err == nil {
+						errorResponseBody.SetAttributeValue(CF_RESPONSE_CODE,
+							cty.NumberIntVal(int64(responseCode)))
+					}
+					if len(errorResponse.ResponsePagePath) > 0 {
+						errorResponseBody.SetAttributeValue(CF_RESPONSE_PAGE_PATH,
+							cty.StringVal(errorResponse.ResponsePagePath))
+					}
+					errorResponseBody.SetAttributeValue(CF_ERROR_CACHING_MIN_TTL,
+						cty.NumberIntVal(int64(errorResponse.ErrorCachingMinTTL)))
+				}
+			}
+
+			if distribution.Logging != nil && distribution.Logging.Enabled {
+				loggingBlock := cfBody.AppendNewBlock(CF_LOGGING_CONFIG,
+					nil)
+				loggingBody := loggingBlock.Body()
+				loggingBody.SetAttributeValue(CF_BUCKET,
+					cty.StringVal(distribution.Logging.Bucket))
+				loggingBody.SetAttributeValue(CF_INCLUDE_COOKIES,
+					cty.BoolVal(distribution.Logging.IncludeCookies))
+				if len(distribution.Logging.Prefix) > 0 {
+					loggingBody.SetAttributeValue(CF_PREFIX,
+						cty.StringVal(distribution.Logging.Prefix))
+				}
+			}
+
+			if config.GenerateTfState {
+				importConfigs = append(importConfigs, common.ImportConfig{
+					ResourceAddress: strings.Join([]string{
+						AWS_CLOUDFRONT_DISTRIBUTION,
+						resourceName,
+					}, "."),
+					ResourceId: distribution.Id,
+					WorkingDir: workingDir,
+				})
+				tfContext.ImportConfigs = importConfigs
+			}
+
+			_, err = tfFile.Write(hclFile.Bytes())
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			log.Printf("[TRACE] Terraform config is generated for cloudfront distribution : %s", shortName)
+
+			tfContext.OutputVars = append(tfContext.OutputVars, common.OutputVarConfig{
+				Name: varFullPrefix + "domain_name",
+				ActualVal: strings.Join([]string{
+					AWS_CLOUDFRONT_DISTRIBUTION,
+					resourceName,
+					"domain_name",
+				}, "."),
+				DescVal:       "The domain name of the CloudFront distribution.",
+				RootTraversal: true,
+			})
+		}
+
+		// Write the example tfvars listing the certificates to be supplied.
+		if len(varsBody.Attributes()) > 0 {
+			path := filepath.Join(workingDir, CF_VARS_EXAMPLE_FILE_NAME)
+			varsTfFile, err := os.Create(path)
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, err
+			}
+			_, err = varsTfFile.Write(varsFile.Bytes())
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, err
+			}
+		}
+		log.Println("[TRACE] <====== CloudFront distribution TF generation done. =====>")
+	}
+	return &tfContext, nil
+}
+
+func appendCloudfrontCacheBehavior(cfBody *hclwrite.Body, blockName string, behavior duplosdk.DuploAwsCloudfrontCacheBehavior) {
+	behaviorBlock := cfBody.AppendNewBlock(blockName,
+		nil)
+	behaviorBody := behaviorBlock.Body()
+	if len(behavior.PathPattern) > 0 {
+		behaviorBody.SetAttributeValue(CF_PATH_PATTERN,
+			cty.StringVal(behavior.PathPattern))
+	}
+	if behavior.AllowedMethods != nil {
+		behaviorBody.SetAttributeValue(CF_ALLOWED_METHODS,
+			getCloudfrontStringSet(behavior.AllowedMethods.Items))
+		if behavior.AllowedMethods.CachedMethods != nil {
+			behaviorBody.SetAttributeValue(CF_CACHED_METHODS,
+				getCloudfrontStringSet(behavior.AllowedMethods.CachedMethods.Items))
+		}
+	}
+	behaviorBody.SetAttributeValue(CF_TARGET_ORIGIN_ID,
+		cty.StringVal(behavior.TargetOriginId))
+	if behavior.ViewerProtocolPolicy != nil {
+		behaviorBody.SetAttributeValue(CF_VIEWER_PROTOCOL_POLICY,
+			cty.StringVal(behavior.ViewerProtocolPolicy.Value))
+	}
+	behaviorBody.SetAttributeValue(CF_COMPRESS,
+		cty.BoolVal(behavior.Compress))
+	if behavior.SmoothStreaming {
+		behaviorBody.SetAttributeValue(CF_SMOOTH_STREAMING,
+			cty.BoolVal(behavior.SmoothStreaming))
+	}
+	if len(behavior.FieldLevelEncryptionId) > 0 {
+		behaviorBody.SetAttributeValue(CF_FIELD_LEVEL_ENCRYPTION_ID,
+			cty.StringVal(behavior.FieldLevelEncryptionId))
+	}
+	if len(behavior.OriginRequestPolicyId) > 0 {
+		behaviorBody.SetAttributeValue(CF_ORIGIN_REQUEST_POLICY_ID,
+			cty.StringVal(behavior.OriginRequestPolicyId))
+	}
+	if behavior.TrustedSigners != nil && behavior.TrustedSigners.Enabled && len(behavior.TrustedSigners.Items) > 0 {
+		var vals []cty.Value
+		for _, signer := range behavior.TrustedSigners.Items {
+			vals = append(vals, cty.StringVal(signer))
+		}
+		behaviorBody.SetAttributeValue(CF_TRUSTED_SIGNERS,
+			cty.ListVal(vals))
+	}
+	// TTLs and forwarded values are legacy settings, they conflict with a cache policy.
+	if len(behavior.CachePolicyId) > 0 {
+		behaviorBody.SetAttributeValue(CF_CACHE_POLICY_ID,
+			cty.StringVal(behavior.CachePolicyId))
+	} else {
+		behaviorBody.SetAttributeValue(CF_MIN_TTL,
+			cty.NumberIntVal(int64(behavior.MinTTL)))
+		behaviorBody.SetAttributeValue(CF_DEFAULT_TTL,
+			cty.NumberIntVal(int64(behavior.DefaultTTL)))
+		behaviorBody.SetAttributeValue(CF_MAX_TTL,
+			cty.NumberIntVal(int64(behavior.MaxTTL)))
+		if behavior.ForwardedValues != nil {
+			forwardedValues := behavior.ForwardedValues
+			forwardedBlock := behaviorBody.AppendNewBlock(CF_FORWARDED_VALUES,
+				nil)
+			forwardedBody := forwardedBlock.Body()
+			forwardedBody.SetAttributeValue(CF_QUERY_STRING,
+				cty.BoolVal(forwardedValues.QueryString))
+			if forwardedValues.QueryStringCacheKeys != nil && len(forwardedValues.QueryStringCacheKeys.Items) > 0 {
+				var vals []cty.Value
+				for _, key := range forwardedValues.QueryStringCacheKeys.Items {
+					vals = append(vals, cty.StringVal(key))
+				}
+				forwardedBody.SetAttributeValue(CF_QUERY_STRING_CACHE_KEYS,
+					cty.ListVal(vals))
+			}
+			if forwardedValues.Headers != nil && len(forwardedValues.Headers.Items) > 0 {
+				forwardedBody.SetAttributeValue(CF_HEADERS,
+					getCloudfrontStringSet(forwardedValues.Headers.Items))
+			}
+			cookiesBlock := forwardedBody.AppendNewBlock(CF_COOKIES,
+				nil)
+			cookiesBody := cookiesBlock.Body()
+			forward := "none"
+			if forwardedValues.Cookies != nil && len(forwardedValues.Cookies.Forward.Value) > 0 {
+				forward = forwardedValues.Cookies.Forward.Value
+			}
+			cookiesBody.SetAttributeValue(CF_FORWARD,
+				cty.StringVal(forward))
+			if forwardedValues.Cookies != nil && forwardedValues.Cookies.WhitelistedNames != nil && len(forwardedValues.Cookies.WhitelistedNames.Items) > 0 {
+				cookiesBody.SetAttributeValue(CF_WHITELISTED_NAMES,
+					getCloudfrontStringSet(forwardedValues.Cookies.WhitelistedNames.Items))
+			}
+		}
+	}
+	if behavior.LambdaFunctionAssociations != nil && behavior.LambdaFunctionAssociations.Items != nil {
+		for _, association := range *behavior.LambdaFunctionAssociations.Items {
+			associationBlock := behaviorBody.AppendNewBlock(CF_LAMBDA_FUNCTION_ASSOCIATION,
+				nil)
+			associationBody := associationBlock.Body()
+			associationBody.SetAttributeValue(CF_EVENT_TYPE,
+				cty.StringVal(association.EventType))
+			associationBody.SetAttributeValue(CF_LAMBDA_ARN,
+				cty.StringVal(association.LambdaFunctionARN))
+			associationBody.SetAttributeValue(CF_INCLUDE_BODY,
+				cty.BoolVal(association.IncludeBody))
+		}
+	}
+}
+
+func getCloudfrontStringSet(items []string) cty.Value {
+	if len(items) == 0 {
+		return cty.SetValEmpty(cty.String)
+	}
+	var vals []cty.Value
+	for _, item := range items {
+		vals = append(vals, cty.StringVal(item))
+	}
+	return cty.SetVal(vals)
+}
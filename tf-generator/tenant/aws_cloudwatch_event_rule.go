@@ -0,0 +1,171 @@
+package tenant
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"tenant-native-terraform-generator/duplosdk"
+	"tenant-native-terraform-generator/tf-generator/common"
+)
+
+const (
+	EVENT_RULE_NAME                string = "name"
+	EVENT_RULE_DESCRIPTION         string = "description"
+	EVENT_RULE_SCHEDULE_EXPRESSION string = "schedule_expression"
+	EVENT_RULE_EVENT_BUS_NAME      string = "event_bus_name"
+	EVENT_RULE_ROLE_ARN            string = "role_arn"
+	EVENT_RULE_IS_ENABLED          string = "is_enabled"
+	EVENT_TARGET_RULE              string = "rule"
+	EVENT_TARGET_TARGET_ID         string = "target_id"
+	EVENT_TARGET_ARN               string = "arn"
+	EVENT_TARGET_INPUT             string = "input"
+)
+
+const AWS_CLOUDWATCH_EVENT_RULE = "aws_cloudwatch_event_rule"
+const AWS_CLOUDWATCH_EVENT_TARGET = "aws_cloudwatch_event_target"
+const EVENT_RULE_FILE_NAME_PREFIX = "aws-cloudwatch-event-"
+const EVENT_RULE_DEFAULT_BUS = "default"
+
+type AwsCloudwatchEventRule struct {
+}
+
+func (awsCloudwatchEventRule *AwsCloudwatchEventRule) Generate(config *common.Config, client *duplosdk.Client) (*common.TFContext, error) {
+	workingDir := filepath.Join(config.TFCodePath, config.TenantProject)
+	list, clientErr := client.DuploCloudWatchEventRuleList(config.TenantId)
+
+	if clientErr != nil {
+		fmt.Println(clientErr)
+		return nil, clientErr
+	}
+	tfContext := common.TFContext{}
+	importConfigs := []common.ImportConfig{}
+	if list != nil && len(*list) > 0 {
+		log.Println("[TRACE] <====== CloudWatch event rule TF generation started. =====>")
+		for _, rule := range *list {
+			shortName := strings.TrimPrefix(rule.Name, "duploservices-"+config.TenantName+"-")
+			resourceName := common.GetResourceName(shortName)
+			importIdPrefix := ""
+			if len(rule.EventBusName) > 0 && rule.EventBusName != EVENT_RULE_DEFAULT_BUS {
+				importIdPrefix = rule.EventBusName + "/"
+			}
+
+			hclFile := hclwrite.NewEmptyFile()
+			path := filepath.Join(workingDir, EVENT_RULE_FILE_NAME_PREFIX+shortName+".tf")
+			tfFile, err := os.Create(path)
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			rootBody := hclFile.Body()
+
+			// Add aws_cloudwatch_event_rule resource
+			ruleBlock := rootBody.AppendNewBlock("resource",
+				[]string{AWS_CLOUDWATCH_EVENT_RULE,
+					resourceName})
+			ruleBody := ruleBlock.Body()
+			ruleName := common.Interpolate(rule.Name)
+			if shortName != rule.Name {
+				ruleName = common.Template("${local.tenant_prefix}-" + common.EscapeTemplate(shortName))
+			}
+			if err := common.SetAttributeTemplate(ruleBody, EVENT_RULE_NAME, ruleName); err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			if len(rule.Description) > 0 {
+				ruleBody.SetAttributeValue(EVENT_RULE_DESCRIPTION,
+					cty.StringVal(rule.Description))
+			}
+			if len(rule.ScheduleExpression) > 0 {
+				ruleBody.SetAttributeValue(EVENT_RULE_SCHEDULE_EXPRESSION,
+					cty.StringVal(rule.ScheduleExpression))
+			}
+			if len(importIdPrefix) > 0 {
+				ruleBody.SetAttributeValue(EVENT_RULE_EVENT_BUS_NAME,
+					cty.StringVal(rule.EventBusName))
+			}
+			if len(rule.RoleArn) > 0 {
+				ruleBody.SetAttributeValue(EVENT_RULE_ROLE_ARN,
+					cty.StringVal(rule.RoleArn))
+			}
+			if rule.State != nil && len(rule.State.Value) > 0 {
+				ruleBody.SetAttributeValue(EVENT_RULE_IS_ENABLED,
+					cty.BoolVal(rule.State.Value == "ENABLED"))
+			}
+			if config.GenerateTfState {
+				importConfigs = append(importConfigs, common.ImportConfig{
+					ResourceAddress: strings.Join([]string{
+						AWS_CLOUDWATCH_EVENT_RULE,
+						resourceName,
+					}, "."),
+					ResourceId: importIdPrefix + rule.Name,
+					WorkingDir: workingDir,
+				})
+				tfContext.ImportConfigs = importConfigs
+			}
+
+			targets, clientErr := client.DuploCloudWatchEventTargetsList(config.TenantId, rule.Name)
+			if clientErr != nil {
+				fmt.Println(clientErr)
+				return &tfContext, common.NewResourceError(resourceName, clientErr)
+			}
+			if targets != nil {
+				for _, target := range *targets {
+					targetResourceName := resourceName + "_" + common.GetResourceName(target.Id)
+
+					// Add aws_cloudwatch_event_target resource
+					rootBody.AppendNewline()
+					targetBlock := rootBody.AppendNewBlock("resource",
+						[]string{AWS_CLOUDWATCH_EVENT_TARGET,
+							targetResourceName})
+					targetBody := targetBlock.Body()
+					if err := common.SetAttributeReference(targetBody, EVENT_TARGET_RULE, AWS_CLOUDWATCH_EVENT_RULE+"."+resourceName+".name"); err != nil {
+						fmt.Println(err)
+						return &tfContext, common.NewResourceError(resourceName, err)
+					}
+					if len(importIdPrefix) > 0 {
+						targetBody.SetAttributeValue(EVENT_RULE_EVENT_BUS_NAME,
+							cty.StringVal(rule.EventBusName))
+					}
+					targetBody.SetAttributeValue(EVENT_TARGET_TARGET_ID,
+						cty.StringVal(target.Id))
+					targetBody.SetAttributeValue(EVENT_TARGET_ARN,
+						cty.StringVal(target.Arn))
+					if len(target.RoleArn) > 0 {
+						targetBody.SetAttributeValue(EVENT_RULE_ROLE_ARN,
+							cty.StringVal(target.RoleArn))
+					}
+					if len(target.Input) > 0 {
+						targetBody.SetAttributeValue(EVENT_TARGET_INPUT,
+							cty.StringVal(target.Input))
+					}
+					if config.GenerateTfState {
+						importConfigs = append(importConfigs, common.ImportConfig{
+							ResourceAddress: strings.Join([]string{
+								AWS_CLOUDWATCH_EVENT_TARGET,
+								targetResourceName,
+							}, "."),
+							ResourceId: importIdPrefix + rule.Name + "/" + target.Id,
+							WorkingDir: workingDir,
+						})
+						tfContext.ImportConfigs = importConfigs
+					}
+				}
+			}
+
+			_, err = tfFile.Write(hclFile.Bytes())
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			log.Printf("[TRACE] Terraform config is generated for cloudwatch event rule : %s", shortName)
+		}
+		log.Println("[TRACE] <====== CloudWatch event rule TF generation done. =====>")
+	}
+	return &tfContext, nil
+}
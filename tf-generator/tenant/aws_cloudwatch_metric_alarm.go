@@ -0,0 +1,118 @@
+package tenant
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"tenant-native-terraform-generator/duplosdk"
+	"tenant-native-terraform-generator/tf-generator/common"
+)
+
+const (
+	ALARM_NAME                string = "alarm_name"
+	ALARM_COMPARISON_OPERATOR string = "comparison_operator"
+	ALARM_EVALUATION_PERIODS  string = "evaluation_periods"
+	ALARM_METRIC_NAME         string = "metric_name"
+	ALARM_NAMESPACE           string = "namespace"
+	ALARM_PERIOD              string = "period"
+	ALARM_STATISTIC           string = "statistic"
+	ALARM_THRESHOLD           string = "threshold"
+	ALARM_DIMENSIONS          string = "dimensions"
+)
+
+const AWS_CLOUDWATCH_METRIC_ALARM = "aws_cloudwatch_metric_alarm"
+const ALARM_FILE_NAME_PREFIX = "aws-cloudwatch-alarm-"
+
+type AwsCloudwatchMetricAlarm struct {
+}
+
+func (awsCloudwatchMetricAlarm *AwsCloudwatchMetricAlarm) Generate(config *common.Config, client *duplosdk.Client) (*common.TFContext, error) {
+	workingDir := filepath.Join(config.TFCodePath, config.TenantProject)
+	list, clientErr := client.DuploCloudWatchMetricAlarmList(config.TenantId)
+
+	if clientErr != nil {
+		fmt.Println(clientErr)
+		return nil, clientErr
+	}
+	tfContext := common.TFContext{}
+	importConfigs := []common.ImportConfig{}
+	if list != nil && len(*list) > 0 {
+		log.Println("[TRACE] <====== CloudWatch metric alarm TF generation started. =====>")
+		for _, alarm := range *list {
+			shortName := strings.TrimPrefix(alarm.Name, "duploservices-"+config.TenantName+"-")
+			resourceName := common.GetResourceName(shortName)
+
+			hclFile := hclwrite.NewEmptyFile()
+			path := filepath.Join(workingDir, ALARM_FILE_NAME_PREFIX+shortName+".tf")
+			tfFile, err := os.Create(path)
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			rootBody := hclFile.Body()
+
+			// Add aws_cloudwatch_metric_alarm resource
+			alarmBlock := rootBody.AppendNewBlock("resource",
+				[]string{AWS_CLOUDWATCH_METRIC_ALARM,
+					resourceName})
+			alarmBody := alarmBlock.Body()
+			alarmName := common.Interpolate(alarm.Name)
+			if shortName != alarm.Name {
+				alarmName = common.Template("${local.tenant_prefix}-" + common.EscapeTemplate(shortName))
+			}
+			if err := common.SetAttributeTemplate(alarmBody, ALARM_NAME, alarmName); err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			alarmBody.SetAttributeValue(ALARM_COMPARISON_OPERATOR,
+				cty.StringVal(alarm.ComparisonOperator))
+			alarmBody.SetAttributeValue(ALARM_EVALUATION_PERIODS,
+				cty.NumberIntVal(int64(alarm.EvaluationPeriods)))
+			alarmBody.SetAttributeValue(ALARM_METRIC_NAME,
+				cty.StringVal(alarm.MetricName))
+			alarmBody.SetAttributeValue(ALARM_NAMESPACE,
+				cty.StringVal(alarm.Namespace))
+			alarmBody.SetAttributeValue(ALARM_PERIOD,
+				cty.NumberIntVal(int64(alarm.Period)))
+			alarmBody.SetAttributeValue(ALARM_STATISTIC,
+				cty.StringVal(alarm.Statistic))
+			alarmBody.SetAttributeValue(ALARM_THRESHOLD,
+				cty.NumberFloatVal(alarm.Threshold))
+			if alarm.Dimensions != nil && len(*alarm.Dimensions) > 0 {
+				dimensions := map[string]cty.Value{}
+				for _, dimension := range *alarm.Dimensions {
+					dimensions[dimension.Name] = cty.StringVal(dimension.Value)
+				}
+				alarmBody.SetAttributeValue(ALARM_DIMENSIONS,
+					cty.MapVal(dimensions))
+			}
+
+			if config.GenerateTfState {
+				importConfigs = append(importConfigs, common.ImportConfig{
+					ResourceAddress: strings.Join([]string{
+						AWS_CLOUDWATCH_METRIC_ALARM,
+						resourceName,
+					}, "."),
+					ResourceId: alarm.Name,
+					WorkingDir: workingDir,
+				})
+				tfContext.ImportConfigs = importConfigs
+			}
+
+			_, err = tfFile.Write(hclFile.Bytes())
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			log.Printf("[TRACE] Terraform config is generated for cloudwatch metric alarm : %s", shortName)
+		}
+		log.Println("[TRACE] <====== CloudWatch metric alarm TF generation done. =====>")
+	}
+	return &tfContext, nil
+}
@@ -0,0 +1,307 @@
+package tenant
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"tenant-native-terraform-generator/duplosdk"
+	"tenant-native-terraform-generator/tf-generator/common"
+)
+
+const (
+	DYNAMODB_NAME                   string = "name"
+	DYNAMODB_BILLING_MODE           string = "billing_mode"
+	DYNAMODB_READ_CAPACITY          string = "read_capacity"
+	DYNAMODB_WRITE_CAPACITY         string = "write_capacity"
+	DYNAMODB_HASH_KEY               string = "hash_key"
+	DYNAMODB_RANGE_KEY              string = "range_key"
+	DYNAMODB_ATTRIBUTE              string = "attribute"
+	DYNAMODB_TYPE                   string = "type"
+	DYNAMODB_GLOBAL_SECONDARY_INDEX string = "global_secondary_index"
+	DYNAMODB_LOCAL_SECONDARY_INDEX  string = "local_secondary_index"
+	DYNAMODB_PROJECTION_TYPE        string = "projection_type"
+	DYNAMODB_NON_KEY_ATTRIBUTES     string = "non_key_attributes"
+	DYNAMODB_STREAM_ENABLED         string = "stream_enabled"
+	DYNAMODB_STREAM_VIEW_TYPE       string = "stream_view_type"
+	DYNAMODB_SERVER_SIDE_ENCRYPTION string = "server_side_encryption"
+	DYNAMODB_ENABLED                string = "enabled"
+	DYNAMODB_KMS_KEY_ARN            string = "kms_key_arn"
+)
+
+const AWS_DYNAMODB_TABLE = "aws_dynamodb_table"
+const DYNAMODB_VAR_PREFIX = "dynamodb_"
+const DYNAMODB_FILE_NAME_PREFIX = "aws-dynamodb-"
+
+type AwsDynamoDBTable struct {
+}
+
+func (awsDynamoDBTable *AwsDynamoDBTable) Generate(config *common.Config, client *duplosdk.Client) (*common.TFContext, error) {
+	workingDir := filepath.Join(config.TFCodePath, config.TenantProject)
+	list, clientErr := client.TenantDynamoDBList(config.TenantId)
+
+	if clientErr != nil {
+		fmt.Println(clientErr)
+		return nil, clientErr
+	}
+	tfContext := common.TFContext{}
+	importConfigs := []common.ImportConfig{}
+	if list != nil && len(*list) > 0 {
+		log.Println("[TRACE] <====== DynamoDB table TF generation started. =====>")
+		for _, resource := range *list {
+			table, clientErr := client.DynamoDBTableGetV2(config.TenantId, resource.Name)
+			if clientErr != nil {
+				fmt.Println(clientErr)
+				return &tfContext, clientErr
+			}
+			if table == nil || len(table.TableName) == 0 {
+				continue
+			}
+			shortName := strings.TrimPrefix(table.TableName, "duploservices-"+config.TenantName+"-")
+			resourceName := common.GetResourceName(shortName)
+			varFullPrefix := DYNAMODB_VAR_PREFIX + resourceName + "_"
+
+			hclFile := hclwrite.NewEmptyFile()
+			path := filepath.Join(workingDir, DYNAMODB_FILE_NAME_PREFIX+shortName+".tf")
+			tfFile, err := os.Create(path)
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			rootBody := hclFile.Body()
+
+			// Add aws_dynamodb_table resource
+			tableBlock := rootBody.AppendNewBlock("resource",
+				[]string{AWS_DYNAMODB_TABLE,
+					resourceName})
+			tableBody := tableBlock.Body()
+			if len(table.TableArn) > 0 {
+				tfContext.References = append(tfContext.References, common.ResourceReference{
+					Value:   table.TableArn,
+					Address: AWS_DYNAMODB_TABLE + "." + resourceName + ".arn",
+				})
+			}
+			if len(table.LatestStreamArn) > 0 {
+				tfContext.References = append(tfContext.References, common.ResourceReference{
+					Value:   table.LatestStreamArn,
+					Address: AWS_DYNAMODB_TABLE + "." + resourceName + ".stream_arn",
+				})
+			}
+			name := common.Interpolate(table.TableName)
+			if strings.HasPrefix(table.TableName, "duploservices-"+config.TenantName+"-") {
+				name = common.Template("${local.tenant_prefix}-" + common.EscapeTemplate(shortName))
+			}
+			if err := common.SetAttributeTemplate(tableBody, DYNAMODB_NAME, name); err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+
+			billingMode := duplosdk.DynamoDBBillingModeProvisioned
+			if table.BillingModeSummary != nil && table.BillingModeSummary.BillingMode != nil && len(table.BillingModeSummary.BillingMode.Value) > 0 {
+				billingMode = table.BillingModeSummary.BillingMode.Value
+			}
+			tableBody.SetAttributeValue(DYNAMODB_BILLING_MODE,
+				cty.StringVal(billingMode))
+			if billingMode == duplosdk.DynamoDBBillingModeProvisioned {
+				if err := common.SetAttributeReference(tableBody, DYNAMODB_READ_CAPACITY, "var."+varFullPrefix+"read_capacity"); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				if err := common.SetAttributeReference(tableBody, DYNAMODB_WRITE_CAPACITY, "var."+varFullPrefix+"write_capacity"); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				tfContext.InputVars = append(tfContext.InputVars, generateDynamoDBVars(table, varFullPrefix)...)
+			}
+
+			hashKey, rangeKey := getDynamoDBKeys(table.KeySchema)
+			if len(hashKey) > 0 {
+				tableBody.SetAttributeValue(DYNAMODB_HASH_KEY,
+					cty.StringVal(hashKey))
+			}
+			if len(rangeKey) > 0 {
+				tableBody.SetAttributeValue(DYNAMODB_RANGE_KEY,
+					cty.StringVal(rangeKey))
+			}
+
+			if table.AttributeDefinitions != nil {
+				for _, attribute := range *table.AttributeDefinitions {
+					attributeBlock := tableBody.AppendNewBlock(DYNAMODB_ATTRIBUTE,
+						nil)
+					attributeBody := attributeBlock.Body()
+					attributeBody.SetAttributeValue(DYNAMODB_NAME,
+						cty.StringVal(attribute.AttributeName))
+					if attribute.AttributeType != nil {
+						attributeBody.SetAttributeValue(DYNAMODB_TYPE,
+							cty.StringVal(attribute.AttributeType.Value))
+					}
+				}
+			}
+
+			if table.GlobalSecondaryIndexes != nil {
+				for _, gsi := range *table.GlobalSecondaryIndexes {
+					gsiBlock := tableBody.AppendNewBlock(DYNAMODB_GLOBAL_SECONDARY_INDEX,
+						nil)
+					gsiBody := gsiBlock.Body()
+					gsiBody.SetAttributeValue(DYNAMODB_NAME,
+						cty.StringVal(gsi.IndexName))
+					gsiHashKey, gsiRangeKey := getDynamoDBKeys(gsi.KeySchema)
+					if len(gsiHashKey) > 0 {
+						gsiBody.SetAttributeValue(DYNAMODB_HASH_KEY,
+							cty.StringVal(gsiHashKey))
+					}
+					if len(gsiRangeKey) > 0 {
+						gsiBody.SetAttributeValue(DYNAMODB_RANGE_KEY,
+							cty.StringVal(gsiRangeKey))
+					}
+					setDynamoDBProjection(gsiBody, gsi.Projection)
+					if billingMode == duplosdk.DynamoDBBillingModeProvisioned && gsi.ProvisionedThroughput != nil {
+						gsiBody.SetAttributeValue(DYNAMODB_READ_CAPACITY,
+							cty.NumberIntVal(int64(gsi.ProvisionedThroughput.ReadCapacityUnits)))
+						gsiBody.SetAttributeValue(DYNAMODB_WRITE_CAPACITY,
+							cty.NumberIntVal(int64(gsi.ProvisionedThroughput.WriteCapacityUnits)))
+					}
+				}
+			}
+
+			if table.LocalSecondaryIndexes != nil {
+				for _, lsi := range *table.LocalSecondaryIndexes {
+					lsiBlock := tableBody.AppendNewBlock(DYNAMODB_LOCAL_SECONDARY_INDEX,
+						nil)
+					lsiBody := lsiBlock.Body()
+					lsiBody.SetAttributeValue(DYNAMODB_NAME,
+						cty.StringVal(lsi.IndexName))
+					_, lsiRangeKey := getDynamoDBKeys(lsi.KeySchema)
+					if len(lsiRangeKey) > 0 {
+						lsiBody.SetAttributeValue(DYNAMODB_RANGE_KEY,
+							cty.StringVal(lsiRangeKey))
+					}
+					setDynamoDBProjection(lsiBody, lsi.Projection)
+				}
+			}
+
+			if table.StreamSpecification != nil && table.StreamSpecification.StreamEnabled {
+				tableBody.SetAttributeValue(DYNAMODB_STREAM_ENABLED,
+					cty.BoolVal(true))
+				if table.StreamSpecification.StreamViewType != nil && len(table.StreamSpecification.StreamViewType.Value) > 0 {
+					tableBody.SetAttributeValue(DYNAMODB_STREAM_VIEW_TYPE,
+						cty.StringVal(table.StreamSpecification.StreamViewType.Value))
+				}
+			}
+
+			// SSEDescription is only present when the table is encrypted with a KMS key.
+			if table.SSEDescription != nil && (table.SSEDescription.Enabled || len(table.SSEDescription.KMSMasterKeyArn) > 0) {
+				sseBlock := tableBody.AppendNewBlock(DYNAMODB_SERVER_SIDE_ENCRYPTION,
+					nil)
+				sseBody := sseBlock.Body()
+				sseBody.SetAttributeValue(DYNAMODB_ENABLED,
+					cty.BoolVal(true))
+				if len(table.SSEDescription.KMSMasterKeyArn) > 0 {
+					sseBody.SetAttributeValue(DYNAMODB_KMS_KEY_ARN,
+						cty.StringVal(table.SSEDescription.KMSMasterKeyArn))
+				}
+			}
+
+			if config.GenerateTfState {
+				importConfigs = append(importConfigs, common.ImportConfig{
+					ResourceAddress: strings.Join([]string{
+						AWS_DYNAMODB_TABLE,
+						resourceName,
+					}, "."),
+					ResourceId: table.TableName,
+					WorkingDir: workingDir,
+				})
+				tfContext.ImportConfigs = importConfigs
+			}
+
+			_, err = tfFile.Write(hclFile.Bytes())
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			log.Printf("[TRACE] Terraform config is generated for dynamodb table : %s", shortName)
+
+			tfContext.OutputVars = append(tfContext.OutputVars, common.OutputVarConfig{
+				Name: varFullPrefix + "arn",
+				ActualVal: strings.Join([]string{
+					AWS_DYNAMODB_TABLE,
+					resourceName,
+					"arn",
+				}, "."),
+				DescVal:       "The ARN of the DynamoDB table.",
+				RootTraversal: true,
+			})
+		}
+		log.Println("[TRACE] <====== DynamoDB table TF generation done. =====>")
+	}
+	return &tfContext, nil
+}
+
+// getDynamoDBKeys returns the hash and range attribute names of a key schema.
+func getDynamoDBKeys(keySchema *[]duplosdk.DuploDynamoDBKeySchema) (string, string) {
+	hashKey, rangeKey := "", ""
+	if keySchema != nil {
+		for _, key := range *keySchema {
+			if key.KeyType == nil {
+				continue
+			}
+			switch key.KeyType.Value {
+			case duplosdk.DynamoDBKeyTypeHash:
+				hashKey = key.AttributeName
+			case duplosdk.DynamoDBKeyTypeRange:
+				rangeKey = key.AttributeName
+			}
+		}
+	}
+	return hashKey, rangeKey
+}
+
+func setDynamoDBProjection(body *hclwrite.Body, projection *duplosdk.DuploDynamoDBTableV2Projection) {
+	if projection == nil {
+		return
+	}
+	if projection.ProjectionType != nil && len(projection.ProjectionType.Value) > 0 {
+		body.SetAttributeValue(DYNAMODB_PROJECTION_TYPE,
+			cty.StringVal(projection.ProjectionType.Value))
+	}
+	if len(projection.NonKeyAttributes) > 0 {
+		var vals []cty.Value
+		for _, s := range projection.NonKeyAttributes {
+			vals = append(vals, cty.StringVal(s))
+		}
+		body.SetAttributeValue(DYNAMODB_NON_KEY_ATTRIBUTES,
+			cty.ListVal(vals))
+	}
+}
+
+func generateDynamoDBVars(table *duplosdk.DuploDynamoDBTableV2, prefix string) []common.VarConfig {
+	readCapacity, writeCapacity := duplosdk.DynamoDBProvisionedThroughputMinValue, duplosdk.DynamoDBProvisionedThroughputMinValue
+	if table.ProvisionedThroughput != nil {
+		if table.ProvisionedThroughput.ReadCapacityUnits > 0 {
+			readCapacity = table.ProvisionedThroughput.ReadCapacityUnits
+		}
+		if table.ProvisionedThroughput.WriteCapacityUnits > 0 {
+			writeCapacity = table.ProvisionedThroughput.WriteCapacityUnits
+		}
+	}
+	return []common.VarConfig{
+		{
+			Name:       prefix + "read_capacity",
+			DefaultVal: strconv.Itoa(readCapacity),
+			TypeVal:    "number",
+			DescVal:    "Provisioned read capacity units of the DynamoDB table " + table.TableName + ".",
+		},
+		{
+			Name:       prefix + "write_capacity",
+			DefaultVal: strconv.Itoa(writeCapacity),
+			TypeVal:    "number",
+			DescVal:    "Provisioned write capacity units of the DynamoDB table " + table.TableName + ".",
+		},
+	}
+}
@@ -0,0 +1,188 @@
+package tenant
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"tenant-native-terraform-generator/duplosdk"
+	"tenant-native-terraform-generator/tf-generator/common"
+)
+
+const (
+	ECR_NAME                         string = "name"
+	ECR_IMAGE_TAG_MUTABILITY         string = "image_tag_mutability"
+	ECR_IMAGE_SCANNING_CONFIGURATION string = "image_scanning_configuration"
+	ECR_SCAN_ON_PUSH                 string = "scan_on_push"
+	ECR_ENCRYPTION_CONFIGURATION     string = "encryption_configuration"
+	ECR_ENCRYPTION_TYPE              string = "encryption_type"
+	ECR_KMS_KEY                      string = "kms_key"
+	ECR_REPOSITORY                   string = "repository"
+	ECR_POLICY                       string = "policy"
+)
+
+const AWS_ECR_REPOSITORY = "aws_ecr_repository"
+const AWS_ECR_LIFECYCLE_POLICY = "aws_ecr_lifecycle_policy"
+const ECR_FILE_NAME_PREFIX = "aws-ecr-"
+
+type AwsEcrRepository struct {
+}
+
+func (awsEcrRepository *AwsEcrRepository) Generate(config *common.Config, client *duplosdk.Client) (*common.TFContext, error) {
+	workingDir := filepath.Join(config.TFCodePath, config.TenantProject)
+	list, clientErr := client.AwsEcrRepositoryList(config.TenantId)
+
+	if clientErr != nil {
+		fmt.Println(clientErr)
+		return nil, clientErr
+	}
+	tfContext := common.TFContext{}
+	importConfigs := []common.ImportConfig{}
+	if list != nil && len(*list) > 0 {
+		log.Println("[TRACE] <====== ECR repository TF generation started. =====>")
+		tenantKms, clientErr := client.TenantGetTenantKmsKey(config.TenantId)
+		if clientErr != nil {
+			fmt.Println(clientErr)
+			return &tfContext, clientErr
+		}
+		ecrClient := ecr.NewFromConfig(config.AwsClientConfig)
+		for _, repo := range *list {
+			shortName := repo.Name
+			resourceName := common.GetResourceName(shortName)
+
+			hclFile := hclwrite.NewEmptyFile()
+			path := filepath.Join(workingDir, ECR_FILE_NAME_PREFIX+shortName+".tf")
+			tfFile, err := os.Create(path)
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			rootBody := hclFile.Body()
+
+			// Repositories shared across tenants are only looked up, they are managed elsewhere.
+			if common.Contains(config.EcrDataSourceRepos, repo.Name) {
+				dataBlock := rootBody.AppendNewBlock("data",
+					[]string{AWS_ECR_REPOSITORY,
+						resourceName})
+				dataBlock.Body().SetAttributeValue(ECR_NAME,
+					cty.StringVal(repo.Name))
+				if len(repo.Arn) > 0 {
+					tfContext.References = append(tfContext.References, common.ResourceReference{
+						Value:   repo.Arn,
+						Address: "data." + AWS_ECR_REPOSITORY + "." + resourceName + ".arn",
+					})
+				}
+			} else {
+				// Add aws_ecr_repository resource
+				ecrBlock := rootBody.AppendNewBlock("resource",
+					[]string{AWS_ECR_REPOSITORY,
+						resourceName})
+				ecrBody := ecrBlock.Body()
+				if len(repo.Arn) > 0 {
+					tfContext.References = append(tfContext.References, common.ResourceReference{
+						Value:   repo.Arn,
+						Address: AWS_ECR_REPOSITORY + "." + resourceName + ".arn",
+					})
+				}
+				ecrBody.SetAttributeValue(ECR_NAME,
+					cty.StringVal(repo.Name))
+				tagMutability := "MUTABLE"
+				if repo.EnableTagImmutability {
+					tagMutability = "IMMUTABLE"
+				}
+				ecrBody.SetAttributeValue(ECR_IMAGE_TAG_MUTABILITY,
+					cty.StringVal(tagMutability))
+				scanningBlock := ecrBody.AppendNewBlock(ECR_IMAGE_SCANNING_CONFIGURATION,
+					nil)
+				scanningBlock.Body().SetAttributeValue(ECR_SCAN_ON_PUSH,
+					cty.BoolVal(repo.EnableScanImageOnPush))
+				if len(repo.KmsEncryption) > 0 {
+					encryptionBlock := ecrBody.AppendNewBlock(ECR_ENCRYPTION_CONFIGURATION,
+						nil)
+					encryptionBody := encryptionBlock.Body()
+					encryptionBody.SetAttributeValue(ECR_ENCRYPTION_TYPE,
+						cty.StringVal("KMS"))
+					if isTenantKmsKey(tenantKms, repo.KmsEncryption) {
+						if err := common.SetAttributeReference(encryptionBody, ECR_KMS_KEY, AWS_KMS_KEY+"."+TENANT_KMS+".arn"); err != nil {
+							fmt.Println(err)
+							return &tfContext, common.NewResourceError(resourceName, err)
+						}
+					} else {
+						encryptionBody.SetAttributeValue(ECR_KMS_KEY,
+							cty.StringVal(repo.KmsEncryption))
+					}
+				}
+				if config.GenerateTfState {
+					importConfigs = append(importConfigs, common.ImportConfig{
+						ResourceAddress: strings.Join([]string{
+							AWS_ECR_REPOSITORY,
+							resourceName,
+						}, "."),
+						ResourceId: repo.Name,
+						WorkingDir: workingDir,
+					})
+					tfContext.ImportConfigs = importConfigs
+				}
+
+				// Add aws_ecr_lifecycle_policy resource
+				lifecyclePolicyOutput, err := ecrClient.GetLifecyclePolicy(context.TODO(), &ecr.GetLifecyclePolicyInput{RepositoryName: &repo.Name})
+				var notFoundErr *types.LifecyclePolicyNotFoundException
+				if err != nil && !errors.As(err, &notFoundErr) {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				if err == nil && lifecyclePolicyOutput.LifecyclePolicyText != nil {
+					var policyMap interface{}
+					err = json.Unmarshal([]byte(*lifecyclePolicyOutput.LifecyclePolicyText), &policyMap)
+					if err != nil {
+						fmt.Println(err)
+						return &tfContext, common.NewResourceError(resourceName, err)
+					}
+					rootBody.AppendNewline()
+					policyBlock := rootBody.AppendNewBlock("resource",
+						[]string{AWS_ECR_LIFECYCLE_POLICY,
+							resourceName})
+					policyBody := policyBlock.Body()
+					if err := common.SetAttributeReference(policyBody, ECR_REPOSITORY, AWS_ECR_REPOSITORY+"."+resourceName+".name"); err != nil {
+						fmt.Println(err)
+						return &tfContext, common.NewResourceError(resourceName, err)
+					}
+					if err := common.SetAttributeJsonencode(policyBody, ECR_POLICY, policyMap); err != nil {
+						fmt.Println(err)
+						return &tfContext, common.NewResourceError(resourceName, err)
+					}
+					if config.GenerateTfState {
+						importConfigs = append(importConfigs, common.ImportConfig{
+							ResourceAddress: strings.Join([]string{
+								AWS_ECR_LIFECYCLE_POLICY,
+								resourceName,
+							}, "."),
+							ResourceId: repo.Name,
+							WorkingDir: workingDir,
+						})
+						tfContext.ImportConfigs = importConfigs
+					}
+				}
+			}
+
+			_, err = tfFile.Write(hclFile.Bytes())
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			log.Printf("[TRACE] Terraform config is generated for ecr repository : %s", shortName)
+		}
+		log.Println("[TRACE] <====== ECR repository TF generation done. =====>")
+	}
+	return &tfContext, nil
+}
@@ -0,0 +1,292 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"tenant-native-terraform-generator/duplosdk"
+	"tenant-native-terraform-generator/tf-generator/common"
+)
+
+const (
+	ECS_SERVICE_NAME              string = "name"
+	ECS_CLUSTER                   string = "cluster"
+	ECS_TASK_DEFINITION           string = "task_definition"
+	ECS_DESIRED_COUNT             string = "desired_count"
+	ECS_HEALTH_CHECK_GRACE_PERIOD string = "health_check_grace_period_seconds"
+	ECS_LOAD_BALANCER             string = "load_balancer"
+	ECS_TARGET_GROUP_ARN          string = "target_group_arn"
+	ECS_CONTAINER_NAME            string = "container_name"
+	ECS_CONTAINER_PORT            string = "container_port"
+	ECS_NETWORK_CONFIGURATION     string = "network_configuration"
+	ECS_SUBNETS                   string = "subnets"
+	ECS_SECURITY_GROUPS           string = "security_groups"
+	ECS_ASSIGN_PUBLIC_IP          string = "assign_public_ip"
+)
+
+const AWS_ECS_SERVICE = "aws_ecs_service"
+const ECS_SERVICE_FILE_NAME_PREFIX = "aws-ecs-service-"
+const ECS_TASK_DEF_ARN_SEPARATOR = ":task-definition/"
+
+type AwsEcsService struct {
+}
+
+func (awsEcsService *AwsEcsService) Generate(config *common.Config, client *duplosdk.Client) (*common.TFContext, error) {
+	workingDir := filepath.Join(config.TFCodePath, config.TenantProject)
+	list, clientErr := client.EcsServiceList(config.TenantId)
+
+	if clientErr != nil {
+		fmt.Println(clientErr)
+		return nil, clientErr
+	}
+	tfContext := common.TFContext{}
+	importConfigs := []common.ImportConfig{}
+	ecsClient := ecs.NewFromConfig(config.AwsClientConfig)
+	if list != nil && len(*list) > 0 {
+		log.Println("[TRACE] <====== ECS service TF generation started. =====>")
+		families, clientErr := client.EcsTaskDefinitionFamiliesGet(config.TenantId)
+		if clientErr != nil {
+			fmt.Println(clientErr)
+			return &tfContext, clientErr
+		}
+		targetGroups, clientErr := client.TenantListApplicationLbTargetGroups(config.TenantId)
+		if clientErr != nil {
+			fmt.Println(clientErr)
+			return &tfContext, clientErr
+		}
+		for _, svc := range *list {
+			shortName := strings.TrimPrefix(svc.Name, "duploservices-"+config.TenantName+"-")
+			resourceName := common.GetResourceName(shortName)
+
+			hclFile := hclwrite.NewEmptyFile()
+			path := filepath.Join(workingDir, ECS_SERVICE_FILE_NAME_PREFIX+shortName+".tf")
+			tfFile, err := os.Create(path)
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			rootBody := hclFile.Body()
+
+			// Add aws_ecs_service resource
+			svcBlock := rootBody.AppendNewBlock("resource",
+				[]string{AWS_ECS_SERVICE,
+					resourceName})
+			svcBody := svcBlock.Body()
+			if err := common.SetAttributeTemplate(svcBody, ECS_SERVICE_NAME, common.Template("${local.tenant_prefix}-"+common.EscapeTemplate(shortName))); err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			if err := common.SetAttributeReference(svcBody, ECS_CLUSTER, "local.tenant_prefix"); err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+
+			var taskDef *duplosdk.DuploEcsTaskDef
+			if len(svc.TaskDefinition) > 0 {
+				family := getEcsTaskDefFamilyFromArn(svc.TaskDefinition)
+				if families != nil && common.Contains(*families, family) {
+					if err := common.SetAttributeReference(svcBody, ECS_TASK_DEFINITION, AWS_ECS_TASK_DEFINITION+"."+common.GetResourceName(getEcsTaskDefShortName(config, family))+".arn"); err != nil {
+						fmt.Println(err)
+						return &tfContext, common.NewResourceError(resourceName, err)
+					}
+				} else {
+					svcBody.SetAttributeValue(ECS_TASK_DEFINITION,
+						cty.StringVal(svc.TaskDefinition))
+				}
+				taskDef, clientErr = client.EcsTaskDefinitionGet(config.TenantId, svc.TaskDefinition)
+				if clientErr != nil {
+					fmt.Println(clientErr)
+					return &tfContext, common.NewResourceError(resourceName, clientErr)
+				}
+			}
+			svcBody.SetAttributeValue(ECS_DESIRED_COUNT,
+				cty.NumberIntVal(int64(svc.Replicas)))
+
+			// Add load_balancer blocks wired to the target groups generated below.
+			serviceTargetGroups := []duplosdk.DuploAwsLbTargetGroup{}
+			serviceTargetGroupPorts := []int{}
+			if svc.LBConfigurations != nil && len(*svc.LBConfigurations) > 0 {
+				if svc.HealthCheckGracePeriodSeconds > 0 {
+					svcBody.SetAttributeValue(ECS_HEALTH_CHECK_GRACE_PERIOD,
+						cty.NumberIntVal(int64(svc.HealthCheckGracePeriodSeconds)))
+				}
+				for _, lbc := range *svc.LBConfigurations {
+					tg := getEcsServiceTargetGroup(config, targetGroups, svc.Name, lbc)
+					if tg == nil {
+						log.Printf("[TRACE] Skipping load balancer (%s%s) of ecs service %s, target group not found.", lbc.Protocol, lbc.Port, svc.Name)
+						continue
+					}
+					port, _ := strconv.Atoi(lbc.Port)
+					serviceTargetGroups = append(serviceTargetGroups, *tg)
+					serviceTargetGroupPorts = append(serviceTargetGroupPorts, port)
+					lbBlock := svcBody.AppendNewBlock(ECS_LOAD_BALANCER,
+						nil)
+					lbBody := lbBlock.Body()
+					if err := common.SetAttributeReference(lbBody, ECS_TARGET_GROUP_ARN, AWS_LB_TARGET_GROUP+"."+getLbTargetGroupResourceName(tg.TargetGroupName)+".arn"); err != nil {
+						fmt.Println(err)
+						return &tfContext, common.NewResourceError(resourceName, err)
+					}
+					lbBody.SetAttributeValue(ECS_CONTAINER_NAME,
+						cty.StringVal(getEcsContainerNameForPort(taskDef, port)))
+					lbBody.SetAttributeValue(ECS_CONTAINER_PORT,
+						cty.NumberIntVal(int64(port)))
+				}
+			}
+
+			ecsServiceName := "duploservices-" + config.TenantName + "-" + shortName
+			// Add network_configuration block, awsvpc services can not be created without it.
+			if taskDef != nil && taskDef.NetworkMode != nil && taskDef.NetworkMode.Value == "awsvpc" {
+				cluster := "duploservices-" + config.TenantName
+				describeServicesOutput, err := ecsClient.DescribeServices(context.TODO(), &ecs.DescribeServicesInput{
+					Cluster:  &cluster,
+					Services: []string{ecsServiceName},
+				})
+				if err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				for _, ecsService := range describeServicesOutput.Services {
+					if ecsService.NetworkConfiguration == nil || ecsService.NetworkConfiguration.AwsvpcConfiguration == nil {
+						continue
+					}
+					awsvpc := ecsService.NetworkConfiguration.AwsvpcConfiguration
+					networkBody := svcBody.AppendNewBlock(ECS_NETWORK_CONFIGURATION,
+						nil).Body()
+					var subnetVals []cty.Value
+					for _, subnet := range awsvpc.Subnets {
+						subnetVals = append(subnetVals, cty.StringVal(subnet))
+					}
+					if len(subnetVals) > 0 {
+						networkBody.SetAttributeValue(ECS_SUBNETS,
+							cty.ListVal(subnetVals))
+					}
+					var sgVals []cty.Value
+					for _, sg := range awsvpc.SecurityGroups {
+						sgVals = append(sgVals, cty.StringVal(sg))
+					}
+					if len(sgVals) > 0 {
+						networkBody.SetAttributeValue(ECS_SECURITY_GROUPS,
+							cty.ListVal(sgVals))
+					}
+					networkBody.SetAttributeValue(ECS_ASSIGN_PUBLIC_IP,
+						cty.BoolVal(awsvpc.AssignPublicIp == types.AssignPublicIpEnabled))
+					break
+				}
+			}
+
+			if config.GenerateTfState {
+				importConfigs = append(importConfigs, common.ImportConfig{
+					ResourceAddress: strings.Join([]string{
+						AWS_ECS_SERVICE,
+						resourceName,
+					}, "."),
+					ResourceId: "duploservices-" + config.TenantName + "/" + ecsServiceName,
+					WorkingDir: workingDir,
+				})
+				tfContext.ImportConfigs = importConfigs
+			}
+
+			// Add aws_lb_target_group resources
+			for i, tg := range serviceTargetGroups {
+				rootBody.AppendNewline()
+				if _, err := appendLbTargetGroup(rootBody, tg, serviceTargetGroupPorts[i]); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				tfContext.References = append(tfContext.References, getLbTargetGroupReference(tg))
+				if config.GenerateTfState {
+					importConfigs = append(importConfigs, getLbTargetGroupImportConfig(tg, workingDir))
+					tfContext.ImportConfigs = importConfigs
+				}
+			}
+
+			_, err = tfFile.Write(hclFile.Bytes())
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			log.Printf("[TRACE] Terraform config is generated for ecs service : %s", shortName)
+		}
+		log.Println("[TRACE] <====== ECS service TF generation done. =====>")
+	}
+	return &tfContext, nil
+}
+
+// getEcsTaskDefFamilyFromArn returns the family of a task definition ARN, dropping the revision.
+func getEcsTaskDefFamilyFromArn(taskDefArn string) string {
+	family := taskDefArn
+	if idx := strings.Index(family, ECS_TASK_DEF_ARN_SEPARATOR); idx >= 0 {
+		family = family[idx+len(ECS_TASK_DEF_ARN_SEPARATOR):]
+	}
+	if idx := strings.LastIndex(family, ":"); idx >= 0 {
+		family = family[:idx]
+	}
+	return family
+}
+
+// getEcsServiceTargetGroup finds the target group duplo created for an ecs service load balancer config,
+// duplo names it duplo3-<tenant>-<service>-<protocol><port>.
+func getEcsServiceTargetGroup(config *common.Config, targetGroups *[]duplosdk.DuploAwsLbTargetGroup, serviceName string, lbc duplosdk.DuploEcsServiceLbConfig) *duplosdk.DuploAwsLbTargetGroup {
+	if targetGroups == nil {
+		return nil
+	}
+	tgName := strings.Join([]string{"duplo3", config.TenantName, serviceName, lbc.Protocol + lbc.Port}, "-")
+	for _, tg := range *targetGroups {
+		if strings.EqualFold(tg.TargetGroupName, tgName) {
+			return &tg
+		}
+	}
+	return nil
+}
+
+// getEcsServiceTargetGroupNames returns the names of the target groups owned by the ecs services of a tenant.
+func getEcsServiceTargetGroupNames(config *common.Config, client *duplosdk.Client, targetGroups *[]duplosdk.DuploAwsLbTargetGroup) ([]string, duplosdk.ClientError) {
+	names := []string{}
+	list, clientErr := client.EcsServiceList(config.TenantId)
+	if clientErr != nil {
+		return nil, clientErr
+	}
+	if list != nil {
+		for _, svc := range *list {
+			if svc.LBConfigurations == nil {
+				continue
+			}
+			for _, lbc := range *svc.LBConfigurations {
+				if tg := getEcsServiceTargetGroup(config, targetGroups, svc.Name, lbc); tg != nil {
+					names = append(names, tg.TargetGroupName)
+				}
+			}
+		}
+	}
+	return names, nil
+}
+
+// getEcsContainerNameForPort returns the container exposing the port, defaulting to the first container.
+func getEcsContainerNameForPort(taskDef *duplosdk.DuploEcsTaskDef, port int) string {
+	if taskDef == nil || len(taskDef.ContainerDefinitions) == 0 {
+		return ""
+	}
+	for _, containerDef := range taskDef.ContainerDefinitions {
+		portMappings, _ := containerDef["PortMappings"].([]interface{})
+		for _, portMapping := range portMappings {
+			if pm, ok := portMapping.(map[string]interface{}); ok {
+				if containerPort, ok := pm["ContainerPort"].(float64); ok && int(containerPort) == port {
+					name, _ := containerDef["Name"].(string)
+					return name
+				}
+			}
+		}
+	}
+	name, _ := taskDef.ContainerDefinitions[0]["Name"].(string)
+	return name
+}
@@ -0,0 +1,300 @@
+package tenant
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"tenant-native-terraform-generator/duplosdk"
+	"tenant-native-terraform-generator/tf-generator/common"
+)
+
+const (
+	ECS_FAMILY                   string = "family"
+	ECS_CPU                      string = "cpu"
+	ECS_MEMORY                   string = "memory"
+	ECS_NETWORK_MODE             string = "network_mode"
+	ECS_REQUIRES_COMPATIBILITIES string = "requires_compatibilities"
+	ECS_TASK_ROLE_ARN            string = "task_role_arn"
+	ECS_EXECUTION_ROLE_ARN       string = "execution_role_arn"
+	ECS_IPC_MODE                 string = "ipc_mode"
+	ECS_PID_MODE                 string = "pid_mode"
+	ECS_CONTAINER_DEFINITIONS    string = "container_definitions"
+	ECS_VOLUME                   string = "volume"
+	ECS_NAME                     string = "name"
+	ECS_HOST_PATH                string = "host_path"
+	ECS_PLACEMENT_CONSTRAINTS    string = "placement_constraints"
+	ECS_TYPE                     string = "type"
+	ECS_EXPRESSION               string = "expression"
+)
+
+const AWS_ECS_TASK_DEFINITION = "aws_ecs_task_definition"
+const ECS_TASK_DEF_VAR_PREFIX = "ecs_task_"
+const ECS_TASK_DEF_FILE_NAME_PREFIX = "aws-ecs-task-"
+
+// Keys whose children are user defined and must be kept as is while normalizing container definitions.
+var ecsVerbatimKeys = []string{"options", "dockerLabels", "driverOpts", "labels"}
+
+type AwsEcsTaskDefinition struct {
+}
+
+func (awsEcsTaskDefinition *AwsEcsTaskDefinition) Generate(config *common.Config, client *duplosdk.Client) (*common.TFContext, error) {
+	workingDir := filepath.Join(config.TFCodePath, config.TenantProject)
+	list, clientErr := client.EcsTaskDefinitionFamiliesGet(config.TenantId)
+
+	if clientErr != nil {
+		fmt.Println(clientErr)
+		return nil, clientErr
+	}
+	tfContext := common.TFContext{}
+	importConfigs := []common.ImportConfig{}
+	if list != nil && len(*list) > 0 {
+		log.Println("[TRACE] <====== ECS task definition TF generation started. =====>")
+		for _, family := range *list {
+			taskDef, clientErr := client.EcsTaskDefinitionGet(config.TenantId, family)
+			if clientErr != nil {
+				fmt.Println(clientErr)
+				return &tfContext, clientErr
+			}
+			if taskDef == nil || len(taskDef.Family) == 0 {
+				continue
+			}
+			shortName := getEcsTaskDefShortName(config, taskDef.Family)
+			resourceName := common.GetResourceName(shortName)
+			varFullPrefix := ECS_TASK_DEF_VAR_PREFIX + resourceName + "_"
+
+			hclFile := hclwrite.NewEmptyFile()
+			path := filepath.Join(workingDir, ECS_TASK_DEF_FILE_NAME_PREFIX+shortName+".tf")
+			tfFile, err := os.Create(path)
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			rootBody := hclFile.Body()
+
+			// Add aws_ecs_task_definition resource
+			taskDefBlock := rootBody.AppendNewBlock("resource",
+				[]string{AWS_ECS_TASK_DEFINITION,
+					resourceName})
+			taskDefBody := taskDefBlock.Body()
+			familyName := common.Interpolate(taskDef.Family)
+			if strings.HasPrefix(taskDef.Family, "duploservices-"+config.TenantName+"-") {
+				familyName = common.Template("${local.tenant_prefix}-" + common.EscapeTemplate(shortName))
+			}
+			if err := common.SetAttributeTemplate(taskDefBody, ECS_FAMILY, familyName); err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			if len(taskDef.CPU) > 0 {
+				taskDefBody.SetAttributeValue(ECS_CPU,
+					cty.StringVal(taskDef.CPU))
+			}
+			if len(taskDef.Memory) > 0 {
+				taskDefBody.SetAttributeValue(ECS_MEMORY,
+					cty.StringVal(taskDef.Memory))
+			}
+			if taskDef.NetworkMode != nil && len(taskDef.NetworkMode.Value) > 0 {
+				taskDefBody.SetAttributeValue(ECS_NETWORK_MODE,
+					cty.StringVal(taskDef.NetworkMode.Value))
+			}
+			if len(taskDef.RequiresCompatibilities) > 0 {
+				var vals []cty.Value
+				for _, s := range taskDef.RequiresCompatibilities {
+					vals = append(vals, cty.StringVal(s))
+				}
+				taskDefBody.SetAttributeValue(ECS_REQUIRES_COMPATIBILITIES,
+					cty.ListVal(vals))
+			}
+			if len(taskDef.TaskRoleArn) > 0 {
+				taskDefBody.SetAttributeValue(ECS_TASK_ROLE_ARN,
+					cty.StringVal(taskDef.TaskRoleArn))
+			}
+			if len(taskDef.ExecutionRoleArn) > 0 {
+				taskDefBody.SetAttributeValue(ECS_EXECUTION_ROLE_ARN,
+					cty.StringVal(taskDef.ExecutionRoleArn))
+			}
+			if len(taskDef.IpcMode) > 0 {
+				taskDefBody.SetAttributeValue(ECS_IPC_MODE,
+					cty.StringVal(taskDef.IpcMode))
+			}
+			if len(taskDef.PidMode) > 0 {
+				taskDefBody.SetAttributeValue(ECS_PID_MODE,
+					cty.StringVal(taskDef.PidMode))
+			}
+
+			// Container images are split into repository and tag, the tag becomes an input variable.
+			containerDefs := []interface{}{}
+			for _, containerDef := range taskDef.ContainerDefinitions {
+				normalized, _ := normalizeEcsJson(containerDef).(map[string]interface{})
+				if normalized == nil {
+					continue
+				}
+				containerName, _ := normalized["name"].(string)
+				if image, ok := normalized["image"].(string); ok {
+					if repository, tag := splitEcsImageTag(image); len(tag) > 0 {
+						varName := varFullPrefix + common.GetResourceName(containerName) + "_image_tag"
+						normalized["image"] = common.Template(common.EscapeTemplate(repository) + ":${var." + varName + "}")
+						tfContext.InputVars = append(tfContext.InputVars, common.VarConfig{
+							Name:       varName,
+							DefaultVal: tag,
+							TypeVal:    "string",
+							DescVal:    "Image tag of the container " + containerName + " in the ECS task definition " + taskDef.Family + ".",
+						})
+					}
+				}
+				containerDefs = append(containerDefs, normalized)
+			}
+			if err := common.SetAttributeJsonencode(taskDefBody, ECS_CONTAINER_DEFINITIONS, containerDefs); err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+
+			for _, volume := range taskDef.Volumes {
+				name, _ := volume["Name"].(string)
+				if len(name) == 0 {
+					continue
+				}
+				volumeBlock := taskDefBody.AppendNewBlock(ECS_VOLUME,
+					nil)
+				volumeBody := volumeBlock.Body()
+				volumeBody.SetAttributeValue(ECS_NAME,
+					cty.StringVal(name))
+				if host, ok := volume["Host"].(map[string]interface{}); ok {
+					if sourcePath, ok := host["SourcePath"].(string); ok && len(sourcePath) > 0 {
+						volumeBody.SetAttributeValue(ECS_HOST_PATH,
+							cty.StringVal(sourcePath))
+					}
+				}
+			}
+			if taskDef.PlacementConstraints != nil {
+				for _, constraint := range *taskDef.PlacementConstraints {
+					constraintBlock := taskDefBody.AppendNewBlock(ECS_PLACEMENT_CONSTRAINTS,
+						nil)
+					constraintBody := constraintBlock.Body()
+					constraintBody.SetAttributeValue(ECS_TYPE,
+						cty.StringVal(constraint.Type))
+					if len(constraint.Expression) > 0 {
+						constraintBody.SetAttributeValue(ECS_EXPRESSION,
+							cty.StringVal(constraint.Expression))
+					}
+				}
+			}
+
+			if config.GenerateTfState && len(taskDef.Arn) > 0 {
+				importConfigs = append(importConfigs, common.ImportConfig{
+					ResourceAddress: strings.Join([]string{
+						AWS_ECS_TASK_DEFINITION,
+						resourceName,
+					}, "."),
+					ResourceId: taskDef.Arn,
+					WorkingDir: workingDir,
+				})
+				tfContext.ImportConfigs = importConfigs
+			}
+
+			_, err = tfFile.Write(hclFile.Bytes())
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			log.Printf("[TRACE] Terraform config is generated for ecs task definition : %s", shortName)
+		}
+		log.Println("[TRACE] <====== ECS task definition TF generation done. =====>")
+	}
+	return &tfContext, nil
+}
+
+// getEcsTaskDefShortName strips the duplo tenant prefix from a task definition family.
+func getEcsTaskDefShortName(config *common.Config, family string) string {
+	return strings.TrimPrefix(family, "duploservices-"+config.TenantName+"-")
+}
+
+// splitEcsImageTag splits an image into repository and tag, the tag is empty for untagged or digest images.
+func splitEcsImageTag(image string) (string, string) {
+	if strings.Contains(image, "@") {
+		return image, ""
+	}
+	idx := strings.LastIndex(image, ":")
+	if idx < 0 || strings.Contains(image[idx:], "/") {
+		return image, ""
+	}
+	return image[:idx], image[idx+1:]
+}
+
+// normalizeEcsJson converts the duplo representation of a container definition into the ECS
+// JSON format: keys are camel cased, {"Value": x} enums are unwrapped and empty values dropped.
+func normalizeEcsJson(val interface{}) interface{} {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		if enumVal, ok := v["Value"]; ok && len(v) == 1 {
+			return enumVal
+		}
+		result := map[string]interface{}{}
+		for key, child := range v {
+			key = ecsCamelCase(key)
+			if common.Contains(ecsVerbatimKeys, key) {
+				if m, ok := child.(map[string]interface{}); ok && len(m) > 0 {
+					result[key] = m
+				}
+				continue
+			}
+			normalized := normalizeEcsJson(child)
+			// An environment variable with an empty value is still part of the container definition.
+			if isEmptyEcsJson(normalized) && !(key == "value" && child == "") {
+				continue
+			}
+			if (key == "memory" || key == "memoryReservation") && normalized == float64(0) {
+				continue
+			}
+			result[key] = normalized
+		}
+		return result
+	case []interface{}:
+		result := []interface{}{}
+		for _, child := range v {
+			normalized := normalizeEcsJson(child)
+			if !isEmptyEcsJson(normalized) {
+				result = append(result, normalized)
+			}
+		}
+		return result
+	}
+	return val
+}
+
+func isEmptyEcsJson(val interface{}) bool {
+	switch v := val.(type) {
+	case nil:
+		return true
+	case string:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// ecsCamelCase lowercases the leading word of a key, an acronym included, like DNSServers to dnsServers.
+func ecsCamelCase(key string) string {
+	runes := []rune(key)
+	n := 0
+	for n < len(runes) && unicode.IsUpper(runes[n]) {
+		n++
+	}
+	// The last capital of an acronym followed by lowercase starts the next word.
+	if n > 1 && n < len(runes) && unicode.IsLower(runes[n]) {
+		n--
+	}
+	for i := 0; i < n; i++ {
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
@@ -10,7 +10,6 @@ import (
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/service/elasticache"
-	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/zclconf/go-cty/cty"
 
@@ -55,7 +54,7 @@ func (awsElasticacheCluster *AwsElasticacheCluster) Generate(config *common.Conf
 
 	if clientErr != nil {
 		fmt.Println(clientErr)
-		return nil, nil
+		return nil, clientErr
 	}
 	tfContext := common.TFContext{}
 	importConfigs := []common.ImportConfig{}
@@ -68,7 +67,7 @@ func (awsElasticacheCluster *AwsElasticacheCluster) Generate(config *common.Conf
 					&elasticache.DescribeReplicationGroupsInput{ReplicationGroupId: &cluster.Identifier})
 				if err != nil {
 					fmt.Println(err)
-					return nil, err
+					return &tfContext, err
 				}
 				b, err := json.Marshal(replicationGroupsOutput)
 				if err != nil {
@@ -88,13 +87,23 @@ func (awsElasticacheCluster *AwsElasticacheCluster) Generate(config *common.Conf
 						tfFile, err := os.Create(path)
 						if err != nil {
 							fmt.Println(err)
-							return nil, err
+							return &tfContext, common.NewResourceError(resourceName, err)
 						}
 						rootBody := hclFile.Body()
 						ecacheBlock := rootBody.AppendNewBlock("resource",
 							[]string{AWS_ELASTICACHE_REPLICATION_GROUP,
 								resourceName})
 						ecacheBody := ecacheBlock.Body()
+						tfContext.References = append(tfContext.References, common.ResourceReference{
+							Value:   *rg.ReplicationGroupId,
+							Address: AWS_ELASTICACHE_REPLICATION_GROUP + "." + resourceName + ".id",
+						})
+						if rg.ARN != nil {
+							tfContext.References = append(tfContext.References, common.ResourceReference{
+								Value:   *rg.ARN,
+								Address: AWS_ELASTICACHE_REPLICATION_GROUP + "." + resourceName + ".arn",
+							})
+						}
 						ecacheBody.SetAttributeValue(REPLICATION_GROUP_ID,
 							cty.StringVal(*rg.ReplicationGroupId))
 						ecacheBody.SetAttributeValue(DESCRIPTION,
@@ -132,7 +141,7 @@ func (awsElasticacheCluster *AwsElasticacheCluster) Generate(config *common.Conf
 							})
 							if err != nil {
 								fmt.Println(err)
-								return nil, err
+								return &tfContext, common.NewResourceError(resourceName, err)
 							}
 							if cacheClusters != nil && len(cacheClusters.CacheClusters) > 0 {
 								cluster := cacheClusters.CacheClusters[0]
@@ -167,23 +176,19 @@ func (awsElasticacheCluster *AwsElasticacheCluster) Generate(config *common.Conf
 								})
 								if err != nil {
 									fmt.Println(err)
-									return nil, err
+									return &tfContext, common.NewResourceError(resourceName, err)
 								}
 								if tagsOutput != nil {
 									if len(tagsOutput.TagList) > 0 {
-										tagsTokens := hclwrite.Tokens{
-											{Type: hclsyntax.TokenOQuote, Bytes: []byte(`{`)},
-											{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")},
-										}
+										tags := map[string]common.Template{}
 										for _, tag := range tagsOutput.TagList {
-											tagValue := strings.Replace(*tag.Value, config.TenantName, "${local.tenant_name}", -1)
-											tag := "\"" + *tag.Key + "\"" + " = \"" + tagValue + "\"\n"
-											tagsTokens = append(tagsTokens,
-												&hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(tag)},
-											)
+											tagValue := common.Interpolate(*tag.Value, common.Interpolation{Value: config.TenantName, Expr: "local.tenant_name"})
+											tags[*tag.Key] = tagValue
+										}
+										if err := common.SetAttributeTags(ecacheBody, TAGS, tags); err != nil {
+											fmt.Println(err)
+											return &tfContext, common.NewResourceError(resourceName, err)
 										}
-										tagsTokens = append(tagsTokens, &hclwrite.Token{Type: hclsyntax.TokenCQuote, Bytes: []byte(`}`)})
-										ecacheBody.SetAttributeRaw(TAGS, tagsTokens)
 									}
 								}
 							}
@@ -192,7 +197,7 @@ func (awsElasticacheCluster *AwsElasticacheCluster) Generate(config *common.Conf
 						_, err = tfFile.Write(hclFile.Bytes())
 						if err != nil {
 							fmt.Println(err)
-							return nil, err
+							return &tfContext, common.NewResourceError(resourceName, err)
 						}
 						if config.GenerateTfState {
 							importConfigs = append(importConfigs, common.ImportConfig{
@@ -212,7 +217,7 @@ func (awsElasticacheCluster *AwsElasticacheCluster) Generate(config *common.Conf
 					&elasticache.DescribeCacheClustersInput{CacheClusterId: &cluster.Identifier})
 				if err != nil {
 					fmt.Println(err)
-					return nil, err
+					return &tfContext, err
 				}
 				if cacheClusters != nil && len(cacheClusters.CacheClusters) > 0 {
 					b, err := json.Marshal(cacheClusters)
@@ -232,13 +237,23 @@ func (awsElasticacheCluster *AwsElasticacheCluster) Generate(config *common.Conf
 						tfFile, err := os.Create(path)
 						if err != nil {
 							fmt.Println(err)
-							return nil, err
+							return &tfContext, common.NewResourceError(resourceName, err)
 						}
 						rootBody := hclFile.Body()
 						ecacheBlock := rootBody.AppendNewBlock("resource",
 							[]string{AWS_ELASTICACHE_CLUSTER,
 								resourceName})
 						ecacheBody := ecacheBlock.Body()
+						tfContext.References = append(tfContext.References, common.ResourceReference{
+							Value:   *memcached.CacheClusterId,
+							Address: AWS_ELASTICACHE_CLUSTER + "." + resourceName + ".id",
+						})
+						if memcached.ARN != nil {
+							tfContext.References = append(tfContext.References, common.ResourceReference{
+								Value:   *memcached.ARN,
+								Address: AWS_ELASTICACHE_CLUSTER + "." + resourceName + ".arn",
+							})
+						}
 						ecacheBody.SetAttributeValue(CLUSTER_ID,
 							cty.StringVal(*memcached.CacheClusterId))
 						ecacheBody.SetAttributeValue(ENGINE,
@@ -284,29 +299,25 @@ func (awsElasticacheCluster *AwsElasticacheCluster) Generate(config *common.Conf
 						})
 						if err != nil {
 							fmt.Println(err)
-							return nil, err
+							return &tfContext, common.NewResourceError(resourceName, err)
 						}
 						if tagsOutput != nil {
 							if len(tagsOutput.TagList) > 0 {
-								tagsTokens := hclwrite.Tokens{
-									{Type: hclsyntax.TokenOQuote, Bytes: []byte(`{`)},
-									{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")},
-								}
+								tags := map[string]common.Template{}
 								for _, tag := range tagsOutput.TagList {
-									tagValue := strings.Replace(*tag.Value, config.TenantName, "${local.tenant_name}", -1)
-									tag := "\"" + *tag.Key + "\"" + " = \"" + tagValue + "\"\n"
-									tagsTokens = append(tagsTokens,
-										&hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(tag)},
-									)
+									tagValue := common.Interpolate(*tag.Value, common.Interpolation{Value: config.TenantName, Expr: "local.tenant_name"})
+									tags[*tag.Key] = tagValue
+								}
+								if err := common.SetAttributeTags(ecacheBody, TAGS, tags); err != nil {
+									fmt.Println(err)
+									return &tfContext, common.NewResourceError(resourceName, err)
 								}
-								tagsTokens = append(tagsTokens, &hclwrite.Token{Type: hclsyntax.TokenCQuote, Bytes: []byte(`}`)})
-								ecacheBody.SetAttributeRaw(TAGS, tagsTokens)
 							}
 						}
 						_, err = tfFile.Write(hclFile.Bytes())
 						if err != nil {
 							fmt.Println(err)
-							return nil, err
+							return &tfContext, common.NewResourceError(resourceName, err)
 						}
 						if config.GenerateTfState {
 							importConfigs = append(importConfigs, common.ImportConfig{
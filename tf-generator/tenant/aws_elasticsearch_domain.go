@@ -0,0 +1,260 @@
+package tenant
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"tenant-native-terraform-generator/duplosdk"
+	"tenant-native-terraform-generator/tf-generator/common"
+)
+
+const (
+	ES_DOMAIN_NAME                   string = "domain_name"
+	ES_VERSION                       string = "elasticsearch_version"
+	ES_ACCESS_POLICIES               string = "access_policies"
+	ES_ADVANCED_OPTIONS              string = "advanced_options"
+	ES_CLUSTER_CONFIG                string = "cluster_config"
+	ES_INSTANCE_TYPE                 string = "instance_type"
+	ES_INSTANCE_COUNT                string = "instance_count"
+	ES_DEDICATED_MASTER_ENABLED      string = "dedicated_master_enabled"
+	ES_DEDICATED_MASTER_TYPE         string = "dedicated_master_type"
+	ES_DEDICATED_MASTER_COUNT        string = "dedicated_master_count"
+	ES_EBS_OPTIONS                   string = "ebs_options"
+	ES_EBS_ENABLED                   string = "ebs_enabled"
+	ES_VOLUME_SIZE                   string = "volume_size"
+	ES_VOLUME_TYPE                   string = "volume_type"
+	ES_IOPS                          string = "iops"
+	ES_VPC_OPTIONS                   string = "vpc_options"
+	ES_SECURITY_GROUP_IDS            string = "security_group_ids"
+	ES_SUBNET_IDS                    string = "subnet_ids"
+	ES_ENCRYPT_AT_REST               string = "encrypt_at_rest"
+	ES_ENABLED                       string = "enabled"
+	ES_KMS_KEY_ID                    string = "kms_key_id"
+	ES_NODE_TO_NODE_ENCRYPTION       string = "node_to_node_encryption"
+	ES_DOMAIN_ENDPOINT_OPTIONS       string = "domain_endpoint_options"
+	ES_ENFORCE_HTTPS                 string = "enforce_https"
+	ES_TLS_SECURITY_POLICY           string = "tls_security_policy"
+	ES_SNAPSHOT_OPTIONS              string = "snapshot_options"
+	ES_AUTOMATED_SNAPSHOT_START_HOUR string = "automated_snapshot_start_hour"
+)
+
+const AWS_ELASTICSEARCH_DOMAIN = "aws_elasticsearch_domain"
+const ES_VAR_PREFIX = "es_"
+const ES_FILE_NAME_PREFIX = "aws-es-"
+
+type AwsElasticsearchDomain struct {
+}
+
+func (awsElasticsearchDomain *AwsElasticsearchDomain) Generate(config *common.Config, client *duplosdk.Client) (*common.TFContext, error) {
+	workingDir := filepath.Join(config.TFCodePath, config.TenantProject)
+	list, clientErr := client.TenantListElasticSearchDomains(config.TenantId)
+
+	if clientErr != nil {
+		fmt.Println(clientErr)
+		return nil, clientErr
+	}
+	tfContext := common.TFContext{}
+	importConfigs := []common.ImportConfig{}
+	if list != nil && len(*list) > 0 {
+		log.Println("[TRACE] <====== Elasticsearch domain TF generation started. =====>")
+		tenantKms, clientErr := client.TenantGetTenantKmsKey(config.TenantId)
+		if clientErr != nil {
+			fmt.Println(clientErr)
+			return &tfContext, clientErr
+		}
+		ec2Client := ec2.NewFromConfig(config.AwsClientConfig)
+		for _, domain := range *list {
+			if domain.Deleted {
+				continue
+			}
+			shortName := domain.Name
+			resourceName := common.GetResourceName(shortName)
+			varFullPrefix := ES_VAR_PREFIX + resourceName + "_"
+
+			hclFile := hclwrite.NewEmptyFile()
+			path := filepath.Join(workingDir, ES_FILE_NAME_PREFIX+shortName+".tf")
+			tfFile, err := os.Create(path)
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			rootBody := hclFile.Body()
+
+			// Add aws_elasticsearch_domain resource
+			esBlock := rootBody.AppendNewBlock("resource",
+				[]string{AWS_ELASTICSEARCH_DOMAIN,
+					resourceName})
+			esBody := esBlock.Body()
+			if len(domain.Arn) > 0 {
+				tfContext.References = append(tfContext.References, common.ResourceReference{
+					Value:   domain.Arn,
+					Address: AWS_ELASTICSEARCH_DOMAIN + "." + resourceName + ".arn",
+				})
+			}
+			domainName := common.Interpolate(domain.DomainName)
+			if shortName != domain.DomainName {
+				domainName = common.Template("${local.tenant_prefix}-" + common.EscapeTemplate(shortName))
+			}
+			if err := common.SetAttributeTemplate(esBody, ES_DOMAIN_NAME, domainName); err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			esBody.SetAttributeValue(ES_VERSION,
+				cty.StringVal(domain.ElasticSearchVersion))
+			if len(domain.AdvancedOptions) > 0 {
+				advancedOptions := map[string]cty.Value{}
+				for key, value := range domain.AdvancedOptions {
+					advancedOptions[key] = cty.StringVal(value)
+				}
+				esBody.SetAttributeValue(ES_ADVANCED_OPTIONS,
+					cty.MapVal(advancedOptions))
+			}
+
+			clusterConfigBlock := esBody.AppendNewBlock(ES_CLUSTER_CONFIG,
+				nil)
+			clusterConfigBody := clusterConfigBlock.Body()
+			clusterConfigBody.SetAttributeValue(ES_INSTANCE_TYPE,
+				cty.StringVal(domain.ClusterConfig.InstanceType.Value))
+			clusterConfigBody.SetAttributeValue(ES_INSTANCE_COUNT,
+				cty.NumberIntVal(int64(domain.ClusterConfig.InstanceCount)))
+			clusterConfigBody.SetAttributeValue(ES_DEDICATED_MASTER_ENABLED,
+				cty.BoolVal(domain.ClusterConfig.DedicatedMasterEnabled))
+			if domain.ClusterConfig.DedicatedMasterEnabled {
+				if domain.ClusterConfig.DedicatedMasterType != nil {
+					clusterConfigBody.SetAttributeValue(ES_DEDICATED_MASTER_TYPE,
+						cty.StringVal(domain.ClusterConfig.DedicatedMasterType.Value))
+				}
+				clusterConfigBody.SetAttributeValue(ES_DEDICATED_MASTER_COUNT,
+					cty.NumberIntVal(int64(domain.ClusterConfig.DedicatedMasterCount)))
+			}
+
+			ebsOptionsBlock := esBody.AppendNewBlock(ES_EBS_OPTIONS,
+				nil)
+			ebsOptionsBody := ebsOptionsBlock.Body()
+			ebsOptionsBody.SetAttributeValue(ES_EBS_ENABLED,
+				cty.BoolVal(domain.EBSOptions.EBSEnabled))
+			if domain.EBSOptions.EBSEnabled {
+				ebsOptionsBody.SetAttributeValue(ES_VOLUME_SIZE,
+					cty.NumberIntVal(int64(domain.EBSOptions.VolumeSize)))
+				if domain.EBSOptions.VolumeType != nil && len(domain.EBSOptions.VolumeType.Value) > 0 {
+					ebsOptionsBody.SetAttributeValue(ES_VOLUME_TYPE,
+						cty.StringVal(domain.EBSOptions.VolumeType.Value))
+				}
+				if domain.EBSOptions.IOPS > 0 {
+					ebsOptionsBody.SetAttributeValue(ES_IOPS,
+						cty.NumberIntVal(int64(domain.EBSOptions.IOPS)))
+				}
+			}
+
+			if len(domain.VPCOptions.SubnetIDs) > 0 {
+				vpcOptionsBlock := esBody.AppendNewBlock(ES_VPC_OPTIONS,
+					nil)
+				vpcOptionsBody := vpcOptionsBlock.Body()
+				if len(domain.VPCOptions.SecurityGroupIDs) > 0 {
+					sgTokens, err := getSecurityGroupIdsTokens(config, ec2Client, domain.VPCOptions.SecurityGroupIDs)
+					if err != nil {
+						fmt.Println(err)
+						return &tfContext, common.NewResourceError(resourceName, err)
+					}
+					vpcOptionsBody.SetAttributeRaw(ES_SECURITY_GROUP_IDS, sgTokens)
+				}
+				var subnetVals []cty.Value
+				for _, subnetId := range domain.VPCOptions.SubnetIDs {
+					subnetVals = append(subnetVals, cty.StringVal(subnetId))
+				}
+				vpcOptionsBody.SetAttributeValue(ES_SUBNET_IDS,
+					cty.ListVal(subnetVals))
+			}
+
+			encryptAtRestBlock := esBody.AppendNewBlock(ES_ENCRYPT_AT_REST,
+				nil)
+			encryptAtRestBody := encryptAtRestBlock.Body()
+			encryptAtRestBody.SetAttributeValue(ES_ENABLED,
+				cty.BoolVal(domain.EncryptionAtRestOptions.Enabled))
+			if isTenantKmsKey(tenantKms, domain.EncryptionAtRestOptions.KmsKeyID) {
+				if err := common.SetAttributeReference(encryptAtRestBody, ES_KMS_KEY_ID, AWS_KMS_KEY+"."+TENANT_KMS+".arn"); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+			} else if len(domain.EncryptionAtRestOptions.KmsKeyID) > 0 {
+				encryptAtRestBody.SetAttributeValue(ES_KMS_KEY_ID,
+					cty.StringVal(domain.EncryptionAtRestOptions.KmsKeyID))
+			}
+
+			nodeToNodeBlock := esBody.AppendNewBlock(ES_NODE_TO_NODE_ENCRYPTION,
+				nil)
+			nodeToNodeBlock.Body().SetAttributeValue(ES_ENABLED,
+				cty.BoolVal(domain.NodeToNodeEncryptionOptions.Enabled))
+
+			endpointOptionsBlock := esBody.AppendNewBlock(ES_DOMAIN_ENDPOINT_OPTIONS,
+				nil)
+			endpointOptionsBody := endpointOptionsBlock.Body()
+			endpointOptionsBody.SetAttributeValue(ES_ENFORCE_HTTPS,
+				cty.BoolVal(domain.DomainEndpointOptions.EnforceHTTPS))
+			if len(domain.DomainEndpointOptions.TLSSecurityPolicy.Value) > 0 {
+				endpointOptionsBody.SetAttributeValue(ES_TLS_SECURITY_POLICY,
+					cty.StringVal(domain.DomainEndpointOptions.TLSSecurityPolicy.Value))
+			}
+
+			snapshotOptionsBlock := esBody.AppendNewBlock(ES_SNAPSHOT_OPTIONS,
+				nil)
+			snapshotOptionsBlock.Body().SetAttributeValue(ES_AUTOMATED_SNAPSHOT_START_HOUR,
+				cty.NumberIntVal(int64(domain.SnapshotOptions.AutomatedSnapshotStartHour)))
+
+			if len(domain.AccessPolicies) > 0 {
+				var policyMap interface{}
+				err = json.Unmarshal([]byte(domain.AccessPolicies), &policyMap)
+				if err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				policy := common.InterpolateValues(policyMap,
+					common.Interpolation{Value: config.AccountID, Expr: "local.account_id"})
+				if err := common.SetAttributeJsonencode(esBody, ES_ACCESS_POLICIES, policy); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+			}
+
+			if config.GenerateTfState {
+				importConfigs = append(importConfigs, common.ImportConfig{
+					ResourceAddress: strings.Join([]string{
+						AWS_ELASTICSEARCH_DOMAIN,
+						resourceName,
+					}, "."),
+					ResourceId: domain.DomainName,
+					WorkingDir: workingDir,
+				})
+				tfContext.ImportConfigs = importConfigs
+			}
+
+			_, err = tfFile.Write(hclFile.Bytes())
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			log.Printf("[TRACE] Terraform config is generated for elasticsearch domain : %s", shortName)
+
+			tfContext.OutputVars = append(tfContext.OutputVars, common.OutputVarConfig{
+				Name: varFullPrefix + "endpoint",
+				ActualVal: strings.Join([]string{
+					AWS_ELASTICSEARCH_DOMAIN,
+					resourceName,
+					"endpoint",
+				}, "."),
+				DescVal:       "Domain-specific endpoint used to submit index, search, and data upload requests.",
+				RootTraversal: true,
+			})
+		}
+		log.Println("[TRACE] <====== Elasticsearch domain TF generation done. =====>")
+	}
+	return &tfContext, nil
+}
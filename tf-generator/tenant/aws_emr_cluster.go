@@ -0,0 +1,551 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/emr"
+	"github.com/aws/aws-sdk-go-v2/service/emr/types"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"tenant-native-terraform-generator/duplosdk"
+	"tenant-native-terraform-generator/tf-generator/common"
+)
+
+const (
+	EMR_NAME                              string = "name"
+	EMR_RELEASE_LABEL                     string = "release_label"
+	EMR_APPLICATIONS                      string = "applications"
+	EMR_LOG_URI                           string = "log_uri"
+	EMR_SERVICE_ROLE                      string = "service_role"
+	EMR_AUTOSCALING_ROLE                  string = "autoscaling_role"
+	EMR_TERMINATION_PROTECTION            string = "termination_protection"
+	EMR_KEEP_JOB_FLOW_ALIVE_WHEN_NO_STEPS string = "keep_job_flow_alive_when_no_steps"
+	EMR_STEP_CONCURRENCY_LEVEL            string = "step_concurrency_level"
+	EMR_EBS_ROOT_VOLUME_SIZE              string = "ebs_root_volume_size"
+	EMR_CUSTOM_AMI_ID                     string = "custom_ami_id"
+	EMR_SCALE_DOWN_BEHAVIOR               string = "scale_down_behavior"
+	EMR_ADDITIONAL_INFO                   string = "additional_info"
+	EMR_CONFIGURATIONS_JSON               string = "configurations_json"
+	EMR_EC2_ATTRIBUTES                    string = "ec2_attributes"
+	EMR_SUBNET_ID                         string = "subnet_id"
+	EMR_KEY_NAME                          string = "key_name"
+	EMR_INSTANCE_PROFILE                  string = "instance_profile"
+	EMR_MANAGED_MASTER_SECURITY_GROUP     string = "emr_managed_master_security_group"
+	EMR_MANAGED_SLAVE_SECURITY_GROUP      string = "emr_managed_slave_security_group"
+	EMR_SERVICE_ACCESS_SECURITY_GROUP     string = "service_access_security_group"
+	EMR_ADDITIONAL_MASTER_SECURITY_GROUPS string = "additional_master_security_groups"
+	EMR_ADDITIONAL_SLAVE_SECURITY_GROUPS  string = "additional_slave_security_groups"
+	EMR_MASTER_INSTANCE_GROUP             string = "master_instance_group"
+	EMR_CORE_INSTANCE_GROUP               string = "core_instance_group"
+	EMR_MASTER_INSTANCE_FLEET             string = "master_instance_fleet"
+	EMR_CORE_INSTANCE_FLEET               string = "core_instance_fleet"
+	EMR_INSTANCE_TYPE                     string = "instance_type"
+	EMR_INSTANCE_COUNT                    string = "instance_count"
+	EMR_EBS_CONFIG                        string = "ebs_config"
+	EMR_SIZE                              string = "size"
+	EMR_TYPE                              string = "type"
+	EMR_IOPS                              string = "iops"
+	EMR_VOLUMES_PER_INSTANCE              string = "volumes_per_instance"
+	EMR_TARGET_ON_DEMAND_CAPACITY         string = "target_on_demand_capacity"
+	EMR_TARGET_SPOT_CAPACITY              string = "target_spot_capacity"
+	EMR_INSTANCE_TYPE_CONFIGS             string = "instance_type_configs"
+	EMR_WEIGHTED_CAPACITY                 string = "weighted_capacity"
+	EMR_BID_PRICE                         string = "bid_price"
+	EMR_BOOTSTRAP_ACTION                  string = "bootstrap_action"
+	EMR_PATH                              string = "path"
+	EMR_ARGS                              string = "args"
+	EMR_STEP                              string = "step"
+	EMR_ACTION_ON_FAILURE                 string = "action_on_failure"
+	EMR_HADOOP_JAR_STEP                   string = "hadoop_jar_step"
+	EMR_JAR                               string = "jar"
+	EMR_MAIN_CLASS                        string = "main_class"
+	EMR_PROPERTIES                        string = "properties"
+	EMR_CLUSTER_ID                        string = "cluster_id"
+)
+
+const AWS_EMR_CLUSTER = "aws_emr_cluster"
+const AWS_EMR_INSTANCE_GROUP = "aws_emr_instance_group"
+const AWS_EMR_INSTANCE_FLEET = "aws_emr_instance_fleet"
+const EMR_FILE_NAME_PREFIX = "aws-emr-"
+
+type AwsEmrCluster struct {
+}
+
+func (awsEmrCluster *AwsEmrCluster) Generate(config *common.Config, client *duplosdk.Client) (*common.TFContext, error) {
+	workingDir := filepath.Join(config.TFCodePath, config.TenantProject)
+	list, clientErr := client.DuploEmrClusterGetList(config.TenantId)
+
+	if clientErr != nil {
+		fmt.Println(clientErr)
+		return nil, clientErr
+	}
+	tfContext := common.TFContext{}
+	importConfigs := []common.ImportConfig{}
+	if list != nil && len(*list) > 0 {
+		log.Println("[TRACE] <====== EMR cluster TF generation started. =====>")
+		emrClient := emr.NewFromConfig(config.AwsClientConfig)
+		for _, summary := range *list {
+			// Only long running clusters can be managed, terminated ones are history.
+			if strings.HasPrefix(summary.Status, "TERMINAT") {
+				continue
+			}
+			emrCluster, clientErr := client.DuploEmrClusterGet(config.TenantId, summary.Name)
+			if clientErr != nil {
+				fmt.Println(clientErr)
+				return &tfContext, clientErr
+			}
+			clusterId := emrCluster.JobFlowID
+			if len(clusterId) == 0 {
+				clusterId = summary.JobFlowId
+			}
+			describeClusterOutput, err := emrClient.DescribeCluster(context.TODO(), &emr.DescribeClusterInput{ClusterId: &clusterId})
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, err
+			}
+			cluster := describeClusterOutput.Cluster
+			shortName := strings.TrimPrefix(emrCluster.Name, "duploservices-"+config.TenantName+"-")
+			resourceName := common.GetResourceName(shortName)
+
+			hclFile := hclwrite.NewEmptyFile()
+			path := filepath.Join(workingDir, EMR_FILE_NAME_PREFIX+shortName+".tf")
+			tfFile, err := os.Create(path)
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			rootBody := hclFile.Body()
+
+			// Add aws_emr_cluster resource
+			emrBlock := rootBody.AppendNewBlock("resource",
+				[]string{AWS_EMR_CLUSTER,
+					resourceName})
+			emrBody := emrBlock.Body()
+			clusterName := common.Interpolate(emrCluster.Name)
+			if shortName != emrCluster.Name {
+				clusterName = common.Template("${local.tenant_prefix}-" + common.EscapeTemplate(shortName))
+			}
+			if err := common.SetAttributeTemplate(emrBody, EMR_NAME, clusterName); err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			emrBody.SetAttributeValue(EMR_RELEASE_LABEL,
+				cty.StringVal(emrCluster.ReleaseLabel))
+			if len(emrCluster.Applications) > 0 {
+				var vals []cty.Value
+				for _, application := range emrCluster.Applications {
+					vals = append(vals, cty.StringVal(application.Name))
+				}
+				emrBody.SetAttributeValue(EMR_APPLICATIONS,
+					cty.SetVal(vals))
+			}
+			if len(emrCluster.LogURI) > 0 {
+				emrBody.SetAttributeValue(EMR_LOG_URI,
+					cty.StringVal(emrCluster.LogURI))
+			}
+			// EMR takes either the name or the ARN of a role.
+			if cluster.ServiceRole != nil && len(*cluster.ServiceRole) > 0 {
+				emrBody.SetAttributeValue(EMR_SERVICE_ROLE,
+					cty.StringVal(*cluster.ServiceRole))
+			}
+			if cluster.AutoScalingRole != nil && len(*cluster.AutoScalingRole) > 0 {
+				emrBody.SetAttributeValue(EMR_AUTOSCALING_ROLE,
+					cty.StringVal(*cluster.AutoScalingRole))
+			}
+			emrBody.SetAttributeValue(EMR_TERMINATION_PROTECTION,
+				cty.BoolVal(emrCluster.TerminationProtection))
+			emrBody.SetAttributeValue(EMR_KEEP_JOB_FLOW_ALIVE_WHEN_NO_STEPS,
+				cty.BoolVal(emrCluster.KeepJobFlowAliveWhenNoSteps))
+			if emrCluster.StepConcurrencyLevel > 0 {
+				emrBody.SetAttributeValue(EMR_STEP_CONCURRENCY_LEVEL,
+					cty.NumberIntVal(int64(emrCluster.StepConcurrencyLevel)))
+			}
+			if emrCluster.EbsRootVolumeSize > 0 {
+				emrBody.SetAttributeValue(EMR_EBS_ROOT_VOLUME_SIZE,
+					cty.NumberIntVal(int64(emrCluster.EbsRootVolumeSize)))
+			}
+			if len(emrCluster.CustomAmiId) > 0 {
+				emrBody.SetAttributeValue(EMR_CUSTOM_AMI_ID,
+					cty.StringVal(emrCluster.CustomAmiId))
+			}
+			if len(emrCluster.ScaleDownBehavior) > 0 {
+				emrBody.SetAttributeValue(EMR_SCALE_DOWN_BEHAVIOR,
+					cty.StringVal(emrCluster.ScaleDownBehavior))
+			}
+			if len(emrCluster.AdditionalInfo) > 0 {
+				emrBody.SetAttributeValue(EMR_ADDITIONAL_INFO,
+					cty.StringVal(emrCluster.AdditionalInfo))
+			}
+			if len(cluster.Configurations) > 0 {
+				if err := common.SetAttributeJsonencode(emrBody, EMR_CONFIGURATIONS_JSON, getEmrConfigurations(cluster.Configurations)); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+			}
+
+			metaData := emrCluster.MetaDataObject
+			ec2AttributesBlock := emrBody.AppendNewBlock(EMR_EC2_ATTRIBUTES,
+				nil)
+			ec2AttributesBody := ec2AttributesBlock.Body()
+			ec2AttributesBody.SetAttributeValue(EMR_SUBNET_ID,
+				cty.StringVal(metaData.Ec2SubnetID))
+			if len(metaData.Ec2KeyName) > 0 {
+				ec2AttributesBody.SetAttributeValue(EMR_KEY_NAME,
+					cty.StringVal(metaData.Ec2KeyName))
+			}
+			ec2AttributesBody.SetAttributeValue(EMR_INSTANCE_PROFILE,
+				cty.StringVal(metaData.IamInstanceProfile))
+			if len(metaData.EmrManagedMasterSecurityGroup) > 0 {
+				ec2AttributesBody.SetAttributeValue(EMR_MANAGED_MASTER_SECURITY_GROUP,
+					cty.StringVal(metaData.EmrManagedMasterSecurityGroup))
+			}
+			if len(metaData.EmrManagedSlaveSecurityGroup) > 0 {
+				ec2AttributesBody.SetAttributeValue(EMR_MANAGED_SLAVE_SECURITY_GROUP,
+					cty.StringVal(metaData.EmrManagedSlaveSecurityGroup))
+			}
+			if len(metaData.ServiceAccessSecurityGroup) > 0 {
+				ec2AttributesBody.SetAttributeValue(EMR_SERVICE_ACCESS_SECURITY_GROUP,
+					cty.StringVal(metaData.ServiceAccessSecurityGroup))
+			}
+			if len(metaData.AdditionalMasterSecurityGroups) > 0 {
+				ec2AttributesBody.SetAttributeValue(EMR_ADDITIONAL_MASTER_SECURITY_GROUPS,
+					cty.StringVal(strings.Join(metaData.AdditionalMasterSecurityGroups, ",")))
+			}
+			if len(metaData.AdditionalSlaveSecurityGroups) > 0 {
+				ec2AttributesBody.SetAttributeValue(EMR_ADDITIONAL_SLAVE_SECURITY_GROUPS,
+					cty.StringVal(strings.Join(metaData.AdditionalSlaveSecurityGroups, ",")))
+			}
+
+			// Master and core capacity belongs to the cluster, task capacity is generated as separate resources below.
+			taskGroupIds := []string{}
+			for _, instanceGroup := range emrCluster.InstanceGroups {
+				var groupBody *hclwrite.Body
+				switch instanceGroup.InstanceGroupType.Value {
+				case "MASTER":
+					groupBody = emrBody.AppendNewBlock(EMR_MASTER_INSTANCE_GROUP, nil).Body()
+				case "CORE":
+					groupBody = emrBody.AppendNewBlock(EMR_CORE_INSTANCE_GROUP, nil).Body()
+				default:
+					taskGroupIds = append(taskGroupIds, instanceGroup.ID)
+					continue
+				}
+				if len(instanceGroup.Name) > 0 {
+					groupBody.SetAttributeValue(EMR_NAME,
+						cty.StringVal(instanceGroup.Name))
+				}
+				groupBody.SetAttributeValue(EMR_INSTANCE_TYPE,
+					cty.StringVal(instanceGroup.InstanceType))
+				groupBody.SetAttributeValue(EMR_INSTANCE_COUNT,
+					cty.NumberIntVal(int64(instanceGroup.RequestedInstanceCount)))
+				appendEmrEbsConfigBlocks(groupBody, instanceGroup.EbsBlockDevices)
+			}
+
+			taskFleets := []types.InstanceFleet{}
+			if cluster.InstanceCollectionType == types.InstanceCollectionTypeInstanceFleet {
+				listInstanceFleetsOutput, err := emrClient.ListInstanceFleets(context.TODO(), &emr.ListInstanceFleetsInput{ClusterId: &clusterId})
+				if err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				for _, instanceFleet := range listInstanceFleetsOutput.InstanceFleets {
+					switch instanceFleet.InstanceFleetType {
+					case types.InstanceFleetTypeMaster:
+						appendEmrInstanceFleet(emrBody.AppendNewBlock(EMR_MASTER_INSTANCE_FLEET, nil).Body(), instanceFleet)
+					case types.InstanceFleetTypeCore:
+						appendEmrInstanceFleet(emrBody.AppendNewBlock(EMR_CORE_INSTANCE_FLEET, nil).Body(), instanceFleet)
+					default:
+						taskFleets = append(taskFleets, instanceFleet)
+					}
+				}
+			}
+
+			bootstrapActions := []types.Command{}
+			bootstrapActionsPaginator := emr.NewListBootstrapActionsPaginator(emrClient, &emr.ListBootstrapActionsInput{ClusterId: &clusterId})
+			for bootstrapActionsPaginator.HasMorePages() {
+				listBootstrapActionsOutput, err := bootstrapActionsPaginator.NextPage(context.TODO())
+				if err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				bootstrapActions = append(bootstrapActions, listBootstrapActionsOutput.BootstrapActions...)
+			}
+			for _, command := range bootstrapActions {
+				bootstrapBlock := emrBody.AppendNewBlock(EMR_BOOTSTRAP_ACTION,
+					nil)
+				bootstrapBody := bootstrapBlock.Body()
+				bootstrapBody.SetAttributeValue(EMR_NAME,
+					cty.StringVal(*command.Name))
+				bootstrapBody.SetAttributeValue(EMR_PATH,
+					cty.StringVal(*command.ScriptPath))
+				if len(command.Args) > 0 {
+					var vals []cty.Value
+					for _, arg := range command.Args {
+						vals = append(vals, cty.StringVal(arg))
+					}
+					bootstrapBody.SetAttributeValue(EMR_ARGS,
+						cty.ListVal(vals))
+				}
+			}
+
+			steps := []types.StepSummary{}
+			stepsPaginator := emr.NewListStepsPaginator(emrClient, &emr.ListStepsInput{ClusterId: &clusterId})
+			for stepsPaginator.HasMorePages() {
+				listStepsOutput, err := stepsPaginator.NextPage(context.TODO())
+				if err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				steps = append(steps, listStepsOutput.Steps...)
+			}
+			// Steps are listed newest first, terraform expects them in submission order.
+			for i := len(steps) - 1; i >= 0; i-- {
+				step := steps[i]
+				if step.Config == nil {
+					continue
+				}
+				stepBlock := emrBody.AppendNewBlock(EMR_STEP,
+					nil)
+				stepBody := stepBlock.Body()
+				stepBody.SetAttributeValue(EMR_ACTION_ON_FAILURE,
+					cty.StringVal(string(step.ActionOnFailure)))
+				stepBody.SetAttributeValue(EMR_NAME,
+					cty.StringVal(*step.Name))
+				jarStepBlock := stepBody.AppendNewBlock(EMR_HADOOP_JAR_STEP,
+					nil)
+				jarStepBody := jarStepBlock.Body()
+				jarStepBody.SetAttributeValue(EMR_JAR,
+					cty.StringVal(*step.Config.Jar))
+				if step.Config.MainClass != nil && len(*step.Config.MainClass) > 0 {
+					jarStepBody.SetAttributeValue(EMR_MAIN_CLASS,
+						cty.StringVal(*step.Config.MainClass))
+				}
+				if len(step.Config.Args) > 0 {
+					var vals []cty.Value
+					for _, arg := range step.Config.Args {
+						vals = append(vals, cty.StringVal(arg))
+					}
+					jarStepBody.SetAttributeValue(EMR_ARGS,
+						cty.ListVal(vals))
+				}
+				if len(step.Config.Properties) > 0 {
+					properties := map[string]cty.Value{}
+					for key, value := range step.Config.Properties {
+						properties[key] = cty.StringVal(value)
+					}
+					jarStepBody.SetAttributeValue(EMR_PROPERTIES,
+						cty.MapVal(properties))
+				}
+			}
+
+			if len(cluster.Tags) > 0 {
+				tags := map[string]common.Template{}
+				for _, tag := range cluster.Tags {
+					if common.IsTagAwsManaged(*tag.Key) {
+						continue
+					}
+					tagValue := common.Interpolate(*tag.Value, common.Interpolation{Value: config.TenantName, Expr: "local.tenant_name"})
+					tags[*tag.Key] = tagValue
+				}
+				if err := common.SetAttributeTags(emrBody, TAGS, tags); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+			}
+
+			// Steps run once at creation and keep being added to a running cluster, they would force a new cluster otherwise.
+			if err := common.AppendLifecycleBlock(emrBody, common.Lifecycle{
+				IgnoreChanges: []string{EMR_STEP},
+			}); err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+
+			if config.GenerateTfState {
+				importConfigs = append(importConfigs, common.ImportConfig{
+					ResourceAddress: strings.Join([]string{
+						AWS_EMR_CLUSTER,
+						resourceName,
+					}, "."),
+					ResourceId: clusterId,
+					WorkingDir: workingDir,
+				})
+				tfContext.ImportConfigs = importConfigs
+			}
+
+			// Add aws_emr_instance_group resources for task groups
+			for _, instanceGroup := range emrCluster.InstanceGroups {
+				if !common.Contains(taskGroupIds, instanceGroup.ID) {
+					continue
+				}
+				groupResourceName := resourceName + "_" + common.GetResourceName(instanceGroup.Name)
+				rootBody.AppendNewline()
+				groupBlock := rootBody.AppendNewBlock("resource",
+					[]string{AWS_EMR_INSTANCE_GROUP,
+						groupResourceName})
+				groupBody := groupBlock.Body()
+				if err := setEmrClusterIdReference(groupBody, resourceName); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				groupBody.SetAttributeValue(EMR_NAME,
+					cty.StringVal(instanceGroup.Name))
+				groupBody.SetAttributeValue(EMR_INSTANCE_TYPE,
+					cty.StringVal(instanceGroup.InstanceType))
+				groupBody.SetAttributeValue(EMR_INSTANCE_COUNT,
+					cty.NumberIntVal(int64(instanceGroup.RequestedInstanceCount)))
+				appendEmrEbsConfigBlocks(groupBody, instanceGroup.EbsBlockDevices)
+				if config.GenerateTfState {
+					importConfigs = append(importConfigs, common.ImportConfig{
+						ResourceAddress: strings.Join([]string{
+							AWS_EMR_INSTANCE_GROUP,
+							groupResourceName,
+						}, "."),
+						ResourceId: clusterId + "/" + instanceGroup.ID,
+						WorkingDir: workingDir,
+					})
+					tfContext.ImportConfigs = importConfigs
+				}
+			}
+
+			// Add aws_emr_instance_fleet resources for task fleets
+			for _, instanceFleet := range taskFleets {
+				fleetResourceName := resourceName + "_" + common.GetResourceName(*instanceFleet.Name)
+				rootBody.AppendNewline()
+				fleetBlock := rootBody.AppendNewBlock("resource",
+					[]string{AWS_EMR_INSTANCE_FLEET,
+						fleetResourceName})
+				fleetBody := fleetBlock.Body()
+				if err := setEmrClusterIdReference(fleetBody, resourceName); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				appendEmrInstanceFleet(fleetBody, instanceFleet)
+				if config.GenerateTfState {
+					importConfigs = append(importConfigs, common.ImportConfig{
+						ResourceAddress: strings.Join([]string{
+							AWS_EMR_INSTANCE_FLEET,
+							fleetResourceName,
+						}, "."),
+						ResourceId: clusterId + "/" + *instanceFleet.Id,
+						WorkingDir: workingDir,
+					})
+					tfContext.ImportConfigs = importConfigs
+				}
+			}
+
+			_, err = tfFile.Write(hclFile.Bytes())
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			log.Printf("[TRACE] Terraform config is generated for emr cluster : %s", shortName)
+		}
+		log.Println("[TRACE] <====== EMR cluster TF generation done. =====>")
+	}
+	return &tfContext, nil
+}
+
+func setEmrClusterIdReference(body *hclwrite.Body, resourceName string) error {
+	return common.SetAttributeReference(body, EMR_CLUSTER_ID, AWS_EMR_CLUSTER+"."+resourceName+".id")
+}
+
+func appendEmrEbsConfigBlocks(body *hclwrite.Body, ebsBlockDevices []interface{}) {
+	for _, device := range ebsBlockDevices {
+		deviceMap, ok := device.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		volumeSpec, ok := deviceMap["VolumeSpecification"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		ebsBody := body.AppendNewBlock(EMR_EBS_CONFIG, nil).Body()
+		if size, ok := volumeSpec["SizeInGB"].(float64); ok {
+			ebsBody.SetAttributeValue(EMR_SIZE,
+				cty.NumberIntVal(int64(size)))
+		}
+		if volumeType, ok := volumeSpec["VolumeType"].(string); ok {
+			ebsBody.SetAttributeValue(EMR_TYPE,
+				cty.StringVal(volumeType))
+		}
+		if iops, ok := volumeSpec["Iops"].(float64); ok && iops > 0 {
+			ebsBody.SetAttributeValue(EMR_IOPS,
+				cty.NumberIntVal(int64(iops)))
+		}
+		ebsBody.SetAttributeValue(EMR_VOLUMES_PER_INSTANCE,
+			cty.NumberIntVal(1))
+	}
+}
+
+func appendEmrInstanceFleet(body *hclwrite.Body, instanceFleet types.InstanceFleet) {
+	if instanceFleet.Name != nil {
+		body.SetAttributeValue(EMR_NAME,
+			cty.StringVal(*instanceFleet.Name))
+	}
+	if instanceFleet.TargetOnDemandCapacity != nil && *instanceFleet.TargetOnDemandCapacity > 0 {
+		body.SetAttributeValue(EMR_TARGET_ON_DEMAND_CAPACITY,
+			cty.NumberIntVal(int64(*instanceFleet.TargetOnDemandCapacity)))
+	}
+	if instanceFleet.TargetSpotCapacity != nil && *instanceFleet.TargetSpotCapacity > 0 {
+		body.SetAttributeValue(EMR_TARGET_SPOT_CAPACITY,
+			cty.NumberIntVal(int64(*instanceFleet.TargetSpotCapacity)))
+	}
+	for _, spec := range instanceFleet.InstanceTypeSpecifications {
+		specBody := body.AppendNewBlock(EMR_INSTANCE_TYPE_CONFIGS, nil).Body()
+		specBody.SetAttributeValue(EMR_INSTANCE_TYPE,
+			cty.StringVal(*spec.InstanceType))
+		if spec.WeightedCapacity != nil {
+			specBody.SetAttributeValue(EMR_WEIGHTED_CAPACITY,
+				cty.NumberIntVal(int64(*spec.WeightedCapacity)))
+		}
+		if spec.BidPrice != nil && len(*spec.BidPrice) > 0 {
+			specBody.SetAttributeValue(EMR_BID_PRICE,
+				cty.StringVal(*spec.BidPrice))
+		}
+		for _, ebsBlockDevice := range spec.EbsBlockDevices {
+			if ebsBlockDevice.VolumeSpecification == nil {
+				continue
+			}
+			ebsBody := specBody.AppendNewBlock(EMR_EBS_CONFIG, nil).Body()
+			if ebsBlockDevice.VolumeSpecification.SizeInGB != nil {
+				ebsBody.SetAttributeValue(EMR_SIZE,
+					cty.NumberIntVal(int64(*ebsBlockDevice.VolumeSpecification.SizeInGB)))
+			}
+			if ebsBlockDevice.VolumeSpecification.VolumeType != nil {
+				ebsBody.SetAttributeValue(EMR_TYPE,
+					cty.StringVal(*ebsBlockDevice.VolumeSpecification.VolumeType))
+			}
+			if ebsBlockDevice.VolumeSpecification.Iops != nil {
+				ebsBody.SetAttributeValue(EMR_IOPS,
+					cty.NumberIntVal(int64(*ebsBlockDevice.VolumeSpecification.Iops)))
+			}
+			ebsBody.SetAttributeValue(EMR_VOLUMES_PER_INSTANCE,
+				cty.NumberIntVal(1))
+		}
+	}
+}
+
+// getEmrConfigurations converts the EMR configurations into the shape expected by configurations_json.
+func getEmrConfigurations(configurations []types.Configuration) []map[string]interface{} {
+	result := []map[string]interface{}{}
+	for _, configuration := range configurations {
+		configurationMap := map[string]interface{}{}
+		if configuration.Classification != nil {
+			configurationMap["Classification"] = *configuration.Classification
+		}
+		if len(configuration.Properties) > 0 {
+			configurationMap["Properties"] = configuration.Properties
+		}
+		if len(configuration.Configurations) > 0 {
+			configurationMap["Configurations"] = getEmrConfigurations(configuration.Configurations)
+		}
+		result = append(result, configurationMap)
+	}
+	return result
+}
@@ -8,8 +8,6 @@ import (
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
-	"github.com/hashicorp/hcl/v2"
-	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/zclconf/go-cty/cty"
 
@@ -62,7 +60,7 @@ func (ec2Instance *AwsInstance) Generate(config *common.Config, client *duplosdk
 
 	if clientErr != nil {
 		fmt.Println(clientErr)
-		return nil, nil
+		return nil, clientErr
 	}
 	tfContext := common.TFContext{}
 	importConfigs := []common.ImportConfig{}
@@ -83,7 +81,7 @@ func (ec2Instance *AwsInstance) Generate(config *common.Config, client *duplosdk
 			resp, err := ec2Client.DescribeInstances(context.TODO(), &ec2.DescribeInstancesInput{InstanceIds: instanceIds})
 			if err != nil {
 				fmt.Println(err)
-				return nil, err
+				return &tfContext, err
 			}
 			b, err := json.Marshal(resp)
 			if err != nil {
@@ -111,7 +109,7 @@ func (ec2Instance *AwsInstance) Generate(config *common.Config, client *duplosdk
 							tfFile, err := os.Create(path)
 							if err != nil {
 								fmt.Println(err)
-								return nil, err
+								return &tfContext, common.NewResourceError(resourceName, err)
 							}
 							// initialize the body of the new file object
 							rootBody := hclFile.Body()
@@ -121,39 +119,24 @@ func (ec2Instance *AwsInstance) Generate(config *common.Config, client *duplosdk
 								[]string{AWS_INSTANCE,
 									resourceName})
 							ec2Body := ec2Block.Body()
-							ec2Body.SetAttributeTraversal(AMI, hcl.Traversal{
-								hcl.TraverseRoot{
-									Name: "var",
-								},
-								hcl.TraverseAttr{
-									Name: varFullPrefix + "ami",
-								},
-							})
-							ec2Body.SetAttributeTraversal(INSTANCE_TYPE, hcl.Traversal{
-								hcl.TraverseRoot{
-									Name: "var",
-								},
-								hcl.TraverseAttr{
-									Name: varFullPrefix + "instance_type",
-								},
+							tfContext.References = append(tfContext.References, common.ResourceReference{
+								Value:   *instance.InstanceId,
+								Address: AWS_INSTANCE + "." + resourceName + ".id",
 							})
+							if err := common.SetAttributeReference(ec2Body, AMI, "var."+varFullPrefix+"ami"); err != nil {
+								fmt.Println(err)
+								return &tfContext, common.NewResourceError(resourceName, err)
+							}
+							if err := common.SetAttributeReference(ec2Body, INSTANCE_TYPE, "var."+varFullPrefix+"instance_type"); err != nil {
+								fmt.Println(err)
+								return &tfContext, common.NewResourceError(resourceName, err)
+							}
 							ec2Body.SetAttributeValue(AVAILABILITY_ZONE,
 								cty.StringVal(*instance.Placement.AvailabilityZone))
 							if instance.IamInstanceProfile != nil && instance.IamInstanceProfile.Arn != nil {
-								roleName := strings.SplitN(*instance.IamInstanceProfile.Arn, ":instance-profile/", 2)[1]
-								if "duploservices-"+config.TenantName == roleName {
-									ec2Body.SetAttributeTraversal(IAM_INSTANCE_PROFILE, hcl.Traversal{
-										hcl.TraverseRoot{
-											Name: AWS_IAM_ROLE + "." + TENANT_IAM,
-										},
-										hcl.TraverseAttr{
-											Name: "name",
-										},
-									})
-								} else {
-									ec2Body.SetAttributeValue(IAM_INSTANCE_PROFILE,
-										cty.StringVal(roleName))
-								}
+								profileName := strings.SplitN(*instance.IamInstanceProfile.Arn, ":instance-profile/", 2)[1]
+								ec2Body.SetAttributeValue(IAM_INSTANCE_PROFILE,
+									cty.StringVal(profileName))
 							}
 
 							ec2Body.SetAttributeValue(AVAILABILITY_ZONE,
@@ -177,19 +160,8 @@ func (ec2Instance *AwsInstance) Generate(config *common.Config, client *duplosdk
 									cty.StringVal(*instance.SubnetId))
 							}
 							if instance.KeyName != nil {
-								if "duploservices-"+config.TenantName == *instance.KeyName {
-									ec2Body.SetAttributeTraversal(KEY_NAME, hcl.Traversal{
-										hcl.TraverseRoot{
-											Name: AWS_KEY_PAIR + ".tenant_keypair",
-										},
-										hcl.TraverseAttr{
-											Name: "key_name",
-										},
-									})
-								} else {
-									ec2Body.SetAttributeValue(KEY_NAME,
-										cty.StringVal(*instance.KeyName))
-								}
+								ec2Body.SetAttributeValue(KEY_NAME,
+									cty.StringVal(*instance.KeyName))
 							}
 							if instance.EbsOptimized != nil && *instance.EbsOptimized {
 								ec2Body.SetAttributeValue(EBS_OPTIMIZED,
@@ -197,22 +169,18 @@ func (ec2Instance *AwsInstance) Generate(config *common.Config, client *duplosdk
 							}
 
 							if len(instance.Tags) > 0 {
-								tagsTokens := hclwrite.Tokens{
-									{Type: hclsyntax.TokenOQuote, Bytes: []byte(`{`)},
-									{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")},
-								}
+								tags := map[string]common.Template{}
 								for _, tag := range instance.Tags {
 									if common.IsTagAwsManaged(*tag.Key) {
 										continue
 									}
-									tagValue := strings.Replace(*tag.Value, config.TenantName, "${local.tenant_name}", -1)
-									tag := "\"" + *tag.Key + "\"" + " = \"" + tagValue + "\"\n"
-									tagsTokens = append(tagsTokens,
-										&hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(tag)},
-									)
+									tagValue := common.Interpolate(*tag.Value, common.Interpolation{Value: config.TenantName, Expr: "local.tenant_name"})
+									tags[*tag.Key] = tagValue
+								}
+								if err := common.SetAttributeTags(ec2Body, TAGS, tags); err != nil {
+									fmt.Println(err)
+									return &tfContext, common.NewResourceError(resourceName, err)
 								}
-								tagsTokens = append(tagsTokens, &hclwrite.Token{Type: hclsyntax.TokenCQuote, Bytes: []byte(`}`)})
-								ec2Body.SetAttributeRaw(TAGS, tagsTokens)
 							}
 
 							instanceAttributeOutput, err := ec2Client.DescribeInstanceAttribute(context.TODO(), &ec2.DescribeInstanceAttributeInput{
@@ -241,7 +209,7 @@ func (ec2Instance *AwsInstance) Generate(config *common.Config, client *duplosdk
 								volumesOutput, err := ec2Client.DescribeVolumes(context.TODO(), &ec2.DescribeVolumesInput{VolumeIds: volIds})
 								if err != nil {
 									fmt.Println(err)
-									return nil, err
+									return &tfContext, common.NewResourceError(resourceName, err)
 								}
 								if volumesOutput != nil && len(volumesOutput.Volumes) > 0 {
 									for _, vol := range volumesOutput.Volumes {
@@ -308,22 +276,14 @@ func (ec2Instance *AwsInstance) Generate(config *common.Config, client *duplosdk
 										ebsVolAttachBody.SetAttributeValue(DEVICE_NAME,
 											cty.StringVal(volIdDevice[*vol.VolumeId]))
 
-										ebsVolAttachBody.SetAttributeTraversal(VOLUME_ID, hcl.Traversal{
-											hcl.TraverseRoot{
-												Name: AWS_EBS_VOLUME + "." + resourceName + "_ebs_vol",
-											},
-											hcl.TraverseAttr{
-												Name: "id",
-											},
-										})
-										ebsVolAttachBody.SetAttributeTraversal(INSTANCE_ID, hcl.Traversal{
-											hcl.TraverseRoot{
-												Name: AWS_INSTANCE + "." + resourceName,
-											},
-											hcl.TraverseAttr{
-												Name: "id",
-											},
-										})
+										if err := common.SetAttributeReference(ebsVolAttachBody, VOLUME_ID, AWS_EBS_VOLUME+"."+resourceName+"_ebs_vol.id"); err != nil {
+											fmt.Println(err)
+											return &tfContext, common.NewResourceError(resourceName, err)
+										}
+										if err := common.SetAttributeReference(ebsVolAttachBody, INSTANCE_ID, AWS_INSTANCE+"."+resourceName+".id"); err != nil {
+											fmt.Println(err)
+											return &tfContext, common.NewResourceError(resourceName, err)
+										}
 
 										if config.GenerateTfState {
 											importConfigs = append(importConfigs, common.ImportConfig{
@@ -344,19 +304,16 @@ func (ec2Instance *AwsInstance) Generate(config *common.Config, client *duplosdk
 									}
 								}
 							}
-							lifecycleBlock := ec2Body.AppendNewBlock("lifecycle", nil)
-							lifecycleBody := lifecycleBlock.Body()
-							ignoreChanges := "user_data,user_data_base64,user_data_replace_on_change"
-							ignoreChangesTokens := hclwrite.Tokens{
-								{Type: hclsyntax.TokenOQuote, Bytes: []byte(`[`)},
-								{Type: hclsyntax.TokenIdent, Bytes: []byte(ignoreChanges)},
-								{Type: hclsyntax.TokenCQuote, Bytes: []byte(`]`)},
+							if err := common.AppendLifecycleBlock(ec2Body, common.Lifecycle{
+								IgnoreChanges: []string{"user_data", "user_data_base64", "user_data_replace_on_change"},
+							}); err != nil {
+								fmt.Println(err)
+								return &tfContext, common.NewResourceError(resourceName, err)
 							}
-							lifecycleBody.SetAttributeRaw("ignore_changes", ignoreChangesTokens)
 							_, err = tfFile.Write(hclFile.Bytes())
 							if err != nil {
 								fmt.Println(err)
-								return nil, err
+								return &tfContext, common.NewResourceError(resourceName, err)
 							}
 							log.Printf("[TRACE] Terraform config is generated for ec2 instance : %s", shortName)
 
@@ -385,6 +342,27 @@ func (ec2Instance *AwsInstance) Generate(config *common.Config, client *duplosdk
 	return &tfContext, nil
 }
 
+// getAwsInstanceResourceNames maps the instance IDs of the hosts generated as aws_instance to their resource names,
+// and lists the instances of autoscaling groups.
+func getAwsInstanceResourceNames(config *common.Config, hosts *[]duplosdk.DuploNativeHost) (map[string]string, []string) {
+	resourceNames := map[string]string{}
+	asgInstanceIds := []string{}
+	if hosts == nil {
+		return resourceNames, asgInstanceIds
+	}
+	prefix := "duploservices-" + config.TenantName + "-"
+	for _, host := range *hosts {
+		if isPartOfAsg(host) {
+			asgInstanceIds = append(asgInstanceIds, host.InstanceID)
+			continue
+		}
+		if strings.HasPrefix(host.FriendlyName, prefix) {
+			resourceNames[host.InstanceID] = common.GetResourceName(host.FriendlyName[len(prefix):])
+		}
+	}
+	return resourceNames, asgInstanceIds
+}
+
 func isPartOfAsg(host duplosdk.DuploNativeHost) bool {
 	asgTagKey := []string{"aws:autoscaling:groupName"}
 	if host.Tags != nil && len(*host.Tags) > 0 {
@@ -413,7 +391,7 @@ func generateEC2InstanceVars(instance types.Instance, prefix string) []common.Va
 	}
 	varConfigs["instance_type"] = capacityVar
 
-	vars := make([]common.VarConfig, len(varConfigs))
+	vars := make([]common.VarConfig, 0, len(varConfigs))
 	for _, v := range varConfigs {
 		vars = append(vars, v)
 	}
@@ -446,7 +424,7 @@ func generateEC2InstanceOutputVars(prefix, resourceName string) []common.OutputV
 	}
 	outVarConfigs["public_ip"] = var2
 
-	outVars := make([]common.OutputVarConfig, len(outVarConfigs))
+	outVars := make([]common.OutputVarConfig, 0, len(outVarConfigs))
 	for _, v := range outVarConfigs {
 		outVars = append(outVars, v)
 	}
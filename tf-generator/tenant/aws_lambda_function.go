@@ -0,0 +1,380 @@
+package tenant
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"tenant-native-terraform-generator/duplosdk"
+	"tenant-native-terraform-generator/tf-generator/common"
+)
+
+const (
+	LAMBDA_FUNCTION_NAME      string = "function_name"
+	LAMBDA_DESCRIPTION        string = "description"
+	LAMBDA_ROLE               string = "role"
+	LAMBDA_PACKAGE_TYPE       string = "package_type"
+	LAMBDA_RUNTIME            string = "runtime"
+	LAMBDA_HANDLER            string = "handler"
+	LAMBDA_MEMORY_SIZE        string = "memory_size"
+	LAMBDA_TIMEOUT            string = "timeout"
+	LAMBDA_IMAGE_URI          string = "image_uri"
+	LAMBDA_S3_BUCKET          string = "s3_bucket"
+	LAMBDA_S3_KEY             string = "s3_key"
+	LAMBDA_LAYERS             string = "layers"
+	LAMBDA_ENVIRONMENT        string = "environment"
+	LAMBDA_VARIABLES          string = "variables"
+	LAMBDA_VPC_CONFIG         string = "vpc_config"
+	LAMBDA_SUBNET_IDS         string = "subnet_ids"
+	LAMBDA_SECURITY_GROUP_IDS string = "security_group_ids"
+	LAMBDA_TRACING_CONFIG     string = "tracing_config"
+	LAMBDA_MODE               string = "mode"
+	LAMBDA_STATEMENT_ID       string = "statement_id"
+	LAMBDA_ACTION             string = "action"
+	LAMBDA_PRINCIPAL          string = "principal"
+	LAMBDA_SOURCE_ARN         string = "source_arn"
+	LAMBDA_SOURCE_ACCOUNT     string = "source_account"
+	LAMBDA_EVENT_SOURCE_TOKEN string = "event_source_token"
+)
+
+const AWS_LAMBDA_FUNCTION = "aws_lambda_function"
+const AWS_LAMBDA_PERMISSION = "aws_lambda_permission"
+const LAMBDA_VAR_PREFIX = "lambda_"
+const LAMBDA_FILE_NAME_PREFIX = "aws-lambda-"
+
+type AwsLambdaFunction struct {
+}
+
+func (awsLambdaFunction *AwsLambdaFunction) Generate(config *common.Config, client *duplosdk.Client) (*common.TFContext, error) {
+	workingDir := filepath.Join(config.TFCodePath, config.TenantProject)
+	list, clientErr := client.LambdaFunctionGetList(config.TenantId)
+
+	if clientErr != nil {
+		fmt.Println(clientErr)
+		return nil, clientErr
+	}
+	tfContext := common.TFContext{}
+	importConfigs := []common.ImportConfig{}
+	if list != nil && len(*list) > 0 {
+		log.Println("[TRACE] <====== Lambda function TF generation started. =====>")
+		for _, lambdaConfig := range *list {
+			lambda, clientErr := client.LambdaFunctionGet(config.TenantId, lambdaConfig.FunctionName)
+			if clientErr != nil {
+				fmt.Println(clientErr)
+				return &tfContext, clientErr
+			}
+			lambdaFn := lambda.Configuration
+			if len(lambdaFn.FunctionName) == 0 {
+				lambdaFn = lambdaConfig
+			}
+			shortName := lambdaConfig.Name
+			resourceName := common.GetResourceName(shortName)
+			varFullPrefix := LAMBDA_VAR_PREFIX + resourceName + "_"
+
+			hclFile := hclwrite.NewEmptyFile()
+			path := filepath.Join(workingDir, LAMBDA_FILE_NAME_PREFIX+shortName+".tf")
+			tfFile, err := os.Create(path)
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			rootBody := hclFile.Body()
+
+			// Add aws_lambda_function resource
+			lambdaBlock := rootBody.AppendNewBlock("resource",
+				[]string{AWS_LAMBDA_FUNCTION,
+					resourceName})
+			lambdaBody := lambdaBlock.Body()
+			if len(lambdaFn.FunctionArn) > 0 {
+				tfContext.References = append(tfContext.References, common.ResourceReference{
+					Value:   lambdaFn.FunctionArn,
+					Address: AWS_LAMBDA_FUNCTION + "." + resourceName + ".arn",
+				})
+			}
+			if invokeArn := getLambdaInvokeArn(lambdaFn.FunctionArn); len(invokeArn) > 0 {
+				tfContext.References = append(tfContext.References, common.ResourceReference{
+					Value:      invokeArn,
+					Address:    AWS_LAMBDA_FUNCTION + "." + resourceName + ".invoke_arn",
+					Attributes: []string{APIGW_BODY},
+				})
+			}
+			functionName := common.Interpolate(lambdaFn.FunctionName)
+			if strings.HasPrefix(lambdaFn.FunctionName, "duploservices-"+config.TenantName+"-") {
+				functionName = common.Template("${local.tenant_prefix}-" + common.EscapeTemplate(lambdaFn.FunctionName[len("duploservices-"+config.TenantName+"-"):]))
+			}
+			if err := common.SetAttributeTemplate(lambdaBody, LAMBDA_FUNCTION_NAME, functionName); err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			if len(lambdaFn.Description) > 0 {
+				lambdaBody.SetAttributeValue(LAMBDA_DESCRIPTION,
+					cty.StringVal(lambdaFn.Description))
+			}
+			if len(lambdaFn.Role) > 0 {
+				lambdaBody.SetAttributeValue(LAMBDA_ROLE,
+					cty.StringVal(lambdaFn.Role))
+			}
+
+			// Code packages are exposed as input variables so the generated code does not pin a build.
+			isImage := lambdaFn.PackageType != nil && lambdaFn.PackageType.Value == "Image"
+			if isImage {
+				lambdaBody.SetAttributeValue(LAMBDA_PACKAGE_TYPE,
+					cty.StringVal("Image"))
+				if err := common.SetAttributeReference(lambdaBody, LAMBDA_IMAGE_URI, "var."+varFullPrefix+"image_uri"); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+			} else {
+				if err := common.SetAttributeReference(lambdaBody, LAMBDA_S3_BUCKET, "var."+varFullPrefix+"s3_bucket"); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				if err := common.SetAttributeReference(lambdaBody, LAMBDA_S3_KEY, "var."+varFullPrefix+"s3_key"); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				if lambdaFn.Runtime != nil && len(lambdaFn.Runtime.Value) > 0 {
+					lambdaBody.SetAttributeValue(LAMBDA_RUNTIME,
+						cty.StringVal(lambdaFn.Runtime.Value))
+				}
+				if len(lambdaFn.Handler) > 0 {
+					lambdaBody.SetAttributeValue(LAMBDA_HANDLER,
+						cty.StringVal(lambdaFn.Handler))
+				}
+			}
+			if lambdaFn.MemorySize > 0 {
+				lambdaBody.SetAttributeValue(LAMBDA_MEMORY_SIZE,
+					cty.NumberIntVal(int64(lambdaFn.MemorySize)))
+			}
+			if lambdaFn.Timeout > 0 {
+				lambdaBody.SetAttributeValue(LAMBDA_TIMEOUT,
+					cty.NumberIntVal(int64(lambdaFn.Timeout)))
+			}
+			if lambdaFn.Layers != nil && len(*lambdaFn.Layers) > 0 {
+				var vals []cty.Value
+				for _, layer := range *lambdaFn.Layers {
+					vals = append(vals, cty.StringVal(layer.Arn))
+				}
+				lambdaBody.SetAttributeValue(LAMBDA_LAYERS,
+					cty.ListVal(vals))
+			}
+			if lambdaFn.Environment != nil && len(lambdaFn.Environment.Variables) > 0 {
+				envBlock := lambdaBody.AppendNewBlock(LAMBDA_ENVIRONMENT,
+					nil)
+				newMap := make(map[string]cty.Value)
+				for key, val := range lambdaFn.Environment.Variables {
+					newMap[key] = cty.StringVal(val)
+				}
+				envBlock.Body().SetAttributeValue(LAMBDA_VARIABLES,
+					cty.MapVal(newMap))
+			}
+			if lambdaFn.VpcConfig != nil && len(lambdaFn.VpcConfig.SubnetIDs) > 0 {
+				vpcBlock := lambdaBody.AppendNewBlock(LAMBDA_VPC_CONFIG,
+					nil)
+				vpcBody := vpcBlock.Body()
+				var subnetVals []cty.Value
+				for _, s := range lambdaFn.VpcConfig.SubnetIDs {
+					subnetVals = append(subnetVals, cty.StringVal(s))
+				}
+				vpcBody.SetAttributeValue(LAMBDA_SUBNET_IDS,
+					cty.ListVal(subnetVals))
+				if len(lambdaFn.VpcConfig.SecurityGroupIDs) > 0 {
+					var sgVals []cty.Value
+					for _, s := range lambdaFn.VpcConfig.SecurityGroupIDs {
+						sgVals = append(sgVals, cty.StringVal(s))
+					}
+					vpcBody.SetAttributeValue(LAMBDA_SECURITY_GROUP_IDS,
+						cty.ListVal(sgVals))
+				}
+			}
+			if lambdaFn.TracingConfig != nil && len(lambdaFn.TracingConfig.Mode.Value) > 0 {
+				tracingBlock := lambdaBody.AppendNewBlock(LAMBDA_TRACING_CONFIG,
+					nil)
+				tracingBlock.Body().SetAttributeValue(LAMBDA_MODE,
+					cty.StringVal(lambdaFn.TracingConfig.Mode.Value))
+			}
+			if len(lambda.Tags) > 0 {
+				tags := map[string]common.Template{}
+				for key, value := range lambda.Tags {
+					if common.IsTagAwsManaged(key) {
+						continue
+					}
+					tagValue := common.Interpolate(value, common.Interpolation{Value: config.TenantName, Expr: "local.tenant_name"})
+					tags[key] = tagValue
+				}
+				if err := common.SetAttributeTags(lambdaBody, TAGS, tags); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+			}
+
+			if config.GenerateTfState {
+				importConfigs = append(importConfigs, common.ImportConfig{
+					ResourceAddress: strings.Join([]string{
+						AWS_LAMBDA_FUNCTION,
+						resourceName,
+					}, "."),
+					ResourceId: lambdaFn.FunctionName,
+					WorkingDir: workingDir,
+				})
+				tfContext.ImportConfigs = importConfigs
+			}
+
+			// Add aws_lambda_permission resources
+			permissions, clientErr := client.LambdaPermissionGet(config.TenantId, lambdaFn.FunctionName)
+			if clientErr != nil {
+				fmt.Println(clientErr)
+				return &tfContext, common.NewResourceError(resourceName, clientErr)
+			}
+			if permissions != nil {
+				for _, statement := range *permissions {
+					principal := statement.Principal.Service
+					if len(principal) == 0 {
+						principal = statement.Principal.AWS
+					}
+					if len(statement.Sid) == 0 || len(principal) == 0 {
+						log.Printf("[TRACE] Skipping permission (%s) of lambda function %s, it has no statement id or principal.", statement.Sid, lambdaFn.FunctionName)
+						continue
+					}
+					permissionResourceName := resourceName + "_" + common.GetResourceName(statement.Sid)
+					rootBody.AppendNewline()
+					permissionBlock := rootBody.AppendNewBlock("resource",
+						[]string{AWS_LAMBDA_PERMISSION,
+							permissionResourceName})
+					permissionBody := permissionBlock.Body()
+					permissionBody.SetAttributeValue(LAMBDA_STATEMENT_ID,
+						cty.StringVal(statement.Sid))
+					permissionBody.SetAttributeValue(LAMBDA_ACTION,
+						cty.StringVal(statement.Action))
+					if err := common.SetAttributeReference(permissionBody, LAMBDA_FUNCTION_NAME, AWS_LAMBDA_FUNCTION+"."+resourceName+".function_name"); err != nil {
+						fmt.Println(err)
+						return &tfContext, common.NewResourceError(resourceName, err)
+					}
+					if err := common.SetAttributeTemplate(permissionBody, LAMBDA_PRINCIPAL, getLambdaAccountTemplate(config, principal)); err != nil {
+						fmt.Println(err)
+						return &tfContext, common.NewResourceError(resourceName, err)
+					}
+					if err := setLambdaPermissionConditions(config, permissionBody, lambdaFn.FunctionName, statement); err != nil {
+						fmt.Println(err)
+						return &tfContext, common.NewResourceError(resourceName, err)
+					}
+					if config.GenerateTfState {
+						importConfigs = append(importConfigs, common.ImportConfig{
+							ResourceAddress: strings.Join([]string{
+								AWS_LAMBDA_PERMISSION,
+								permissionResourceName,
+							}, "."),
+							ResourceId: lambdaFn.FunctionName + "/" + statement.Sid,
+							WorkingDir: workingDir,
+						})
+						tfContext.ImportConfigs = importConfigs
+					}
+				}
+			}
+
+			_, err = tfFile.Write(hclFile.Bytes())
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			log.Printf("[TRACE] Terraform config is generated for lambda function : %s", shortName)
+
+			tfContext.InputVars = append(tfContext.InputVars, generateLambdaVars(lambda, varFullPrefix, isImage)...)
+			tfContext.OutputVars = append(tfContext.OutputVars, common.OutputVarConfig{
+				Name: varFullPrefix + "arn",
+				ActualVal: strings.Join([]string{
+					AWS_LAMBDA_FUNCTION,
+					resourceName,
+					"arn",
+				}, "."),
+				DescVal:       "The ARN of the Lambda function.",
+				RootTraversal: true,
+			})
+		}
+		log.Println("[TRACE] <====== Lambda function TF generation done. =====>")
+	}
+	return &tfContext, nil
+}
+
+func generateLambdaVars(lambda *duplosdk.DuploLambdaFunction, prefix string, isImage bool) []common.VarConfig {
+	if isImage {
+		return []common.VarConfig{
+			{
+				Name:       prefix + "image_uri",
+				DefaultVal: lambda.Code.ImageURI,
+				TypeVal:    "string",
+				DescVal:    "ECR image URI of the Lambda function " + lambda.Name + ".",
+			},
+		}
+	}
+	return []common.VarConfig{
+		{
+			Name:       prefix + "s3_bucket",
+			DefaultVal: lambda.Code.S3Bucket,
+			TypeVal:    "string",
+			DescVal:    "S3 bucket holding the deployment package of the Lambda function " + lambda.Name + ".",
+		},
+		{
+			Name:       prefix + "s3_key",
+			DefaultVal: lambda.Code.S3Key,
+			TypeVal:    "string",
+			DescVal:    "S3 key of the deployment package of the Lambda function " + lambda.Name + ".",
+		},
+	}
+}
+
+// getLambdaInvokeArn returns the arn api gateway integrations invoke a function with.
+func getLambdaInvokeArn(functionArn string) string {
+	parts := strings.SplitN(functionArn, ":", 5)
+	if len(parts) < 5 {
+		return ""
+	}
+	return "arn:" + parts[1] + ":apigateway:" + parts[3] + ":lambda:path/2015-03-31/functions/" + functionArn + "/invocations"
+}
+
+// setLambdaPermissionConditions sets the conditions of a permission statement, without them the permission
+// would let any resource of the principal service, in any account, invoke the function.
+func setLambdaPermissionConditions(config *common.Config, body *hclwrite.Body, functionName string, statement duplosdk.DuploLambdaPermissionStatement) error {
+	operators := []string{}
+	for operator := range statement.Condition {
+		operators = append(operators, operator)
+	}
+	sort.Strings(operators)
+	for _, operator := range operators {
+		keys := []string{}
+		for key := range statement.Condition[operator] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value := statement.Condition[operator][key]
+			switch strings.ToLower(key) {
+			case "aws:sourcearn":
+				if err := common.SetAttributeTemplate(body, LAMBDA_SOURCE_ARN, getLambdaAccountTemplate(config, value)); err != nil {
+					return err
+				}
+			case "aws:sourceaccount":
+				if err := common.SetAttributeTemplate(body, LAMBDA_SOURCE_ACCOUNT, getLambdaAccountTemplate(config, value)); err != nil {
+					return err
+				}
+			case "lambda:eventsourcetoken":
+				body.SetAttributeValue(LAMBDA_EVENT_SOURCE_TOKEN,
+					cty.StringVal(value))
+			default:
+				log.Printf("[WARN] Condition %s %s of permission (%s) of lambda function %s is not supported by aws_lambda_permission.", operator, key, statement.Sid, functionName)
+			}
+		}
+	}
+	return nil
+}
+
+// getLambdaAccountTemplate replaces the account ID in a principal, source account or source ARN with local.account_id.
+func getLambdaAccountTemplate(config *common.Config, value string) common.Template {
+	return common.Interpolate(value, common.Interpolation{Value: config.AccountID, Expr: "local.account_id"})
+}
@@ -0,0 +1,570 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	elbv2 "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"tenant-native-terraform-generator/duplosdk"
+	"tenant-native-terraform-generator/tf-generator/common"
+)
+
+const (
+	LB_NAME                       string = "name"
+	LB_INTERNAL                   string = "internal"
+	LB_LOAD_BALANCER_TYPE         string = "load_balancer_type"
+	LB_SECURITY_GROUPS            string = "security_groups"
+	LB_SUBNETS                    string = "subnets"
+	LB_IDLE_TIMEOUT               string = "idle_timeout"
+	LB_DROP_INVALID_HEADER_FIELDS string = "drop_invalid_header_fields"
+	LB_ENABLE_DELETION_PROTECTION string = "enable_deletion_protection"
+	LB_ACCESS_LOGS                string = "access_logs"
+	LB_BUCKET                     string = "bucket"
+	LB_PREFIX                     string = "prefix"
+	LB_ENABLED                    string = "enabled"
+	LB_LOAD_BALANCER_ARN          string = "load_balancer_arn"
+	LB_PORT                       string = "port"
+	LB_PROTOCOL                   string = "protocol"
+	LB_SSL_POLICY                 string = "ssl_policy"
+	LB_CERTIFICATE_ARN            string = "certificate_arn"
+	LB_DEFAULT_ACTION             string = "default_action"
+	LB_TYPE                       string = "type"
+	LB_TARGET_GROUP_ARN           string = "target_group_arn"
+	LB_REDIRECT                   string = "redirect"
+	LB_STATUS_CODE                string = "status_code"
+	LB_HOST                       string = "host"
+	LB_PATH                       string = "path"
+	LB_QUERY                      string = "query"
+	LB_TARGET_ID                  string = "target_id"
+	LB_RESOURCE_ARN               string = "resource_arn"
+	LB_WEB_ACL_ARN                string = "web_acl_arn"
+	LB_WEB_ACL_ID                 string = "web_acl_id"
+)
+
+const AWS_LB = "aws_lb"
+const AWS_LB_LISTENER = "aws_lb_listener"
+const AWS_LB_TARGET_GROUP_ATTACHMENT = "aws_lb_target_group_attachment"
+const AWS_WAFV2_WEB_ACL_ASSOCIATION = "aws_wafv2_web_acl_association"
+const AWS_WAFREGIONAL_WEB_ACL_ASSOCIATION = "aws_wafregional_web_acl_association"
+const LB_FILE_NAME_PREFIX = "aws-lb-"
+
+type AwsLb struct {
+}
+
+func (awsLb *AwsLb) Generate(config *common.Config, client *duplosdk.Client) (*common.TFContext, error) {
+	workingDir := filepath.Join(config.TFCodePath, config.TenantProject)
+	list, clientErr := client.TenantGetApplicationLBList(config.TenantId)
+
+	if clientErr != nil {
+		fmt.Println(clientErr)
+		return nil, clientErr
+	}
+	tfContext := common.TFContext{}
+	importConfigs := []common.ImportConfig{}
+	if list != nil && len(*list) > 0 {
+		log.Println("[TRACE] <====== Load balancer TF generation started. =====>")
+		targetGroups, clientErr := client.TenantListApplicationLbTargetGroups(config.TenantId)
+		if clientErr != nil {
+			fmt.Println(clientErr)
+			return &tfContext, clientErr
+		}
+		// Target groups of ecs services are generated along with the service.
+		ecsTargetGroupNames, clientErr := getEcsServiceTargetGroupNames(config, client, targetGroups)
+		if clientErr != nil {
+			fmt.Println(clientErr)
+			return &tfContext, clientErr
+		}
+		hosts, clientErr := client.NativeHostGetList(config.TenantId)
+		if clientErr != nil {
+			fmt.Println(clientErr)
+			return &tfContext, clientErr
+		}
+		instanceResourceNames, asgInstanceIds := getAwsInstanceResourceNames(config, hosts)
+		elbClient := elbv2.NewFromConfig(config.AwsClientConfig)
+		ec2Client := ec2.NewFromConfig(config.AwsClientConfig)
+		lbPrefix := "duplo3-" + config.TenantName + "-"
+		generatedTargetGroupArns := []string{}
+		for _, lb := range *list {
+			if !strings.HasPrefix(lb.Name, lbPrefix) {
+				log.Printf("[TRACE] Skipping load balancer (%s), it is managed by a duplo service.", lb.Name)
+				continue
+			}
+			shortName := lb.Name[len(lbPrefix):]
+			resourceName := common.GetResourceName(shortName)
+			describeLbOutput, err := elbClient.DescribeLoadBalancers(context.TODO(), &elbv2.DescribeLoadBalancersInput{LoadBalancerArns: []string{lb.Arn}})
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			if len(describeLbOutput.LoadBalancers) == 0 {
+				continue
+			}
+			lbDetails := describeLbOutput.LoadBalancers[0]
+			lbAttributesOutput, err := elbClient.DescribeLoadBalancerAttributes(context.TODO(), &elbv2.DescribeLoadBalancerAttributesInput{LoadBalancerArn: &lb.Arn})
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			lbAttributes := map[string]string{}
+			for _, attr := range lbAttributesOutput.Attributes {
+				if attr.Key != nil && attr.Value != nil {
+					lbAttributes[*attr.Key] = *attr.Value
+				}
+			}
+			settings, clientErr := client.TenantGetApplicationLbSettings(config.TenantId, lb.Arn)
+			if clientErr != nil {
+				fmt.Println(clientErr)
+				return &tfContext, common.NewResourceError(resourceName, clientErr)
+			}
+
+			hclFile := hclwrite.NewEmptyFile()
+			path := filepath.Join(workingDir, LB_FILE_NAME_PREFIX+shortName+".tf")
+			tfFile, err := os.Create(path)
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			rootBody := hclFile.Body()
+
+			// Add aws_lb resource
+			lbBlock := rootBody.AppendNewBlock("resource",
+				[]string{AWS_LB,
+					resourceName})
+			lbBody := lbBlock.Body()
+			tfContext.References = append(tfContext.References, common.ResourceReference{
+				Value:   lb.Arn,
+				Address: AWS_LB + "." + resourceName + ".arn",
+			})
+			if err := common.SetAttributeTemplate(lbBody, LB_NAME, common.Template("duplo3-${local.tenant_name}-"+common.EscapeTemplate(shortName))); err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			lbBody.SetAttributeValue(LB_INTERNAL,
+				cty.BoolVal(lb.IsInternal))
+			lbType := string(lbDetails.Type)
+			if len(lbType) > 0 {
+				lbBody.SetAttributeValue(LB_LOAD_BALANCER_TYPE,
+					cty.StringVal(lbType))
+			}
+			if len(lbDetails.SecurityGroups) > 0 {
+				sgTokens, err := getSecurityGroupIdsTokens(config, ec2Client, lbDetails.SecurityGroups)
+				if err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				lbBody.SetAttributeRaw(LB_SECURITY_GROUPS, sgTokens)
+			}
+			if len(lbDetails.AvailabilityZones) > 0 {
+				var vals []cty.Value
+				for _, az := range lbDetails.AvailabilityZones {
+					if az.SubnetId != nil {
+						vals = append(vals, cty.StringVal(*az.SubnetId))
+					}
+				}
+				if len(vals) > 0 {
+					lbBody.SetAttributeValue(LB_SUBNETS,
+						cty.ListVal(vals))
+				}
+			}
+			if lbType == "application" {
+				if idleTimeout, err := strconv.Atoi(lbAttributes["idle_timeout.timeout_seconds"]); err == nil {
+					lbBody.SetAttributeValue(LB_IDLE_TIMEOUT,
+						cty.NumberIntVal(int64(idleTimeout)))
+				}
+				lbBody.SetAttributeValue(LB_DROP_INVALID_HEADER_FIELDS,
+					cty.BoolVal(settings.DropInvalidHeaders))
+			}
+			if lbAttributes["deletion_protection.enabled"] == "true" {
+				lbBody.SetAttributeValue(LB_ENABLE_DELETION_PROTECTION,
+					cty.BoolVal(true))
+			}
+			if lbAttributes["access_logs.s3.enabled"] == "true" {
+				accessLogsBlock := lbBody.AppendNewBlock(LB_ACCESS_LOGS,
+					nil)
+				accessLogsBody := accessLogsBlock.Body()
+				accessLogsBody.SetAttributeValue(LB_BUCKET,
+					cty.StringVal(lbAttributes["access_logs.s3.bucket"]))
+				if prefix := lbAttributes["access_logs.s3.prefix"]; len(prefix) > 0 {
+					accessLogsBody.SetAttributeValue(LB_PREFIX,
+						cty.StringVal(prefix))
+				}
+				accessLogsBody.SetAttributeValue(LB_ENABLED,
+					cty.BoolVal(true))
+			}
+			if lb.Tags != nil && len(*lb.Tags) > 0 {
+				tags := map[string]common.Template{}
+				for _, tag := range *lb.Tags {
+					if common.IsTagAwsManaged(tag.Key) {
+						continue
+					}
+					tagValue := common.Interpolate(tag.Value, common.Interpolation{Value: config.TenantName, Expr: "local.tenant_name"})
+					tags[tag.Key] = tagValue
+				}
+				if err := common.SetAttributeTags(lbBody, TAGS, tags); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+			}
+			if config.GenerateTfState {
+				importConfigs = append(importConfigs, common.ImportConfig{
+					ResourceAddress: strings.Join([]string{
+						AWS_LB,
+						resourceName,
+					}, "."),
+					ResourceId: lb.Arn,
+					WorkingDir: workingDir,
+				})
+				tfContext.ImportConfigs = importConfigs
+			}
+
+			// Collect the target groups of this load balancer which are not owned by an ecs service.
+			lbTargetGroups := []duplosdk.DuploAwsLbTargetGroup{}
+			lbTargetGroupArns := []string{}
+			if targetGroups != nil {
+				for _, tg := range *targetGroups {
+					if common.Contains(tg.LoadBalancerArns, lb.Arn) && !common.Contains(ecsTargetGroupNames, tg.TargetGroupName) {
+						lbTargetGroups = append(lbTargetGroups, tg)
+						lbTargetGroupArns = append(lbTargetGroupArns, tg.TargetGroupArn)
+					}
+				}
+			}
+
+			// Add aws_lb_listener resources
+			listeners, clientErr := client.TenantListApplicationLbListeners(config.TenantId, shortName)
+			if clientErr != nil {
+				fmt.Println(clientErr)
+				return &tfContext, common.NewResourceError(resourceName, clientErr)
+			}
+			if listeners != nil {
+				for _, listener := range *listeners {
+					protocol := ""
+					if listener.Protocol != nil {
+						protocol = listener.Protocol.Value
+					}
+					listenerResourceName := resourceName + "_" + strings.ToLower(protocol) + "_" + strconv.Itoa(listener.Port)
+					rootBody.AppendNewline()
+					listenerBlock := rootBody.AppendNewBlock("resource",
+						[]string{AWS_LB_LISTENER,
+							listenerResourceName})
+					listenerBody := listenerBlock.Body()
+					if err := common.SetAttributeReference(listenerBody, LB_LOAD_BALANCER_ARN, AWS_LB+"."+resourceName+".arn"); err != nil {
+						fmt.Println(err)
+						return &tfContext, common.NewResourceError(resourceName, err)
+					}
+					listenerBody.SetAttributeValue(LB_PORT,
+						cty.NumberIntVal(int64(listener.Port)))
+					if len(protocol) > 0 {
+						listenerBody.SetAttributeValue(LB_PROTOCOL,
+							cty.StringVal(protocol))
+					}
+					if len(listener.SSLPolicy) > 0 {
+						listenerBody.SetAttributeValue(LB_SSL_POLICY,
+							cty.StringVal(listener.SSLPolicy))
+					}
+					for _, cert := range listener.Certificates {
+						if cert.IsDefault || len(listener.Certificates) == 1 {
+							listenerBody.SetAttributeValue(LB_CERTIFICATE_ARN,
+								cty.StringVal(cert.CertificateArn))
+							break
+						}
+					}
+					var redirectConfigs map[int32]*types.RedirectActionConfig
+					for _, action := range listener.DefaultActions {
+						actionType := ""
+						if action.Type != nil {
+							actionType = action.Type.Value
+						}
+						actionBlock := listenerBody.AppendNewBlock(LB_DEFAULT_ACTION,
+							nil)
+						actionBody := actionBlock.Body()
+						actionBody.SetAttributeValue(LB_TYPE,
+							cty.StringVal(actionType))
+						switch actionType {
+						case "forward":
+							if tgName := getLbTargetGroupNameByArn(targetGroups, action.TargetGroupArn); len(tgName) > 0 {
+								if err := common.SetAttributeReference(actionBody, LB_TARGET_GROUP_ARN, AWS_LB_TARGET_GROUP+"."+getLbTargetGroupResourceName(tgName)+".arn"); err != nil {
+									fmt.Println(err)
+									return &tfContext, common.NewResourceError(resourceName, err)
+								}
+								if !common.Contains(lbTargetGroupArns, action.TargetGroupArn) && !common.Contains(ecsTargetGroupNames, tgName) {
+									lbTargetGroups = append(lbTargetGroups, *getLbTargetGroupByArn(targetGroups, action.TargetGroupArn))
+									lbTargetGroupArns = append(lbTargetGroupArns, action.TargetGroupArn)
+								}
+							} else {
+								actionBody.SetAttributeValue(LB_TARGET_GROUP_ARN,
+									cty.StringVal(action.TargetGroupArn))
+							}
+						case "redirect":
+							// Duplo does not return the redirect config, it is read from the listener itself.
+							if redirectConfigs == nil {
+								redirectConfigs, err = getLbListenerRedirectConfigs(elbClient, listener.ListenerArn)
+								if err != nil {
+									fmt.Println(err)
+									return &tfContext, common.NewResourceError(resourceName, err)
+								}
+							}
+							redirect, ok := redirectConfigs[int32(action.Order)]
+							if !ok {
+								err = fmt.Errorf("redirect config of listener %s is not found", listener.ListenerArn)
+								fmt.Println(err)
+								return &tfContext, common.NewResourceError(resourceName, err)
+							}
+							redirectBlock := actionBody.AppendNewBlock(LB_REDIRECT,
+								nil)
+							redirectBody := redirectBlock.Body()
+							for _, attr := range []struct {
+								name  string
+								value *string
+							}{
+								{LB_HOST, redirect.Host},
+								{LB_PATH, redirect.Path},
+								{LB_PORT, redirect.Port},
+								{LB_PROTOCOL, redirect.Protocol},
+								{LB_QUERY, redirect.Query},
+							} {
+								if attr.value != nil {
+									// The #{host} style placeholders are kept by terraform as they are.
+									redirectBody.SetAttributeValue(attr.name,
+										cty.StringVal(*attr.value))
+								}
+							}
+							redirectBody.SetAttributeValue(LB_STATUS_CODE,
+								cty.StringVal(string(redirect.StatusCode)))
+						}
+					}
+					if config.GenerateTfState {
+						importConfigs = append(importConfigs, common.ImportConfig{
+							ResourceAddress: strings.Join([]string{
+								AWS_LB_LISTENER,
+								listenerResourceName,
+							}, "."),
+							ResourceId: listener.ListenerArn,
+							WorkingDir: workingDir,
+						})
+						tfContext.ImportConfigs = importConfigs
+					}
+				}
+			}
+
+			// Add aws_lb_target_group and aws_lb_target_group_attachment resources
+			if len(lbTargetGroupArns) > 0 {
+				describeTgOutput, err := elbClient.DescribeTargetGroups(context.TODO(), &elbv2.DescribeTargetGroupsInput{TargetGroupArns: lbTargetGroupArns})
+				if err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				tgPorts := map[string]int{}
+				for _, tg := range describeTgOutput.TargetGroups {
+					if tg.TargetGroupArn != nil && tg.Port != nil {
+						tgPorts[*tg.TargetGroupArn] = int(*tg.Port)
+					}
+				}
+				for _, tg := range lbTargetGroups {
+					// A target group shared by several load balancers is generated only once.
+					if common.Contains(generatedTargetGroupArns, tg.TargetGroupArn) {
+						continue
+					}
+					generatedTargetGroupArns = append(generatedTargetGroupArns, tg.TargetGroupArn)
+					rootBody.AppendNewline()
+					tgBody, err := appendLbTargetGroup(rootBody, tg, tgPorts[tg.TargetGroupArn])
+					if err != nil {
+						fmt.Println(err)
+						return &tfContext, common.NewResourceError(resourceName, err)
+					}
+					tfContext.References = append(tfContext.References, getLbTargetGroupReference(tg))
+					tgAttributes, clientErr := client.DuploAwsTargetGroupAttributesGet(config.TenantId, duplosdk.DuploTargetGroupAttributesGetReq{
+						TargetGroupArn: tg.TargetGroupArn,
+					})
+					if clientErr != nil {
+						fmt.Println(clientErr)
+						return &tfContext, common.NewResourceError(resourceName, clientErr)
+					}
+					setLbTargetGroupAttributes(tgBody, tgAttributes)
+					if config.GenerateTfState {
+						importConfigs = append(importConfigs, getLbTargetGroupImportConfig(tg, workingDir))
+						tfContext.ImportConfigs = importConfigs
+					}
+
+					targetHealthOutput, err := elbClient.DescribeTargetHealth(context.TODO(), &elbv2.DescribeTargetHealthInput{TargetGroupArn: &tg.TargetGroupArn})
+					if err != nil {
+						fmt.Println(err)
+						return &tfContext, common.NewResourceError(resourceName, err)
+					}
+					tgResourceName := getLbTargetGroupResourceName(tg.TargetGroupName)
+					for _, targetHealth := range targetHealthOutput.TargetHealthDescriptions {
+						if targetHealth.Target == nil || targetHealth.Target.Id == nil {
+							continue
+						}
+						if common.Contains(asgInstanceIds, *targetHealth.Target.Id) {
+							log.Printf("[TRACE] Skipping target (%s) of target group %s, it is attached by its autoscaling group.", *targetHealth.Target.Id, tg.TargetGroupName)
+							continue
+						}
+						target := targetHealth.Target
+						attachmentResourceName := tgResourceName + "_" + common.GetResourceName(*target.Id)
+						if target.Port != nil {
+							attachmentResourceName = attachmentResourceName + "_" + strconv.Itoa(int(*target.Port))
+						}
+						rootBody.AppendNewline()
+						attachmentBlock := rootBody.AppendNewBlock("resource",
+							[]string{AWS_LB_TARGET_GROUP_ATTACHMENT,
+								attachmentResourceName})
+						attachmentBody := attachmentBlock.Body()
+						if err := common.SetAttributeReference(attachmentBody, LB_TARGET_GROUP_ARN, AWS_LB_TARGET_GROUP+"."+tgResourceName+".arn"); err != nil {
+							fmt.Println(err)
+							return &tfContext, common.NewResourceError(resourceName, err)
+						}
+						if instanceResourceName, ok := instanceResourceNames[*target.Id]; ok {
+							if err := common.SetAttributeReference(attachmentBody, LB_TARGET_ID, AWS_INSTANCE+"."+instanceResourceName+".id"); err != nil {
+								fmt.Println(err)
+								return &tfContext, common.NewResourceError(resourceName, err)
+							}
+						} else {
+							// Targets which are not generated, like IP addresses, are supplied as input.
+							varName := "lb_" + attachmentResourceName + "_target_id"
+							if err := common.SetAttributeReference(attachmentBody, LB_TARGET_ID, "var."+varName); err != nil {
+								fmt.Println(err)
+								return &tfContext, common.NewResourceError(resourceName, err)
+							}
+							tfContext.InputVars = append(tfContext.InputVars, common.VarConfig{
+								Name:       varName,
+								DefaultVal: *target.Id,
+								TypeVal:    "string",
+								DescVal:    "Target of the target group " + tg.TargetGroupName + ".",
+							})
+						}
+						if target.Port != nil {
+							attachmentBody.SetAttributeValue(LB_PORT,
+								cty.NumberIntVal(int64(*target.Port)))
+						}
+						// NOTE: terraform does not support importing target group attachments.
+					}
+				}
+			}
+
+			// Add web acl association resource
+			if len(settings.WebACLID) > 0 {
+				rootBody.AppendNewline()
+				if strings.Contains(settings.WebACLID, ":wafv2:") {
+					wafBlock := rootBody.AppendNewBlock("resource",
+						[]string{AWS_WAFV2_WEB_ACL_ASSOCIATION,
+							resourceName})
+					wafBody := wafBlock.Body()
+					if err := setLbArnReference(wafBody, LB_RESOURCE_ARN, resourceName); err != nil {
+						fmt.Println(err)
+						return &tfContext, common.NewResourceError(resourceName, err)
+					}
+					wafBody.SetAttributeValue(LB_WEB_ACL_ARN,
+						cty.StringVal(settings.WebACLID))
+					if config.GenerateTfState {
+						importConfigs = append(importConfigs, common.ImportConfig{
+							ResourceAddress: strings.Join([]string{
+								AWS_WAFV2_WEB_ACL_ASSOCIATION,
+								resourceName,
+							}, "."),
+							ResourceId: settings.WebACLID + "," + lb.Arn,
+							WorkingDir: workingDir,
+						})
+						tfContext.ImportConfigs = importConfigs
+					}
+				} else {
+					wafBlock := rootBody.AppendNewBlock("resource",
+						[]string{AWS_WAFREGIONAL_WEB_ACL_ASSOCIATION,
+							resourceName})
+					wafBody := wafBlock.Body()
+					if err := setLbArnReference(wafBody, LB_RESOURCE_ARN, resourceName); err != nil {
+						fmt.Println(err)
+						return &tfContext, common.NewResourceError(resourceName, err)
+					}
+					wafBody.SetAttributeValue(LB_WEB_ACL_ID,
+						cty.StringVal(settings.WebACLID))
+					if config.GenerateTfState {
+						importConfigs = append(importConfigs, common.ImportConfig{
+							ResourceAddress: strings.Join([]string{
+								AWS_WAFREGIONAL_WEB_ACL_ASSOCIATION,
+								resourceName,
+							}, "."),
+							ResourceId: settings.WebACLID + ":" + lb.Arn,
+							WorkingDir: workingDir,
+						})
+						tfContext.ImportConfigs = importConfigs
+					}
+				}
+			}
+
+			_, err = tfFile.Write(hclFile.Bytes())
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			log.Printf("[TRACE] Terraform config is generated for load balancer : %s", shortName)
+
+			tfContext.OutputVars = append(tfContext.OutputVars, common.OutputVarConfig{
+				Name: "lb_" + resourceName + "_dns_name",
+				ActualVal: strings.Join([]string{
+					AWS_LB,
+					resourceName,
+					"dns_name",
+				}, "."),
+				DescVal:       "The DNS name of the load balancer.",
+				RootTraversal: true,
+			})
+		}
+		log.Println("[TRACE] <====== Load balancer TF generation done. =====>")
+	}
+	return &tfContext, nil
+}
+
+func setLbArnReference(body *hclwrite.Body, attrName string, resourceName string) error {
+	return common.SetAttributeReference(body, attrName, AWS_LB+"."+resourceName+".arn")
+}
+
+func getLbTargetGroupByArn(targetGroups *[]duplosdk.DuploAwsLbTargetGroup, targetGroupArn string) *duplosdk.DuploAwsLbTargetGroup {
+	if targetGroups == nil {
+		return nil
+	}
+	for i := range *targetGroups {
+		if (*targetGroups)[i].TargetGroupArn == targetGroupArn {
+			return &(*targetGroups)[i]
+		}
+	}
+	return nil
+}
+
+func getLbTargetGroupNameByArn(targetGroups *[]duplosdk.DuploAwsLbTargetGroup, targetGroupArn string) string {
+	if tg := getLbTargetGroupByArn(targetGroups, targetGroupArn); tg != nil {
+		return tg.TargetGroupName
+	}
+	return ""
+}
+
+// getLbListenerRedirectConfigs returns the redirect configs of the default actions of a listener by action order.
+func getLbListenerRedirectConfigs(elbClient *elbv2.Client, listenerArn string) (map[int32]*types.RedirectActionConfig, error) {
+	output, err := elbClient.DescribeListeners(context.TODO(), &elbv2.DescribeListenersInput{ListenerArns: []string{listenerArn}})
+	if err != nil {
+		return nil, err
+	}
+	configs := map[int32]*types.RedirectActionConfig{}
+	for _, listener := range output.Listeners {
+		for _, action := range listener.DefaultActions {
+			if action.RedirectConfig == nil {
+				continue
+			}
+			order := int32(0)
+			if action.Order != nil {
+				order = *action.Order
+			}
+			configs[order] = action.RedirectConfig
+		}
+	}
+	return configs, nil
+}
@@ -0,0 +1,183 @@
+package tenant
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"tenant-native-terraform-generator/duplosdk"
+	"tenant-native-terraform-generator/tf-generator/common"
+)
+
+const (
+	TG_NAME                string = "name"
+	TG_PORT                string = "port"
+	TG_PROTOCOL            string = "protocol"
+	TG_PROTOCOL_VERSION    string = "protocol_version"
+	TG_TARGET_TYPE         string = "target_type"
+	TG_VPC_ID              string = "vpc_id"
+	TG_HEALTH_CHECK        string = "health_check"
+	TG_ENABLED             string = "enabled"
+	TG_INTERVAL            string = "interval"
+	TG_PATH                string = "path"
+	TG_TIMEOUT             string = "timeout"
+	TG_HEALTHY_THRESHOLD   string = "healthy_threshold"
+	TG_UNHEALTHY_THRESHOLD string = "unhealthy_threshold"
+	TG_MATCHER             string = "matcher"
+	TG_DEREGISTRATION      string = "deregistration_delay"
+	TG_SLOW_START          string = "slow_start"
+	TG_LB_ALGORITHM_TYPE   string = "load_balancing_algorithm_type"
+	TG_STICKINESS          string = "stickiness"
+	TG_TYPE                string = "type"
+	TG_COOKIE_DURATION     string = "cookie_duration"
+)
+
+const AWS_LB_TARGET_GROUP = "aws_lb_target_group"
+
+// getLbTargetGroupResourceName returns the terraform resource name used for a target group.
+func getLbTargetGroupResourceName(targetGroupName string) string {
+	return common.GetResourceName(targetGroupName)
+}
+
+func getLbTargetGroupReference(tg duplosdk.DuploAwsLbTargetGroup) common.ResourceReference {
+	return common.ResourceReference{
+		Value:   tg.TargetGroupArn,
+		Address: AWS_LB_TARGET_GROUP + "." + getLbTargetGroupResourceName(tg.TargetGroupName) + ".arn",
+	}
+}
+
+// appendLbTargetGroup renders an aws_lb_target_group block and returns its body.
+func appendLbTargetGroup(rootBody *hclwrite.Body, tg duplosdk.DuploAwsLbTargetGroup, port int) (*hclwrite.Body, error) {
+	resourceName := getLbTargetGroupResourceName(tg.TargetGroupName)
+	tgBlock := rootBody.AppendNewBlock("resource",
+		[]string{AWS_LB_TARGET_GROUP,
+			resourceName})
+	tgBody := tgBlock.Body()
+	tgBody.SetAttributeValue(TG_NAME,
+		cty.StringVal(tg.TargetGroupName))
+	protocol := ""
+	if tg.Protocol != nil {
+		protocol = tg.Protocol.Value
+	}
+	targetType := ""
+	if tg.TargetType != nil {
+		targetType = tg.TargetType.Value
+	}
+	if targetType != "lambda" {
+		if port > 0 {
+			tgBody.SetAttributeValue(TG_PORT,
+				cty.NumberIntVal(int64(port)))
+		}
+		if len(protocol) > 0 {
+			tgBody.SetAttributeValue(TG_PROTOCOL,
+				cty.StringVal(protocol))
+		}
+		if len(tg.ProtocolVersion) > 0 && (protocol == "HTTP" || protocol == "HTTPS") {
+			tgBody.SetAttributeValue(TG_PROTOCOL_VERSION,
+				cty.StringVal(tg.ProtocolVersion))
+		}
+		if err := common.SetAttributeReference(tgBody, TG_VPC_ID, "local.vpc_id"); err != nil {
+			return nil, err
+		}
+	}
+	if len(targetType) > 0 {
+		tgBody.SetAttributeValue(TG_TARGET_TYPE,
+			cty.StringVal(targetType))
+	}
+
+	hcBlock := tgBody.AppendNewBlock(TG_HEALTH_CHECK,
+		nil)
+	hcBody := hcBlock.Body()
+	hcBody.SetAttributeValue(TG_ENABLED,
+		cty.BoolVal(tg.HealthCheckEnabled))
+	if tg.HealthCheckEnabled {
+		if tg.HealthCheckIntervalSeconds > 0 {
+			hcBody.SetAttributeValue(TG_INTERVAL,
+				cty.NumberIntVal(int64(tg.HealthCheckIntervalSeconds)))
+		}
+		if len(tg.HealthCheckPath) > 0 {
+			hcBody.SetAttributeValue(TG_PATH,
+				cty.StringVal(tg.HealthCheckPath))
+		}
+		if len(tg.HealthCheckPort) > 0 {
+			hcBody.SetAttributeValue(TG_PORT,
+				cty.StringVal(tg.HealthCheckPort))
+		}
+		if tg.HealthCheckProtocol != nil && len(tg.HealthCheckProtocol.Value) > 0 {
+			hcBody.SetAttributeValue(TG_PROTOCOL,
+				cty.StringVal(tg.HealthCheckProtocol.Value))
+		}
+		if tg.HealthCheckTimeoutSeconds > 0 {
+			hcBody.SetAttributeValue(TG_TIMEOUT,
+				cty.NumberIntVal(int64(tg.HealthCheckTimeoutSeconds)))
+		}
+		if tg.HealthyThreshold > 0 {
+			hcBody.SetAttributeValue(TG_HEALTHY_THRESHOLD,
+				cty.NumberIntVal(int64(tg.HealthyThreshold)))
+		}
+		if tg.UnhealthyThreshold > 0 {
+			hcBody.SetAttributeValue(TG_UNHEALTHY_THRESHOLD,
+				cty.NumberIntVal(int64(tg.UnhealthyThreshold)))
+		}
+		if tg.HealthMatcher != nil && len(tg.HealthMatcher.HttpCode) > 0 {
+			hcBody.SetAttributeValue(TG_MATCHER,
+				cty.StringVal(tg.HealthMatcher.HttpCode))
+		} else if tg.HealthMatcher != nil && len(tg.HealthMatcher.GrpcCode) > 0 {
+			hcBody.SetAttributeValue(TG_MATCHER,
+				cty.StringVal(tg.HealthMatcher.GrpcCode))
+		}
+	}
+	return tgBody, nil
+}
+
+// setLbTargetGroupAttributes renders the target group attributes terraform exposes as arguments.
+func setLbTargetGroupAttributes(tgBody *hclwrite.Body, attributes *[]duplosdk.DuploKeyStringValue) {
+	if attributes == nil {
+		return
+	}
+	attrMap := map[string]string{}
+	for _, attr := range *attributes {
+		attrMap[attr.Key] = attr.Value
+	}
+	if val, ok := attrMap["deregistration_delay.timeout_seconds"]; ok && len(val) > 0 {
+		tgBody.SetAttributeValue(TG_DEREGISTRATION,
+			cty.StringVal(val))
+	}
+	if val, err := strconv.Atoi(attrMap["slow_start.duration_seconds"]); err == nil && val > 0 {
+		tgBody.SetAttributeValue(TG_SLOW_START,
+			cty.NumberIntVal(int64(val)))
+	}
+	if val, ok := attrMap["load_balancing.algorithm.type"]; ok && len(val) > 0 {
+		tgBody.SetAttributeValue(TG_LB_ALGORITHM_TYPE,
+			cty.StringVal(val))
+	}
+	if attrMap["stickiness.enabled"] == "true" {
+		stickinessBlock := tgBody.AppendNewBlock(TG_STICKINESS,
+			nil)
+		stickinessBody := stickinessBlock.Body()
+		stickinessBody.SetAttributeValue(TG_ENABLED,
+			cty.BoolVal(true))
+		if val, ok := attrMap["stickiness.type"]; ok && len(val) > 0 {
+			stickinessBody.SetAttributeValue(TG_TYPE,
+				cty.StringVal(val))
+		}
+		if val, err := strconv.Atoi(attrMap["stickiness.lb_cookie.duration_seconds"]); err == nil && val > 0 {
+			stickinessBody.SetAttributeValue(TG_COOKIE_DURATION,
+				cty.NumberIntVal(int64(val)))
+		}
+	}
+}
+
+// getLbTargetGroupImportConfig returns the import config of a generated target group.
+func getLbTargetGroupImportConfig(tg duplosdk.DuploAwsLbTargetGroup, workingDir string) common.ImportConfig {
+	return common.ImportConfig{
+		ResourceAddress: strings.Join([]string{
+			AWS_LB_TARGET_GROUP,
+			getLbTargetGroupResourceName(tg.TargetGroupName),
+		}, "."),
+		ResourceId: tg.TargetGroupArn,
+		WorkingDir: workingDir,
+	}
+}
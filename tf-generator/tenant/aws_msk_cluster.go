@@ -0,0 +1,405 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/kafka"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"tenant-native-terraform-generator/duplosdk"
+	"tenant-native-terraform-generator/tf-generator/common"
+)
+
+const (
+	MSK_CLUSTER_NAME                   string = "cluster_name"
+	MSK_KAFKA_VERSION                  string = "kafka_version"
+	MSK_NUMBER_OF_BROKER_NODES         string = "number_of_broker_nodes"
+	MSK_ENHANCED_MONITORING            string = "enhanced_monitoring"
+	MSK_BROKER_NODE_GROUP_INFO         string = "broker_node_group_info"
+	MSK_INSTANCE_TYPE                  string = "instance_type"
+	MSK_CLIENT_SUBNETS                 string = "client_subnets"
+	MSK_SECURITY_GROUPS                string = "security_groups"
+	MSK_AZ_DISTRIBUTION                string = "az_distribution"
+	MSK_STORAGE_INFO                   string = "storage_info"
+	MSK_EBS_STORAGE_INFO               string = "ebs_storage_info"
+	MSK_VOLUME_SIZE                    string = "volume_size"
+	MSK_ENCRYPTION_INFO                string = "encryption_info"
+	MSK_ENCRYPTION_AT_REST_KMS_KEY_ARN string = "encryption_at_rest_kms_key_arn"
+	MSK_ENCRYPTION_IN_TRANSIT          string = "encryption_in_transit"
+	MSK_CLIENT_BROKER                  string = "client_broker"
+	MSK_IN_CLUSTER                     string = "in_cluster"
+	MSK_CLIENT_AUTHENTICATION          string = "client_authentication"
+	MSK_SASL                           string = "sasl"
+	MSK_IAM                            string = "iam"
+	MSK_SCRAM                          string = "scram"
+	MSK_TLS                            string = "tls"
+	MSK_CERTIFICATE_AUTHORITY_ARNS     string = "certificate_authority_arns"
+	MSK_UNAUTHENTICATED                string = "unauthenticated"
+	MSK_CONFIGURATION_INFO             string = "configuration_info"
+	MSK_ARN                            string = "arn"
+	MSK_REVISION                       string = "revision"
+	MSK_OPEN_MONITORING                string = "open_monitoring"
+	MSK_PROMETHEUS                     string = "prometheus"
+	MSK_JMX_EXPORTER                   string = "jmx_exporter"
+	MSK_NODE_EXPORTER                  string = "node_exporter"
+	MSK_ENABLED_IN_BROKER              string = "enabled_in_broker"
+	MSK_NAME                           string = "name"
+	MSK_DESCRIPTION                    string = "description"
+	MSK_KAFKA_VERSIONS                 string = "kafka_versions"
+	MSK_SERVER_PROPERTIES              string = "server_properties"
+)
+
+const AWS_MSK_CLUSTER = "aws_msk_cluster"
+const AWS_MSK_CONFIGURATION = "aws_msk_configuration"
+const MSK_VAR_PREFIX = "msk_"
+const MSK_FILE_NAME_PREFIX = "aws-msk-"
+
+type AwsMskCluster struct {
+}
+
+func (awsMskCluster *AwsMskCluster) Generate(config *common.Config, client *duplosdk.Client) (*common.TFContext, error) {
+	workingDir := filepath.Join(config.TFCodePath, config.TenantProject)
+	list, clientErr := client.TenantListKafkaCluster(config.TenantId)
+
+	if clientErr != nil {
+		fmt.Println(clientErr)
+		return nil, clientErr
+	}
+	tfContext := common.TFContext{}
+	importConfigs := []common.ImportConfig{}
+	if list != nil && len(*list) > 0 {
+		log.Println("[TRACE] <====== MSK cluster TF generation started. =====>")
+		tenantKms, clientErr := client.TenantGetTenantKmsKey(config.TenantId)
+		if clientErr != nil {
+			fmt.Println(clientErr)
+			return &tfContext, clientErr
+		}
+		kafkaClient := kafka.NewFromConfig(config.AwsClientConfig)
+		ec2Client := ec2.NewFromConfig(config.AwsClientConfig)
+		clusters := *list
+		sort.Slice(clusters, func(i, j int) bool { return clusters[i].Name < clusters[j].Name })
+		generatedConfigurationArns := []string{}
+		for _, cluster := range clusters {
+			clusterInfo, clientErr := client.TenantGetKafkaClusterInfo(config.TenantId, cluster.Arn)
+			if clientErr != nil {
+				fmt.Println(clientErr)
+				return &tfContext, clientErr
+			}
+			if clusterInfo == nil {
+				continue
+			}
+			shortName := strings.TrimPrefix(clusterInfo.Name, "duploservices-"+config.TenantName+"-")
+			resourceName := common.GetResourceName(shortName)
+			varFullPrefix := MSK_VAR_PREFIX + resourceName + "_"
+
+			describeClusterOutput, err := kafkaClient.DescribeCluster(context.TODO(), &kafka.DescribeClusterInput{ClusterArn: &clusterInfo.Arn})
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+
+			hclFile := hclwrite.NewEmptyFile()
+			path := filepath.Join(workingDir, MSK_FILE_NAME_PREFIX+shortName+".tf")
+			tfFile, err := os.Create(path)
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			rootBody := hclFile.Body()
+
+			// Add aws_msk_configuration resource when a custom configuration is attached.
+			configurationResourceName := ""
+			if clusterInfo.CurrentSoftware != nil && len(clusterInfo.CurrentSoftware.ConfigurationArn) > 0 {
+				configurationArn := clusterInfo.CurrentSoftware.ConfigurationArn
+				describeConfigurationOutput, err := kafkaClient.DescribeConfiguration(context.TODO(), &kafka.DescribeConfigurationInput{Arn: &configurationArn})
+				if err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				configurationResourceName = common.GetResourceName(*describeConfigurationOutput.Name)
+				if !common.Contains(generatedConfigurationArns, configurationArn) {
+					generatedConfigurationArns = append(generatedConfigurationArns, configurationArn)
+					describeRevisionOutput, err := kafkaClient.DescribeConfigurationRevision(context.TODO(), &kafka.DescribeConfigurationRevisionInput{
+						Arn:      &configurationArn,
+						Revision: int64(clusterInfo.CurrentSoftware.ConfigurationRevision),
+					})
+					if err != nil {
+						fmt.Println(err)
+						return &tfContext, common.NewResourceError(resourceName, err)
+					}
+					configurationBlock := rootBody.AppendNewBlock("resource",
+						[]string{AWS_MSK_CONFIGURATION,
+							configurationResourceName})
+					configurationBody := configurationBlock.Body()
+					tfContext.References = append(tfContext.References, common.ResourceReference{
+						Value:   configurationArn,
+						Address: AWS_MSK_CONFIGURATION + "." + configurationResourceName + ".arn",
+					})
+					configurationBody.SetAttributeValue(MSK_NAME,
+						cty.StringVal(*describeConfigurationOutput.Name))
+					if describeConfigurationOutput.Description != nil && len(*describeConfigurationOutput.Description) > 0 {
+						configurationBody.SetAttributeValue(MSK_DESCRIPTION,
+							cty.StringVal(*describeConfigurationOutput.Description))
+					}
+					if len(describeConfigurationOutput.KafkaVersions) > 0 {
+						var vals []cty.Value
+						for _, version := range describeConfigurationOutput.KafkaVersions {
+							vals = append(vals, cty.StringVal(version))
+						}
+						configurationBody.SetAttributeValue(MSK_KAFKA_VERSIONS,
+							cty.ListVal(vals))
+					}
+					configurationBody.SetAttributeValue(MSK_SERVER_PROPERTIES,
+						cty.StringVal(string(describeRevisionOutput.ServerProperties)))
+					rootBody.AppendNewline()
+					if config.GenerateTfState {
+						importConfigs = append(importConfigs, common.ImportConfig{
+							ResourceAddress: strings.Join([]string{
+								AWS_MSK_CONFIGURATION,
+								configurationResourceName,
+							}, "."),
+							ResourceId: configurationArn,
+							WorkingDir: workingDir,
+						})
+						tfContext.ImportConfigs = importConfigs
+					}
+				}
+			}
+
+			// Add aws_msk_cluster resource
+			mskBlock := rootBody.AppendNewBlock("resource",
+				[]string{AWS_MSK_CLUSTER,
+					resourceName})
+			mskBody := mskBlock.Body()
+			tfContext.References = append(tfContext.References, common.ResourceReference{
+				Value:   clusterInfo.Arn,
+				Address: AWS_MSK_CLUSTER + "." + resourceName + ".arn",
+			})
+			clusterName := common.Interpolate(clusterInfo.Name)
+			if shortName != clusterInfo.Name {
+				clusterName = common.Template("${local.tenant_prefix}-" + common.EscapeTemplate(shortName))
+			}
+			if err := common.SetAttributeTemplate(mskBody, MSK_CLUSTER_NAME, clusterName); err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			if clusterInfo.CurrentSoftware != nil && len(clusterInfo.CurrentSoftware.KafkaVersion) > 0 {
+				mskBody.SetAttributeValue(MSK_KAFKA_VERSION,
+					cty.StringVal(clusterInfo.CurrentSoftware.KafkaVersion))
+			}
+			mskBody.SetAttributeValue(MSK_NUMBER_OF_BROKER_NODES,
+				cty.NumberIntVal(int64(clusterInfo.NumberOfBrokerNodes)))
+			if clusterInfo.EnhancedMonitoring != nil && len(clusterInfo.EnhancedMonitoring.Value) > 0 {
+				mskBody.SetAttributeValue(MSK_ENHANCED_MONITORING,
+					cty.StringVal(clusterInfo.EnhancedMonitoring.Value))
+			}
+
+			if clusterInfo.BrokerNodeGroup != nil {
+				brokerBlock := mskBody.AppendNewBlock(MSK_BROKER_NODE_GROUP_INFO,
+					nil)
+				brokerBody := brokerBlock.Body()
+				brokerBody.SetAttributeValue(MSK_INSTANCE_TYPE,
+					cty.StringVal(clusterInfo.BrokerNodeGroup.InstanceType))
+				if clusterInfo.BrokerNodeGroup.Subnets != nil && len(*clusterInfo.BrokerNodeGroup.Subnets) > 0 {
+					var vals []cty.Value
+					for _, subnetId := range *clusterInfo.BrokerNodeGroup.Subnets {
+						vals = append(vals, cty.StringVal(subnetId))
+					}
+					brokerBody.SetAttributeValue(MSK_CLIENT_SUBNETS,
+						cty.ListVal(vals))
+				}
+				if clusterInfo.BrokerNodeGroup.SecurityGroups != nil && len(*clusterInfo.BrokerNodeGroup.SecurityGroups) > 0 {
+					sgTokens, err := getSecurityGroupIdsTokens(config, ec2Client, *clusterInfo.BrokerNodeGroup.SecurityGroups)
+					if err != nil {
+						fmt.Println(err)
+						return &tfContext, common.NewResourceError(resourceName, err)
+					}
+					brokerBody.SetAttributeRaw(MSK_SECURITY_GROUPS, sgTokens)
+				}
+				if clusterInfo.BrokerNodeGroup.AZDistribution != nil && len(clusterInfo.BrokerNodeGroup.AZDistribution.Value) > 0 {
+					brokerBody.SetAttributeValue(MSK_AZ_DISTRIBUTION,
+						cty.StringVal(clusterInfo.BrokerNodeGroup.AZDistribution.Value))
+				}
+				storageBlock := brokerBody.AppendNewBlock(MSK_STORAGE_INFO,
+					nil)
+				ebsStorageBlock := storageBlock.Body().AppendNewBlock(MSK_EBS_STORAGE_INFO,
+					nil)
+				ebsStorageBlock.Body().SetAttributeValue(MSK_VOLUME_SIZE,
+					cty.NumberIntVal(int64(clusterInfo.BrokerNodeGroup.StorageInfo.EbsStorageInfo.VolumeSize)))
+			}
+
+			if clusterInfo.EncryptionInfo != nil {
+				encryptionBlock := mskBody.AppendNewBlock(MSK_ENCRYPTION_INFO,
+					nil)
+				encryptionBody := encryptionBlock.Body()
+				if clusterInfo.EncryptionInfo.AtRest != nil {
+					if isTenantKmsKey(tenantKms, clusterInfo.EncryptionInfo.AtRest.KmsKeyID) {
+						if err := common.SetAttributeReference(encryptionBody, MSK_ENCRYPTION_AT_REST_KMS_KEY_ARN, AWS_KMS_KEY+"."+TENANT_KMS+".arn"); err != nil {
+							fmt.Println(err)
+							return &tfContext, common.NewResourceError(resourceName, err)
+						}
+					} else if len(clusterInfo.EncryptionInfo.AtRest.KmsKeyID) > 0 {
+						encryptionBody.SetAttributeValue(MSK_ENCRYPTION_AT_REST_KMS_KEY_ARN,
+							cty.StringVal(clusterInfo.EncryptionInfo.AtRest.KmsKeyID))
+					}
+				}
+				if clusterInfo.EncryptionInfo.InTransit != nil {
+					inTransitBlock := encryptionBody.AppendNewBlock(MSK_ENCRYPTION_IN_TRANSIT,
+						nil)
+					inTransitBody := inTransitBlock.Body()
+					if clusterInfo.EncryptionInfo.InTransit.ClientBroker != nil && len(clusterInfo.EncryptionInfo.InTransit.ClientBroker.Value) > 0 {
+						inTransitBody.SetAttributeValue(MSK_CLIENT_BROKER,
+							cty.StringVal(clusterInfo.EncryptionInfo.InTransit.ClientBroker.Value))
+					}
+					inTransitBody.SetAttributeValue(MSK_IN_CLUSTER,
+						cty.BoolVal(clusterInfo.EncryptionInfo.InTransit.InCluster))
+				}
+			}
+
+			if describeClusterOutput.ClusterInfo != nil && describeClusterOutput.ClusterInfo.ClientAuthentication != nil {
+				clientAuth := describeClusterOutput.ClusterInfo.ClientAuthentication
+				clientAuthBlock := mskBody.AppendNewBlock(MSK_CLIENT_AUTHENTICATION,
+					nil)
+				clientAuthBody := clientAuthBlock.Body()
+				if clientAuth.Sasl != nil {
+					saslBlock := clientAuthBody.AppendNewBlock(MSK_SASL,
+						nil)
+					saslBody := saslBlock.Body()
+					if clientAuth.Sasl.Iam != nil {
+						saslBody.SetAttributeValue(MSK_IAM,
+							cty.BoolVal(clientAuth.Sasl.Iam.Enabled))
+					}
+					if clientAuth.Sasl.Scram != nil {
+						saslBody.SetAttributeValue(MSK_SCRAM,
+							cty.BoolVal(clientAuth.Sasl.Scram.Enabled))
+					}
+				}
+				if clientAuth.Tls != nil && clientAuth.Tls.Enabled {
+					tlsBlock := clientAuthBody.AppendNewBlock(MSK_TLS,
+						nil)
+					if len(clientAuth.Tls.CertificateAuthorityArnList) > 0 {
+						var vals []cty.Value
+						for _, caArn := range clientAuth.Tls.CertificateAuthorityArnList {
+							vals = append(vals, cty.StringVal(caArn))
+						}
+						tlsBlock.Body().SetAttributeValue(MSK_CERTIFICATE_AUTHORITY_ARNS,
+							cty.ListVal(vals))
+					}
+				}
+				if clientAuth.Unauthenticated != nil {
+					clientAuthBody.SetAttributeValue(MSK_UNAUTHENTICATED,
+						cty.BoolVal(clientAuth.Unauthenticated.Enabled))
+				}
+			}
+
+			if len(configurationResourceName) > 0 {
+				configurationInfoBlock := mskBody.AppendNewBlock(MSK_CONFIGURATION_INFO,
+					nil)
+				configurationInfoBody := configurationInfoBlock.Body()
+				if err := common.SetAttributeReference(configurationInfoBody, MSK_ARN, AWS_MSK_CONFIGURATION+"."+configurationResourceName+".arn"); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				if err := common.SetAttributeReference(configurationInfoBody, MSK_REVISION, AWS_MSK_CONFIGURATION+"."+configurationResourceName+".latest_revision"); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+			}
+
+			if clusterInfo.OpenMonitoring != nil && clusterInfo.OpenMonitoring.Prometheus != nil {
+				prometheus := clusterInfo.OpenMonitoring.Prometheus
+				openMonitoringBlock := mskBody.AppendNewBlock(MSK_OPEN_MONITORING,
+					nil)
+				prometheusBlock := openMonitoringBlock.Body().AppendNewBlock(MSK_PROMETHEUS,
+					nil)
+				prometheusBody := prometheusBlock.Body()
+				if prometheus.JmxExporter != nil {
+					jmxBlock := prometheusBody.AppendNewBlock(MSK_JMX_EXPORTER,
+						nil)
+					jmxBlock.Body().SetAttributeValue(MSK_ENABLED_IN_BROKER,
+						cty.BoolVal(prometheus.JmxExporter.EnabledInBroker))
+				}
+				if prometheus.NodeExporter != nil {
+					nodeBlock := prometheusBody.AppendNewBlock(MSK_NODE_EXPORTER,
+						nil)
+					nodeBlock.Body().SetAttributeValue(MSK_ENABLED_IN_BROKER,
+						cty.BoolVal(prometheus.NodeExporter.EnabledInBroker))
+				}
+			}
+
+			if len(clusterInfo.Tags) > 0 {
+				tags := map[string]common.Template{}
+				for key, value := range clusterInfo.Tags {
+					if common.IsTagAwsManaged(key) {
+						continue
+					}
+					tagValue := common.Interpolate(fmt.Sprint(value), common.Interpolation{Value: config.TenantName, Expr: "local.tenant_name"})
+					tags[key] = tagValue
+				}
+				if err := common.SetAttributeTags(mskBody, TAGS, tags); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+			}
+
+			if config.GenerateTfState {
+				importConfigs = append(importConfigs, common.ImportConfig{
+					ResourceAddress: strings.Join([]string{
+						AWS_MSK_CLUSTER,
+						resourceName,
+					}, "."),
+					ResourceId: clusterInfo.Arn,
+					WorkingDir: workingDir,
+				})
+				tfContext.ImportConfigs = importConfigs
+			}
+
+			_, err = tfFile.Write(hclFile.Bytes())
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			log.Printf("[TRACE] Terraform config is generated for msk cluster : %s", shortName)
+
+			bootstrapBrokers, clientErr := client.TenantGetKafkaClusterBootstrapBrokers(config.TenantId, clusterInfo.Arn)
+			if clientErr != nil {
+				fmt.Println(clientErr)
+				return &tfContext, common.NewResourceError(resourceName, clientErr)
+			}
+			if bootstrapBrokers != nil && len(bootstrapBrokers.BootstrapBrokerString) > 0 {
+				tfContext.OutputVars = append(tfContext.OutputVars, common.OutputVarConfig{
+					Name: varFullPrefix + "bootstrap_brokers",
+					ActualVal: strings.Join([]string{
+						AWS_MSK_CLUSTER,
+						resourceName,
+						"bootstrap_brokers",
+					}, "."),
+					DescVal:       "Plaintext bootstrap broker connection string of the MSK cluster.",
+					RootTraversal: true,
+				})
+			}
+			if bootstrapBrokers != nil && len(bootstrapBrokers.BootstrapBrokerStringTls) > 0 {
+				tfContext.OutputVars = append(tfContext.OutputVars, common.OutputVarConfig{
+					Name: varFullPrefix + "bootstrap_brokers_tls",
+					ActualVal: strings.Join([]string{
+						AWS_MSK_CLUSTER,
+						resourceName,
+						"bootstrap_brokers_tls",
+					}, "."),
+					DescVal:       "TLS bootstrap broker connection string of the MSK cluster.",
+					RootTraversal: true,
+				})
+			}
+		}
+		log.Println("[TRACE] <====== MSK cluster TF generation done. =====>")
+	}
+	return &tfContext, nil
+}
@@ -0,0 +1,302 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/mwaa"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"tenant-native-terraform-generator/duplosdk"
+	"tenant-native-terraform-generator/tf-generator/common"
+)
+
+const (
+	MWAA_NAME                            string = "name"
+	MWAA_AIRFLOW_VERSION                 string = "airflow_version"
+	MWAA_ENVIRONMENT_CLASS               string = "environment_class"
+	MWAA_EXECUTION_ROLE_ARN              string = "execution_role_arn"
+	MWAA_KMS_KEY                         string = "kms_key"
+	MWAA_SOURCE_BUCKET_ARN               string = "source_bucket_arn"
+	MWAA_DAG_S3_PATH                     string = "dag_s3_path"
+	MWAA_PLUGINS_S3_PATH                 string = "plugins_s3_path"
+	MWAA_PLUGINS_S3_OBJECT_VERSION       string = "plugins_s3_object_version"
+	MWAA_REQUIREMENTS_S3_PATH            string = "requirements_s3_path"
+	MWAA_REQUIREMENTS_S3_OBJECT_VERSION  string = "requirements_s3_object_version"
+	MWAA_MAX_WORKERS                     string = "max_workers"
+	MWAA_MIN_WORKERS                     string = "min_workers"
+	MWAA_SCHEDULERS                      string = "schedulers"
+	MWAA_WEBSERVER_ACCESS_MODE           string = "webserver_access_mode"
+	MWAA_WEEKLY_MAINTENANCE_WINDOW_START string = "weekly_maintenance_window_start"
+	MWAA_AIRFLOW_CONFIGURATION_OPTIONS   string = "airflow_configuration_options"
+	MWAA_LOGGING_CONFIGURATION           string = "logging_configuration"
+	MWAA_ENABLED                         string = "enabled"
+	MWAA_LOG_LEVEL                       string = "log_level"
+	MWAA_NETWORK_CONFIGURATION           string = "network_configuration"
+	MWAA_SECURITY_GROUP_IDS              string = "security_group_ids"
+	MWAA_SUBNET_IDS                      string = "subnet_ids"
+)
+
+const AWS_MWAA_ENVIRONMENT = "aws_mwaa_environment"
+const MWAA_VAR_PREFIX = "mwaa_"
+const MWAA_FILE_NAME_PREFIX = "aws-mwaa-"
+
+type AwsMwaaEnvironment struct {
+}
+
+func (awsMwaaEnvironment *AwsMwaaEnvironment) Generate(config *common.Config, client *duplosdk.Client) (*common.TFContext, error) {
+	workingDir := filepath.Join(config.TFCodePath, config.TenantProject)
+	list, clientErr := client.MwaaAirflowList(config.TenantId)
+
+	if clientErr != nil {
+		fmt.Println(clientErr)
+		return nil, clientErr
+	}
+	tfContext := common.TFContext{}
+	importConfigs := []common.ImportConfig{}
+	if list != nil && len(*list) > 0 {
+		log.Println("[TRACE] <====== MWAA environment TF generation started. =====>")
+		tenantKms, clientErr := client.TenantGetTenantKmsKey(config.TenantId)
+		if clientErr != nil {
+			fmt.Println(clientErr)
+			return &tfContext, clientErr
+		}
+		mwaaClient := mwaa.NewFromConfig(config.AwsClientConfig)
+		ec2Client := ec2.NewFromConfig(config.AwsClientConfig)
+		for _, summary := range *list {
+			airflow, clientErr := client.MwaaAirflowDetailsGet(config.TenantId, summary.Name)
+			if clientErr != nil {
+				fmt.Println(clientErr)
+				return &tfContext, clientErr
+			}
+			shortName := strings.TrimPrefix(airflow.Name, "duploservices-"+config.TenantName+"-")
+			resourceName := common.GetResourceName(shortName)
+			varFullPrefix := MWAA_VAR_PREFIX + resourceName + "_"
+
+			getEnvironmentOutput, err := mwaaClient.GetEnvironment(context.TODO(), &mwaa.GetEnvironmentInput{Name: &airflow.Name})
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+
+			hclFile := hclwrite.NewEmptyFile()
+			path := filepath.Join(workingDir, MWAA_FILE_NAME_PREFIX+shortName+".tf")
+			tfFile, err := os.Create(path)
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			rootBody := hclFile.Body()
+
+			// Add aws_mwaa_environment resource
+			mwaaBlock := rootBody.AppendNewBlock("resource",
+				[]string{AWS_MWAA_ENVIRONMENT,
+					resourceName})
+			mwaaBody := mwaaBlock.Body()
+			airflowName := common.Interpolate(airflow.Name)
+			if shortName != airflow.Name {
+				airflowName = common.Template("${local.tenant_prefix}-" + common.EscapeTemplate(shortName))
+			}
+			if err := common.SetAttributeTemplate(mwaaBody, MWAA_NAME, airflowName); err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			mwaaBody.SetAttributeValue(MWAA_AIRFLOW_VERSION,
+				cty.StringVal(airflow.AirflowVersion))
+			mwaaBody.SetAttributeValue(MWAA_ENVIRONMENT_CLASS,
+				cty.StringVal(airflow.EnvironmentClass))
+			mwaaBody.SetAttributeValue(MWAA_EXECUTION_ROLE_ARN,
+				cty.StringVal(airflow.ExecutionRoleArn))
+			if isTenantKmsKey(tenantKms, airflow.KmsKey) {
+				if err := common.SetAttributeReference(mwaaBody, MWAA_KMS_KEY, AWS_KMS_KEY+"."+TENANT_KMS+".arn"); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+			} else if len(airflow.KmsKey) > 0 {
+				mwaaBody.SetAttributeValue(MWAA_KMS_KEY,
+					cty.StringVal(airflow.KmsKey))
+			}
+			mwaaBody.SetAttributeValue(MWAA_SOURCE_BUCKET_ARN,
+				cty.StringVal(airflow.SourceBucketArn))
+			mwaaBody.SetAttributeValue(MWAA_DAG_S3_PATH,
+				cty.StringVal(airflow.DagS3Path))
+			if len(airflow.PluginsS3Path) > 0 {
+				mwaaBody.SetAttributeValue(MWAA_PLUGINS_S3_PATH,
+					cty.StringVal(airflow.PluginsS3Path))
+				if err := common.SetAttributeReference(mwaaBody, MWAA_PLUGINS_S3_OBJECT_VERSION, "var."+varFullPrefix+MWAA_PLUGINS_S3_OBJECT_VERSION); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+			}
+			if len(airflow.RequirementsS3Path) > 0 {
+				mwaaBody.SetAttributeValue(MWAA_REQUIREMENTS_S3_PATH,
+					cty.StringVal(airflow.RequirementsS3Path))
+				if err := common.SetAttributeReference(mwaaBody, MWAA_REQUIREMENTS_S3_OBJECT_VERSION, "var."+varFullPrefix+MWAA_REQUIREMENTS_S3_OBJECT_VERSION); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+			}
+			if airflow.MaxWorkers > 0 {
+				mwaaBody.SetAttributeValue(MWAA_MAX_WORKERS,
+					cty.NumberIntVal(int64(airflow.MaxWorkers)))
+			}
+			if airflow.MinWorkers > 0 {
+				mwaaBody.SetAttributeValue(MWAA_MIN_WORKERS,
+					cty.NumberIntVal(int64(airflow.MinWorkers)))
+			}
+			if airflow.Schedulers > 0 {
+				mwaaBody.SetAttributeValue(MWAA_SCHEDULERS,
+					cty.NumberIntVal(int64(airflow.Schedulers)))
+			}
+			if airflow.WebserverAccessMode != nil && len(airflow.WebserverAccessMode.Value) > 0 {
+				mwaaBody.SetAttributeValue(MWAA_WEBSERVER_ACCESS_MODE,
+					cty.StringVal(airflow.WebserverAccessMode.Value))
+			}
+			if len(airflow.WeeklyMaintenanceWindowStart) > 0 {
+				mwaaBody.SetAttributeValue(MWAA_WEEKLY_MAINTENANCE_WINDOW_START,
+					cty.StringVal(airflow.WeeklyMaintenanceWindowStart))
+			}
+			if len(airflow.AirflowConfigurationOptions) > 0 {
+				configurationOptions := map[string]cty.Value{}
+				for key, value := range airflow.AirflowConfigurationOptions {
+					configurationOptions[key] = cty.StringVal(value)
+				}
+				mwaaBody.SetAttributeValue(MWAA_AIRFLOW_CONFIGURATION_OPTIONS,
+					cty.MapVal(configurationOptions))
+			}
+
+			if airflow.LoggingConfiguration != nil {
+				loggingBlock := mwaaBody.AppendNewBlock(MWAA_LOGGING_CONFIGURATION,
+					nil)
+				loggingBody := loggingBlock.Body()
+				appendMwaaLogsBlock(loggingBody, "dag_processing_logs", airflow.LoggingConfiguration.DagProcessingLogs)
+				appendMwaaLogsBlock(loggingBody, "scheduler_logs", airflow.LoggingConfiguration.SchedulerLogs)
+				appendMwaaLogsBlock(loggingBody, "task_logs", airflow.LoggingConfiguration.TaskLogs)
+				appendMwaaLogsBlock(loggingBody, "webserver_logs", airflow.LoggingConfiguration.WebserverLogs)
+				appendMwaaLogsBlock(loggingBody, "worker_logs", airflow.LoggingConfiguration.WorkerLogs)
+			}
+
+			if getEnvironmentOutput.Environment != nil && getEnvironmentOutput.Environment.NetworkConfiguration != nil {
+				networkConfiguration := getEnvironmentOutput.Environment.NetworkConfiguration
+				networkBlock := mwaaBody.AppendNewBlock(MWAA_NETWORK_CONFIGURATION,
+					nil)
+				networkBody := networkBlock.Body()
+				if len(networkConfiguration.SecurityGroupIds) > 0 {
+					sgTokens, err := getSecurityGroupIdsTokens(config, ec2Client, networkConfiguration.SecurityGroupIds)
+					if err != nil {
+						fmt.Println(err)
+						return &tfContext, common.NewResourceError(resourceName, err)
+					}
+					networkBody.SetAttributeRaw(MWAA_SECURITY_GROUP_IDS, sgTokens)
+				}
+				if len(networkConfiguration.SubnetIds) > 0 {
+					var vals []cty.Value
+					for _, subnetId := range networkConfiguration.SubnetIds {
+						vals = append(vals, cty.StringVal(subnetId))
+					}
+					networkBody.SetAttributeValue(MWAA_SUBNET_IDS,
+						cty.ListVal(vals))
+				}
+			}
+
+			if len(airflow.Tags) > 0 {
+				tags := map[string]common.Template{}
+				for key, value := range airflow.Tags {
+					if common.IsTagAwsManaged(key) {
+						continue
+					}
+					tagValue := common.Interpolate(fmt.Sprint(value), common.Interpolation{Value: config.TenantName, Expr: "local.tenant_name"})
+					tags[key] = tagValue
+				}
+				if err := common.SetAttributeTags(mwaaBody, TAGS, tags); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+			}
+
+			if config.GenerateTfState {
+				importConfigs = append(importConfigs, common.ImportConfig{
+					ResourceAddress: strings.Join([]string{
+						AWS_MWAA_ENVIRONMENT,
+						resourceName,
+					}, "."),
+					ResourceId: airflow.Name,
+					WorkingDir: workingDir,
+				})
+				tfContext.ImportConfigs = importConfigs
+			}
+
+			_, err = tfFile.Write(hclFile.Bytes())
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			log.Printf("[TRACE] Terraform config is generated for mwaa environment : %s", shortName)
+
+			tfContext.InputVars = append(tfContext.InputVars, generateMwaaVars(airflow, varFullPrefix)...)
+			tfContext.OutputVars = append(tfContext.OutputVars, common.OutputVarConfig{
+				Name: varFullPrefix + "webserver_url",
+				ActualVal: strings.Join([]string{
+					AWS_MWAA_ENVIRONMENT,
+					resourceName,
+					"webserver_url",
+				}, "."),
+				DescVal:       "The webserver URL of the MWAA environment.",
+				RootTraversal: true,
+			})
+		}
+		log.Println("[TRACE] <====== MWAA environment TF generation done. =====>")
+	}
+	return &tfContext, nil
+}
+
+func appendMwaaLogsBlock(loggingBody *hclwrite.Body, blockName string, logs *duplosdk.DuploLoggingConfigurationInput) {
+	if logs == nil {
+		return
+	}
+	logsBlock := loggingBody.AppendNewBlock(blockName,
+		nil)
+	logsBody := logsBlock.Body()
+	logsBody.SetAttributeValue(MWAA_ENABLED,
+		cty.BoolVal(logs.Enabled))
+	if logs.LogLevel != nil && len(logs.LogLevel.Value) > 0 {
+		logsBody.SetAttributeValue(MWAA_LOG_LEVEL,
+			cty.StringVal(logs.LogLevel.Value))
+	}
+}
+
+func generateMwaaVars(airflow *duplosdk.DuploMwaaAirflowDetail, prefix string) []common.VarConfig {
+	varConfigs := []common.VarConfig{}
+	// Unversioned source buckets have no object version, so fall back to null instead of a required input.
+	pluginsVersion := airflow.PluginsS3ObjectVersion
+	if len(pluginsVersion) == 0 {
+		pluginsVersion = "null"
+	}
+	requirementsVersion := airflow.RequirementsS3ObjectVersion
+	if len(requirementsVersion) == 0 {
+		requirementsVersion = "null"
+	}
+	if len(airflow.PluginsS3Path) > 0 {
+		varConfigs = append(varConfigs, common.VarConfig{
+			Name:       prefix + MWAA_PLUGINS_S3_OBJECT_VERSION,
+			DefaultVal: pluginsVersion,
+			TypeVal:    "string",
+			DescVal:    "S3 object version of the plugins archive of the MWAA environment " + airflow.Name + ".",
+		})
+	}
+	if len(airflow.RequirementsS3Path) > 0 {
+		varConfigs = append(varConfigs, common.VarConfig{
+			Name:       prefix + MWAA_REQUIREMENTS_S3_OBJECT_VERSION,
+			DefaultVal: requirementsVersion,
+			TypeVal:    "string",
+			DescVal:    "S3 object version of the requirements file of the MWAA environment " + airflow.Name + ".",
+		})
+	}
+	return varConfigs
+}
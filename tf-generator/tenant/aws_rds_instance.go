@@ -0,0 +1,407 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	awsrds "github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"tenant-native-terraform-generator/duplosdk"
+	"tenant-native-terraform-generator/tf-generator/common"
+)
+
+const (
+	RDS_IDENTIFIER              string = "identifier"
+	RDS_CLUSTER_IDENTIFIER      string = "cluster_identifier"
+	RDS_ENGINE_MODE             string = "engine_mode"
+	RDS_INSTANCE_CLASS          string = "instance_class"
+	RDS_ALLOCATED_STORAGE       string = "allocated_storage"
+	RDS_USERNAME                string = "username"
+	RDS_PASSWORD                string = "password"
+	RDS_MASTER_USERNAME         string = "master_username"
+	RDS_MASTER_PASSWORD         string = "master_password"
+	RDS_SNAPSHOT_IDENTIFIER     string = "snapshot_identifier"
+	RDS_STORAGE_ENCRYPTED       string = "storage_encrypted"
+	RDS_MULTI_AZ                string = "multi_az"
+	RDS_DB_PARAMETER_GROUP_NAME string = "db_parameter_group_name"
+	RDS_SKIP_FINAL_SNAPSHOT     string = "skip_final_snapshot"
+	RDS_FINAL_SNAPSHOT_ID       string = "final_snapshot_identifier"
+	RDS_DELETION_PROTECTION     string = "deletion_protection"
+)
+
+const AWS_DB_INSTANCE = "aws_db_instance"
+const AWS_RDS_CLUSTER = "aws_rds_cluster"
+const AWS_RDS_CLUSTER_INSTANCE = "aws_rds_cluster_instance"
+const RDS_VAR_PREFIX = "rds_"
+const RDS_FILE_NAME_PREFIX = "aws-rds-"
+const RDS_FINAL_SNAPSHOT_SUFFIX = "-final-snapshot"
+
+type AwsRdsInstance struct {
+}
+
+func (awsRdsInstance *AwsRdsInstance) Generate(config *common.Config, client *duplosdk.Client) (*common.TFContext, error) {
+	workingDir := filepath.Join(config.TFCodePath, config.TenantProject)
+	list, clientErr := client.RdsInstanceList(config.TenantId)
+
+	if clientErr != nil {
+		fmt.Println(clientErr)
+		return nil, clientErr
+	}
+	tfContext := common.TFContext{}
+	importConfigs := []common.ImportConfig{}
+	if list != nil && len(*list) > 0 {
+		log.Println("[TRACE] <====== RDS instance TF generation started. =====>")
+		rdsClient := awsrds.NewFromConfig(config.AwsClientConfig)
+		for _, rds := range *list {
+			engine := getRdsEngineName(rds.Engine)
+			if len(engine) == 0 {
+				log.Printf("[TRACE] Skipping rds instance (%s), unsupported engine type (%d).", rds.Identifier, rds.Engine)
+				continue
+			}
+			shortName := strings.TrimPrefix(rds.Identifier, "duplo")
+			resourceName := common.GetResourceName(shortName)
+			varFullPrefix := RDS_VAR_PREFIX + resourceName + "_"
+
+			details, err := describeRdsInstance(rdsClient, rds)
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+
+			hclFile := hclwrite.NewEmptyFile()
+			path := filepath.Join(workingDir, RDS_FILE_NAME_PREFIX+shortName+".tf")
+			tfFile, err := os.Create(path)
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			rootBody := hclFile.Body()
+
+			if isAuroraRdsEngine(rds.Engine) {
+				// Aurora is managed as a cluster with a writer instance.
+				clusterIdentifier := details.clusterIdentifier
+				clusterBlock := rootBody.AppendNewBlock("resource",
+					[]string{AWS_RDS_CLUSTER,
+						resourceName})
+				clusterBody := clusterBlock.Body()
+				tfContext.References = append(tfContext.References, common.ResourceReference{
+					Value:      clusterIdentifier,
+					Address:    AWS_RDS_CLUSTER + "." + resourceName + ".id",
+					Attributes: []string{RDS_CLUSTER_IDENTIFIER},
+				})
+				if len(details.clusterArn) > 0 {
+					tfContext.References = append(tfContext.References, common.ResourceReference{
+						Value:   details.clusterArn,
+						Address: AWS_RDS_CLUSTER + "." + resourceName + ".arn",
+					})
+				}
+				clusterBody.SetAttributeValue(RDS_CLUSTER_IDENTIFIER,
+					cty.StringVal(clusterIdentifier))
+				clusterBody.SetAttributeValue(ENGINE,
+					cty.StringVal(engine))
+				if isAuroraServerlessRdsEngine(rds.Engine) {
+					clusterBody.SetAttributeValue(RDS_ENGINE_MODE,
+						cty.StringVal("serverless"))
+				}
+				if len(rds.EngineVersion) > 0 {
+					clusterBody.SetAttributeValue(ENGINE_VERSION,
+						cty.StringVal(rds.EngineVersion))
+				}
+				if len(rds.MasterUsername) > 0 {
+					clusterBody.SetAttributeValue(RDS_MASTER_USERNAME,
+						cty.StringVal(rds.MasterUsername))
+				}
+				if err := common.SetAttributeReference(clusterBody, RDS_MASTER_PASSWORD, "var."+varFullPrefix+"master_password"); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				if len(rds.SnapshotID) > 0 {
+					clusterBody.SetAttributeValue(RDS_SNAPSHOT_IDENTIFIER,
+						cty.StringVal(rds.SnapshotID))
+				}
+				clusterBody.SetAttributeValue(RDS_STORAGE_ENCRYPTED,
+					cty.BoolVal(rds.EncryptStorage))
+				clusterBody.SetAttributeValue(RDS_DELETION_PROTECTION,
+					cty.BoolVal(details.deletionProtection))
+				clusterBody.SetAttributeValue(RDS_SKIP_FINAL_SNAPSHOT,
+					cty.BoolVal(false))
+				clusterBody.SetAttributeValue(RDS_FINAL_SNAPSHOT_ID,
+					cty.StringVal(clusterIdentifier+RDS_FINAL_SNAPSHOT_SUFFIX))
+
+				if config.GenerateTfState {
+					importConfigs = append(importConfigs, common.ImportConfig{
+						ResourceAddress: strings.Join([]string{
+							AWS_RDS_CLUSTER,
+							resourceName,
+						}, "."),
+						ResourceId: clusterIdentifier,
+						WorkingDir: workingDir,
+					})
+					tfContext.ImportConfigs = importConfigs
+				}
+
+				if !isAuroraServerlessRdsEngine(rds.Engine) {
+					rootBody.AppendNewline()
+					instanceBlock := rootBody.AppendNewBlock("resource",
+						[]string{AWS_RDS_CLUSTER_INSTANCE,
+							resourceName})
+					instanceBody := instanceBlock.Body()
+					if len(details.instanceArn) > 0 {
+						tfContext.References = append(tfContext.References, common.ResourceReference{
+							Value:   details.instanceArn,
+							Address: AWS_RDS_CLUSTER_INSTANCE + "." + resourceName + ".arn",
+						})
+					}
+					instanceBody.SetAttributeValue(RDS_IDENTIFIER,
+						cty.StringVal(rds.Identifier))
+					if err := common.SetAttributeReference(instanceBody, RDS_CLUSTER_IDENTIFIER, AWS_RDS_CLUSTER+"."+resourceName+".id"); err != nil {
+						fmt.Println(err)
+						return &tfContext, common.NewResourceError(resourceName, err)
+					}
+					if err := common.SetAttributeReference(instanceBody, RDS_INSTANCE_CLASS, "var."+varFullPrefix+"instance_class"); err != nil {
+						fmt.Println(err)
+						return &tfContext, common.NewResourceError(resourceName, err)
+					}
+					if err := common.SetAttributeReference(instanceBody, ENGINE, AWS_RDS_CLUSTER+"."+resourceName+".engine"); err != nil {
+						fmt.Println(err)
+						return &tfContext, common.NewResourceError(resourceName, err)
+					}
+					if err := common.SetAttributeReference(instanceBody, ENGINE_VERSION, AWS_RDS_CLUSTER+"."+resourceName+".engine_version"); err != nil {
+						fmt.Println(err)
+						return &tfContext, common.NewResourceError(resourceName, err)
+					}
+					if len(rds.DBParameterGroupName) > 0 {
+						instanceBody.SetAttributeValue(RDS_DB_PARAMETER_GROUP_NAME,
+							cty.StringVal(rds.DBParameterGroupName))
+					}
+
+					if config.GenerateTfState {
+						importConfigs = append(importConfigs, common.ImportConfig{
+							ResourceAddress: strings.Join([]string{
+								AWS_RDS_CLUSTER_INSTANCE,
+								resourceName,
+							}, "."),
+							ResourceId: rds.Identifier,
+							WorkingDir: workingDir,
+						})
+						tfContext.ImportConfigs = importConfigs
+					}
+				}
+				tfContext.OutputVars = append(tfContext.OutputVars, generateRdsOutputVars(varFullPrefix, AWS_RDS_CLUSTER, resourceName)...)
+			} else {
+				rdsBlock := rootBody.AppendNewBlock("resource",
+					[]string{AWS_DB_INSTANCE,
+						resourceName})
+				rdsBody := rdsBlock.Body()
+				if len(details.instanceArn) > 0 {
+					tfContext.References = append(tfContext.References, common.ResourceReference{
+						Value:   details.instanceArn,
+						Address: AWS_DB_INSTANCE + "." + resourceName + ".arn",
+					})
+				}
+				rdsBody.SetAttributeValue(RDS_IDENTIFIER,
+					cty.StringVal(rds.Identifier))
+				rdsBody.SetAttributeValue(ENGINE,
+					cty.StringVal(engine))
+				if len(rds.EngineVersion) > 0 {
+					rdsBody.SetAttributeValue(ENGINE_VERSION,
+						cty.StringVal(rds.EngineVersion))
+				}
+				if err := common.SetAttributeReference(rdsBody, RDS_INSTANCE_CLASS, "var."+varFullPrefix+"instance_class"); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				if err := common.SetAttributeReference(rdsBody, RDS_ALLOCATED_STORAGE, "var."+varFullPrefix+"allocated_storage"); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				if len(rds.MasterUsername) > 0 {
+					rdsBody.SetAttributeValue(RDS_USERNAME,
+						cty.StringVal(rds.MasterUsername))
+				}
+				if err := common.SetAttributeReference(rdsBody, RDS_PASSWORD, "var."+varFullPrefix+"master_password"); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				if len(rds.SnapshotID) > 0 {
+					rdsBody.SetAttributeValue(RDS_SNAPSHOT_IDENTIFIER,
+						cty.StringVal(rds.SnapshotID))
+				}
+				if len(rds.DBParameterGroupName) > 0 {
+					rdsBody.SetAttributeValue(PARAMETER_GROUP_NAME,
+						cty.StringVal(rds.DBParameterGroupName))
+				}
+				rdsBody.SetAttributeValue(RDS_STORAGE_ENCRYPTED,
+					cty.BoolVal(rds.EncryptStorage))
+				rdsBody.SetAttributeValue(RDS_MULTI_AZ,
+					cty.BoolVal(rds.MultiAZ))
+				rdsBody.SetAttributeValue(RDS_DELETION_PROTECTION,
+					cty.BoolVal(details.deletionProtection))
+				rdsBody.SetAttributeValue(RDS_SKIP_FINAL_SNAPSHOT,
+					cty.BoolVal(false))
+				rdsBody.SetAttributeValue(RDS_FINAL_SNAPSHOT_ID,
+					cty.StringVal(rds.Identifier+RDS_FINAL_SNAPSHOT_SUFFIX))
+
+				if config.GenerateTfState {
+					importConfigs = append(importConfigs, common.ImportConfig{
+						ResourceAddress: strings.Join([]string{
+							AWS_DB_INSTANCE,
+							resourceName,
+						}, "."),
+						ResourceId: rds.Identifier,
+						WorkingDir: workingDir,
+					})
+					tfContext.ImportConfigs = importConfigs
+				}
+				tfContext.OutputVars = append(tfContext.OutputVars, generateRdsOutputVars(varFullPrefix, AWS_DB_INSTANCE, resourceName)...)
+			}
+
+			inputVars := generateRdsVars(rds, details, varFullPrefix)
+			tfContext.InputVars = append(tfContext.InputVars, inputVars...)
+
+			_, err = tfFile.Write(hclFile.Bytes())
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			log.Printf("[TRACE] Terraform config is generated for rds instance : %s", shortName)
+		}
+		log.Println("[TRACE] <====== RDS instance TF generation done. =====>")
+	}
+	return &tfContext, nil
+}
+
+func getRdsEngineName(engine int) string {
+	switch engine {
+	case duplosdk.DUPLO_RDS_ENGINE_MYSQL:
+		return "mysql"
+	case duplosdk.DUPLO_RDS_ENGINE_POSTGRESQL:
+		return "postgres"
+	case duplosdk.DUPLO_RDS_ENGINE_MSSQL_EXPRESS:
+		return "sqlserver-ex"
+	case duplosdk.DUPLO_RDS_ENGINE_MSSQL_STANDARD:
+		return "sqlserver-se"
+	case duplosdk.DUPLO_RDS_ENGINE_MSSQL_WEB:
+		return "sqlserver-web"
+	case duplosdk.DUPLO_RDS_ENGINE_AURORA_MYSQL, duplosdk.DUPLO_RDS_ENGINE_AURORA_SERVERLESS_MYSQL:
+		return "aurora-mysql"
+	case duplosdk.DUPLO_RDS_ENGINE_AURORA_POSTGRESQL, duplosdk.DUPLO_RDS_ENGINE_AURORA_SERVERLESS_POSTGRESQL:
+		return "aurora-postgresql"
+	}
+	return ""
+}
+
+func isAuroraRdsEngine(engine int) bool {
+	return engine == duplosdk.DUPLO_RDS_ENGINE_AURORA_MYSQL ||
+		engine == duplosdk.DUPLO_RDS_ENGINE_AURORA_POSTGRESQL ||
+		isAuroraServerlessRdsEngine(engine)
+}
+
+func isAuroraServerlessRdsEngine(engine int) bool {
+	return engine == duplosdk.DUPLO_RDS_ENGINE_AURORA_SERVERLESS_MYSQL ||
+		engine == duplosdk.DUPLO_RDS_ENGINE_AURORA_SERVERLESS_POSTGRESQL
+}
+
+// rdsInstanceDetails holds the settings Duplo does not report, read from AWS.
+type rdsInstanceDetails struct {
+	instanceArn        string
+	clusterIdentifier  string
+	clusterArn         string
+	allocatedStorage   int32
+	deletionProtection bool
+}
+
+// describeRdsInstance reads the cluster, storage and deletion protection settings of an RDS instance.
+// Aurora serverless has no instances, so its Duplo identifier names the cluster.
+func describeRdsInstance(rdsClient *awsrds.Client, rds duplosdk.DuploRdsInstance) (*rdsInstanceDetails, error) {
+	details := &rdsInstanceDetails{}
+	if isAuroraServerlessRdsEngine(rds.Engine) {
+		details.clusterIdentifier = rds.Identifier
+	} else {
+		output, err := rdsClient.DescribeDBInstances(context.TODO(), &awsrds.DescribeDBInstancesInput{DBInstanceIdentifier: &rds.Identifier})
+		if err != nil {
+			return nil, err
+		}
+		if len(output.DBInstances) == 0 {
+			return nil, fmt.Errorf("rds instance %s not found", rds.Identifier)
+		}
+		instance := output.DBInstances[0]
+		if instance.DBInstanceArn != nil {
+			details.instanceArn = *instance.DBInstanceArn
+		}
+		if instance.DBClusterIdentifier != nil {
+			details.clusterIdentifier = *instance.DBClusterIdentifier
+		}
+		details.allocatedStorage = instance.AllocatedStorage
+		details.deletionProtection = instance.DeletionProtection
+	}
+	if !isAuroraRdsEngine(rds.Engine) {
+		return details, nil
+	}
+	if len(details.clusterIdentifier) == 0 {
+		return nil, fmt.Errorf("rds instance %s is not part of a cluster", rds.Identifier)
+	}
+	// Deletion protection of an aurora database is a cluster setting.
+	output, err := rdsClient.DescribeDBClusters(context.TODO(), &awsrds.DescribeDBClustersInput{DBClusterIdentifier: &details.clusterIdentifier})
+	if err != nil {
+		return nil, err
+	}
+	if len(output.DBClusters) == 0 {
+		return nil, fmt.Errorf("rds cluster %s not found", details.clusterIdentifier)
+	}
+	cluster := output.DBClusters[0]
+	if cluster.DBClusterArn != nil {
+		details.clusterArn = *cluster.DBClusterArn
+	}
+	details.deletionProtection = cluster.DeletionProtection != nil && *cluster.DeletionProtection
+	return details, nil
+}
+
+func generateRdsVars(rds duplosdk.DuploRdsInstance, details *rdsInstanceDetails, prefix string) []common.VarConfig {
+	vars := []common.VarConfig{
+		{
+			Name:      prefix + "master_password",
+			TypeVal:   "string",
+			DescVal:   "Master password of the RDS instance " + rds.Identifier + ".",
+			Sensitive: true,
+		},
+	}
+	if !isAuroraServerlessRdsEngine(rds.Engine) {
+		vars = append(vars, common.VarConfig{
+			Name:       prefix + "instance_class",
+			DefaultVal: rds.SizeEx,
+			TypeVal:    "string",
+		})
+	}
+	if !isAuroraRdsEngine(rds.Engine) {
+		vars = append(vars, common.VarConfig{
+			Name:       prefix + "allocated_storage",
+			DefaultVal: strconv.Itoa(int(details.allocatedStorage)),
+			TypeVal:    "number",
+			DescVal:    "Allocated storage in GiB.",
+		})
+	}
+	return vars
+}
+
+func generateRdsOutputVars(prefix, resourceType, resourceName string) []common.OutputVarConfig {
+	return []common.OutputVarConfig{
+		{
+			Name: prefix + "endpoint",
+			ActualVal: strings.Join([]string{
+				resourceType,
+				resourceName,
+				"endpoint",
+			}, "."),
+			DescVal:       "The connection endpoint of the RDS instance.",
+			RootTraversal: true,
+		},
+	}
+}
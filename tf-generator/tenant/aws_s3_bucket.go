@@ -0,0 +1,449 @@
+package tenant
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"tenant-native-terraform-generator/duplosdk"
+	"tenant-native-terraform-generator/tf-generator/common"
+)
+
+const (
+	S3_BUCKET                   string = "bucket"
+	S3_VERSIONING_CONFIGURATION string = "versioning_configuration"
+	S3_STATUS                   string = "status"
+	S3_RULE                     string = "rule"
+	S3_APPLY_SSE_BY_DEFAULT     string = "apply_server_side_encryption_by_default"
+	S3_SSE_ALGORITHM            string = "sse_algorithm"
+	S3_KMS_MASTER_KEY_ID        string = "kms_master_key_id"
+	S3_BLOCK_PUBLIC_ACLS        string = "block_public_acls"
+	S3_BLOCK_PUBLIC_POLICY      string = "block_public_policy"
+	S3_IGNORE_PUBLIC_ACLS       string = "ignore_public_acls"
+	S3_RESTRICT_PUBLIC_BUCKETS  string = "restrict_public_buckets"
+	S3_ID                       string = "id"
+	S3_FILTER                   string = "filter"
+	S3_PREFIX                   string = "prefix"
+	S3_TAG                      string = "tag"
+	S3_AND                      string = "and"
+	S3_KEY                      string = "key"
+	S3_VALUE                    string = "value"
+	S3_OBJECT_SIZE_GREATER_THAN string = "object_size_greater_than"
+	S3_OBJECT_SIZE_LESS_THAN    string = "object_size_less_than"
+	S3_EXPIRATION               string = "expiration"
+	S3_TRANSITION               string = "transition"
+	S3_DATE                     string = "date"
+	S3_DAYS                     string = "days"
+	S3_STORAGE_CLASS            string = "storage_class"
+	S3_EXPIRED_DELETE_MARKER    string = "expired_object_delete_marker"
+	S3_NONCURRENT_EXPIRATION    string = "noncurrent_version_expiration"
+	S3_NONCURRENT_TRANSITION    string = "noncurrent_version_transition"
+	S3_NONCURRENT_DAYS          string = "noncurrent_days"
+	S3_NEWER_NONCURRENT         string = "newer_noncurrent_versions"
+	S3_ABORT_MULTIPART_UPLOAD   string = "abort_incomplete_multipart_upload"
+	S3_DAYS_AFTER_INITIATION    string = "days_after_initiation"
+)
+
+const AWS_S3_BUCKET = "aws_s3_bucket"
+const AWS_S3_BUCKET_VERSIONING = "aws_s3_bucket_versioning"
+const AWS_S3_BUCKET_SSE_CONFIGURATION = "aws_s3_bucket_server_side_encryption_configuration"
+const AWS_S3_BUCKET_PUBLIC_ACCESS_BLOCK = "aws_s3_bucket_public_access_block"
+const AWS_S3_BUCKET_POLICY = "aws_s3_bucket_policy"
+const AWS_S3_BUCKET_LIFECYCLE_CONFIGURATION = "aws_s3_bucket_lifecycle_configuration"
+const S3_FILE_NAME_PREFIX = "aws-s3-"
+
+type AwsS3Bucket struct {
+}
+
+func (awsS3Bucket *AwsS3Bucket) Generate(config *common.Config, client *duplosdk.Client) (*common.TFContext, error) {
+	workingDir := filepath.Join(config.TFCodePath, config.TenantProject)
+	list, clientErr := client.TenantListS3Buckets(config.TenantId)
+
+	if clientErr != nil {
+		fmt.Println(clientErr)
+		return nil, clientErr
+	}
+	tfContext := common.TFContext{}
+	s3Client := s3.NewFromConfig(config.AwsClientConfig)
+	if list != nil && len(*list) > 0 {
+		log.Println("[TRACE] <====== S3 bucket TF generation started. =====>")
+		for _, bucket := range *list {
+			settings, clientErr := client.TenantGetS3BucketSettings(config.TenantId, bucket.Name)
+			if clientErr != nil {
+				fmt.Println(clientErr)
+				return &tfContext, clientErr
+			}
+			if settings == nil {
+				settings = &bucket
+			}
+			shortName, _ := duplosdk.UnwrapName("duploservices-"+config.TenantName, config.AccountID, bucket.Name, true)
+			resourceName := common.GetResourceName(shortName)
+
+			hclFile := hclwrite.NewEmptyFile()
+			path := filepath.Join(workingDir, S3_FILE_NAME_PREFIX+shortName+".tf")
+			tfFile, err := os.Create(path)
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			rootBody := hclFile.Body()
+
+			// Add aws_s3_bucket resource
+			s3Block := rootBody.AppendNewBlock("resource",
+				[]string{AWS_S3_BUCKET,
+					resourceName})
+			s3Body := s3Block.Body()
+			tfContext.References = append(tfContext.References, common.ResourceReference{
+				Value:   "arn:aws:s3:::" + bucket.Name,
+				Address: AWS_S3_BUCKET + "." + resourceName + ".arn",
+			}, common.ResourceReference{
+				Value:      bucket.Name,
+				Address:    AWS_S3_BUCKET + "." + resourceName + ".id",
+				Attributes: []string{S3_BUCKET},
+			}, common.ResourceReference{
+				// Cloudfront origins and logging configs refer to buckets by domain name.
+				Value:      bucket.Name + ".s3.amazonaws.com",
+				Address:    AWS_S3_BUCKET + "." + resourceName + ".bucket_domain_name",
+				Attributes: []string{CF_DOMAIN_NAME, CF_BUCKET},
+			}, common.ResourceReference{
+				Value:      bucket.Name + ".s3." + config.AwsRegion + ".amazonaws.com",
+				Address:    AWS_S3_BUCKET + "." + resourceName + ".bucket_regional_domain_name",
+				Attributes: []string{CF_DOMAIN_NAME},
+			})
+			bucketName := getS3BucketNameTemplate(config, bucket.Name)
+			if err := common.SetAttributeTemplate(s3Body, S3_BUCKET, bucketName); err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+
+			if settings.Tags != nil && len(*settings.Tags) > 0 {
+				tags := map[string]common.Template{}
+				for _, tag := range *settings.Tags {
+					if common.IsTagAwsManaged(tag.Key) {
+						continue
+					}
+					tagValue := common.Interpolate(tag.Value, common.Interpolation{Value: config.TenantName, Expr: "local.tenant_name"})
+					tags[tag.Key] = tagValue
+				}
+				if err := common.SetAttributeTags(s3Body, TAGS, tags); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+			}
+			appendS3ImportConfig(config, &tfContext, AWS_S3_BUCKET, resourceName, bucket.Name, workingDir)
+
+			// Add aws_s3_bucket_versioning resource
+			if settings.EnableVersioning {
+				rootBody.AppendNewline()
+				versioningBlock := rootBody.AppendNewBlock("resource",
+					[]string{AWS_S3_BUCKET_VERSIONING,
+						resourceName})
+				versioningBody := versioningBlock.Body()
+				if err := setS3BucketReference(versioningBody, resourceName); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				versioningConfigBlock := versioningBody.AppendNewBlock(S3_VERSIONING_CONFIGURATION,
+					nil)
+				versioningConfigBlock.Body().SetAttributeValue(S3_STATUS,
+					cty.StringVal("Enabled"))
+				appendS3ImportConfig(config, &tfContext, AWS_S3_BUCKET_VERSIONING, resourceName, bucket.Name, workingDir)
+			}
+
+			// Add aws_s3_bucket_server_side_encryption_configuration resource
+			sseAlgorithm := ""
+			switch settings.DefaultEncryption {
+			case "Sse":
+				sseAlgorithm = "AES256"
+			case "AwsKms", "TenantKms":
+				sseAlgorithm = "aws:kms"
+			}
+			if len(sseAlgorithm) > 0 {
+				rootBody.AppendNewline()
+				sseBlock := rootBody.AppendNewBlock("resource",
+					[]string{AWS_S3_BUCKET_SSE_CONFIGURATION,
+						resourceName})
+				sseBody := sseBlock.Body()
+				if err := setS3BucketReference(sseBody, resourceName); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				ruleBlock := sseBody.AppendNewBlock(S3_RULE,
+					nil)
+				defaultBlock := ruleBlock.Body().AppendNewBlock(S3_APPLY_SSE_BY_DEFAULT,
+					nil)
+				defaultBody := defaultBlock.Body()
+				defaultBody.SetAttributeValue(S3_SSE_ALGORITHM,
+					cty.StringVal(sseAlgorithm))
+				if settings.DefaultEncryption == "TenantKms" {
+					if err := common.SetAttributeReference(defaultBody, S3_KMS_MASTER_KEY_ID, AWS_KMS_KEY+"."+TENANT_KMS+".arn"); err != nil {
+						fmt.Println(err)
+						return &tfContext, common.NewResourceError(resourceName, err)
+					}
+				}
+				appendS3ImportConfig(config, &tfContext, AWS_S3_BUCKET_SSE_CONFIGURATION, resourceName, bucket.Name, workingDir)
+			}
+
+			// Add aws_s3_bucket_public_access_block resource
+			rootBody.AppendNewline()
+			pabBlock := rootBody.AppendNewBlock("resource",
+				[]string{AWS_S3_BUCKET_PUBLIC_ACCESS_BLOCK,
+					resourceName})
+			pabBody := pabBlock.Body()
+			if err := setS3BucketReference(pabBody, resourceName); err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			blockPublicAccess := !settings.AllowPublicAccess
+			pabBody.SetAttributeValue(S3_BLOCK_PUBLIC_ACLS,
+				cty.BoolVal(blockPublicAccess))
+			pabBody.SetAttributeValue(S3_BLOCK_PUBLIC_POLICY,
+				cty.BoolVal(blockPublicAccess))
+			pabBody.SetAttributeValue(S3_IGNORE_PUBLIC_ACLS,
+				cty.BoolVal(blockPublicAccess))
+			pabBody.SetAttributeValue(S3_RESTRICT_PUBLIC_BUCKETS,
+				cty.BoolVal(blockPublicAccess))
+			appendS3ImportConfig(config, &tfContext, AWS_S3_BUCKET_PUBLIC_ACCESS_BLOCK, resourceName, bucket.Name, workingDir)
+
+			// Add aws_s3_bucket_policy resource
+			policyOutput, err := s3Client.GetBucketPolicy(context.TODO(), &s3.GetBucketPolicyInput{Bucket: &bucket.Name})
+			if err != nil && !isS3ErrorCode(err, "NoSuchBucketPolicy") {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			if err == nil && policyOutput.Policy != nil && len(*policyOutput.Policy) > 0 {
+				rootBody.AppendNewline()
+				policyBlock := rootBody.AppendNewBlock("resource",
+					[]string{AWS_S3_BUCKET_POLICY,
+						resourceName})
+				policyBody := policyBlock.Body()
+				if err := setS3BucketReference(policyBody, resourceName); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				var policyMap interface{}
+				err = json.Unmarshal([]byte(*policyOutput.Policy), &policyMap)
+				if err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				// The bucket name holds the account ID, the longer bucket ARN wins.
+				policy := common.InterpolateValues(policyMap,
+					common.Interpolation{Value: "arn:aws:s3:::" + bucket.Name, Expr: AWS_S3_BUCKET + "." + resourceName + ".arn"},
+					common.Interpolation{Value: config.AccountID, Expr: "local.account_id"})
+				if err := common.SetAttributeJsonencode(policyBody, POLICY, policy); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				appendS3ImportConfig(config, &tfContext, AWS_S3_BUCKET_POLICY, resourceName, bucket.Name, workingDir)
+			}
+
+			// Add aws_s3_bucket_lifecycle_configuration resource
+			lifecycleOutput, err := s3Client.GetBucketLifecycleConfiguration(context.TODO(), &s3.GetBucketLifecycleConfigurationInput{Bucket: &bucket.Name})
+			if err != nil && !isS3ErrorCode(err, "NoSuchLifecycleConfiguration") {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			if err == nil && len(lifecycleOutput.Rules) > 0 {
+				rootBody.AppendNewline()
+				lifecycleBlock := rootBody.AppendNewBlock("resource",
+					[]string{AWS_S3_BUCKET_LIFECYCLE_CONFIGURATION,
+						resourceName})
+				lifecycleBody := lifecycleBlock.Body()
+				if err := setS3BucketReference(lifecycleBody, resourceName); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				for _, rule := range lifecycleOutput.Rules {
+					appendS3LifecycleRule(lifecycleBody, rule)
+				}
+				appendS3ImportConfig(config, &tfContext, AWS_S3_BUCKET_LIFECYCLE_CONFIGURATION, resourceName, bucket.Name, workingDir)
+			}
+
+			_, err = tfFile.Write(hclFile.Bytes())
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			log.Printf("[TRACE] Terraform config is generated for s3 bucket : %s", shortName)
+		}
+		log.Println("[TRACE] <====== S3 bucket TF generation done. =====>")
+	}
+	return &tfContext, nil
+}
+
+// getS3BucketNameTemplate replaces the tenant name and the account ID suffix of a duplo bucket name with locals.
+func getS3BucketNameTemplate(config *common.Config, bucketName string) common.Template {
+	prefix := "duploservices-" + config.TenantName + "-"
+	suffix := "-" + config.AccountID
+	namePrefix := ""
+	nameSuffix := ""
+	if strings.HasPrefix(bucketName, prefix) {
+		namePrefix = "${local.tenant_prefix}-"
+		bucketName = bucketName[len(prefix):]
+	}
+	if strings.HasSuffix(bucketName, suffix) {
+		nameSuffix = "-${local.account_id}"
+		bucketName = bucketName[:len(bucketName)-len(suffix)]
+	}
+	return common.Template(namePrefix + common.EscapeTemplate(bucketName) + nameSuffix)
+}
+
+// appendS3LifecycleRule renders a rule block of an aws_s3_bucket_lifecycle_configuration.
+func appendS3LifecycleRule(body *hclwrite.Body, rule types.LifecycleRule) {
+	ruleBody := body.AppendNewBlock(S3_RULE,
+		nil).Body()
+	if rule.ID != nil {
+		ruleBody.SetAttributeValue(S3_ID,
+			cty.StringVal(*rule.ID))
+	}
+	ruleBody.SetAttributeValue(S3_STATUS,
+		cty.StringVal(string(rule.Status)))
+
+	filterBody := ruleBody.AppendNewBlock(S3_FILTER,
+		nil).Body()
+	switch filter := rule.Filter.(type) {
+	case *types.LifecycleRuleFilterMemberPrefix:
+		filterBody.SetAttributeValue(S3_PREFIX,
+			cty.StringVal(filter.Value))
+	case *types.LifecycleRuleFilterMemberObjectSizeGreaterThan:
+		filterBody.SetAttributeValue(S3_OBJECT_SIZE_GREATER_THAN,
+			cty.NumberIntVal(filter.Value))
+	case *types.LifecycleRuleFilterMemberObjectSizeLessThan:
+		filterBody.SetAttributeValue(S3_OBJECT_SIZE_LESS_THAN,
+			cty.NumberIntVal(filter.Value))
+	case *types.LifecycleRuleFilterMemberTag:
+		tagBody := filterBody.AppendNewBlock(S3_TAG,
+			nil).Body()
+		tagBody.SetAttributeValue(S3_KEY,
+			cty.StringVal(*filter.Value.Key))
+		tagBody.SetAttributeValue(S3_VALUE,
+			cty.StringVal(*filter.Value.Value))
+	case *types.LifecycleRuleFilterMemberAnd:
+		andBody := filterBody.AppendNewBlock(S3_AND,
+			nil).Body()
+		if filter.Value.Prefix != nil {
+			andBody.SetAttributeValue(S3_PREFIX,
+				cty.StringVal(*filter.Value.Prefix))
+		}
+		if filter.Value.ObjectSizeGreaterThan > 0 {
+			andBody.SetAttributeValue(S3_OBJECT_SIZE_GREATER_THAN,
+				cty.NumberIntVal(filter.Value.ObjectSizeGreaterThan))
+		}
+		if filter.Value.ObjectSizeLessThan > 0 {
+			andBody.SetAttributeValue(S3_OBJECT_SIZE_LESS_THAN,
+				cty.NumberIntVal(filter.Value.ObjectSizeLessThan))
+		}
+		if len(filter.Value.Tags) > 0 {
+			tags := map[string]cty.Value{}
+			for _, tag := range filter.Value.Tags {
+				tags[*tag.Key] = cty.StringVal(*tag.Value)
+			}
+			andBody.SetAttributeValue(TAGS,
+				cty.MapVal(tags))
+		}
+	default:
+		// The deprecated rule level prefix, an empty prefix applies the rule to all objects.
+		prefix := ""
+		if rule.Prefix != nil {
+			prefix = *rule.Prefix
+		}
+		filterBody.SetAttributeValue(S3_PREFIX,
+			cty.StringVal(prefix))
+	}
+
+	if rule.Expiration != nil {
+		expirationBody := ruleBody.AppendNewBlock(S3_EXPIRATION,
+			nil).Body()
+		if rule.Expiration.Date != nil {
+			expirationBody.SetAttributeValue(S3_DATE,
+				cty.StringVal(rule.Expiration.Date.UTC().Format(time.RFC3339)))
+		}
+		if rule.Expiration.Days > 0 {
+			expirationBody.SetAttributeValue(S3_DAYS,
+				cty.NumberIntVal(int64(rule.Expiration.Days)))
+		}
+		if rule.Expiration.ExpiredObjectDeleteMarker {
+			expirationBody.SetAttributeValue(S3_EXPIRED_DELETE_MARKER,
+				cty.BoolVal(true))
+		}
+	}
+	for _, transition := range rule.Transitions {
+		transitionBody := ruleBody.AppendNewBlock(S3_TRANSITION,
+			nil).Body()
+		if transition.Date != nil {
+			transitionBody.SetAttributeValue(S3_DATE,
+				cty.StringVal(transition.Date.UTC().Format(time.RFC3339)))
+		} else {
+			transitionBody.SetAttributeValue(S3_DAYS,
+				cty.NumberIntVal(int64(transition.Days)))
+		}
+		transitionBody.SetAttributeValue(S3_STORAGE_CLASS,
+			cty.StringVal(string(transition.StorageClass)))
+	}
+	if rule.NoncurrentVersionExpiration != nil {
+		expirationBody := ruleBody.AppendNewBlock(S3_NONCURRENT_EXPIRATION,
+			nil).Body()
+		expirationBody.SetAttributeValue(S3_NONCURRENT_DAYS,
+			cty.NumberIntVal(int64(rule.NoncurrentVersionExpiration.NoncurrentDays)))
+		if rule.NoncurrentVersionExpiration.NewerNoncurrentVersions > 0 {
+			expirationBody.SetAttributeValue(S3_NEWER_NONCURRENT,
+				cty.StringVal(strconv.Itoa(int(rule.NoncurrentVersionExpiration.NewerNoncurrentVersions))))
+		}
+	}
+	for _, transition := range rule.NoncurrentVersionTransitions {
+		transitionBody := ruleBody.AppendNewBlock(S3_NONCURRENT_TRANSITION,
+			nil).Body()
+		transitionBody.SetAttributeValue(S3_NONCURRENT_DAYS,
+			cty.NumberIntVal(int64(transition.NoncurrentDays)))
+		if transition.NewerNoncurrentVersions > 0 {
+			transitionBody.SetAttributeValue(S3_NEWER_NONCURRENT,
+				cty.StringVal(strconv.Itoa(int(transition.NewerNoncurrentVersions))))
+		}
+		transitionBody.SetAttributeValue(S3_STORAGE_CLASS,
+			cty.StringVal(string(transition.StorageClass)))
+	}
+	if rule.AbortIncompleteMultipartUpload != nil {
+		ruleBody.AppendNewBlock(S3_ABORT_MULTIPART_UPLOAD,
+			nil).Body().SetAttributeValue(S3_DAYS_AFTER_INITIATION,
+			cty.NumberIntVal(int64(rule.AbortIncompleteMultipartUpload.DaysAfterInitiation)))
+	}
+}
+
+// isS3ErrorCode reports an s3 api error with the given code, s3 has no typed errors for missing bucket configurations.
+func isS3ErrorCode(err error, code string) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == code
+}
+
+func setS3BucketReference(body *hclwrite.Body, resourceName string) error {
+	return common.SetAttributeReference(body, S3_BUCKET, AWS_S3_BUCKET+"."+resourceName+".id")
+}
+
+// appendS3ImportConfig adds the import of a bucket resource right away, so that a later failure keeps it.
+func appendS3ImportConfig(config *common.Config, tfContext *common.TFContext, resourceType, resourceName, bucketName, workingDir string) {
+	if !config.GenerateTfState {
+		return
+	}
+	tfContext.ImportConfigs = append(tfContext.ImportConfigs, common.ImportConfig{
+		ResourceAddress: strings.Join([]string{
+			resourceType,
+			resourceName,
+		}, "."),
+		ResourceId: bucketName,
+		WorkingDir: workingDir,
+	})
+}
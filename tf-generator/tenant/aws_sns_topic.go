@@ -0,0 +1,192 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"tenant-native-terraform-generator/duplosdk"
+	"tenant-native-terraform-generator/tf-generator/common"
+)
+
+const (
+	SNS_NAME                        string = "name"
+	SNS_DISPLAY_NAME                string = "display_name"
+	SNS_FIFO_TOPIC                  string = "fifo_topic"
+	SNS_CONTENT_BASED_DEDUPLICATION string = "content_based_deduplication"
+	SNS_KMS_MASTER_KEY_ID           string = "kms_master_key_id"
+	SNS_TOPIC_ARN                   string = "topic_arn"
+	SNS_PROTOCOL                    string = "protocol"
+	SNS_ENDPOINT                    string = "endpoint"
+	SNS_RAW_MESSAGE_DELIVERY        string = "raw_message_delivery"
+)
+
+const AWS_SNS_TOPIC = "aws_sns_topic"
+const AWS_SNS_TOPIC_SUBSCRIPTION = "aws_sns_topic_subscription"
+const SNS_FILE_NAME_PREFIX = "aws-sns-"
+
+type AwsSnsTopic struct {
+}
+
+func (awsSnsTopic *AwsSnsTopic) Generate(config *common.Config, client *duplosdk.Client) (*common.TFContext, error) {
+	workingDir := filepath.Join(config.TFCodePath, config.TenantProject)
+	list, clientErr := client.TenantListSnsTopic(config.TenantId)
+
+	if clientErr != nil {
+		fmt.Println(clientErr)
+		return nil, clientErr
+	}
+	tfContext := common.TFContext{}
+	importConfigs := []common.ImportConfig{}
+	if list != nil && len(*list) > 0 {
+		log.Println("[TRACE] <====== SNS topic TF generation started. =====>")
+		queueList, clientErr := client.TenantListSQS(config.TenantId)
+		if clientErr != nil {
+			fmt.Println(clientErr)
+			return &tfContext, clientErr
+		}
+		tenantQueueNames := getTenantSqsQueueNames(queueList)
+		snsClient := sns.NewFromConfig(config.AwsClientConfig)
+		for _, topic := range *list {
+			topicName := duplosdk.UnwrapResoureNameFromAwsArn(topic.Name)
+			topicArn := topic.Name
+			if !strings.HasPrefix(topicArn, "arn:") {
+				topicArn = strings.Join([]string{"arn", "aws", "sns", config.AwsRegion, config.AccountID, topicName}, ":")
+			}
+			topicAttributesOutput, err := snsClient.GetTopicAttributes(context.TODO(), &sns.GetTopicAttributesInput{TopicArn: &topicArn})
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, err
+			}
+			attributes := topicAttributesOutput.Attributes
+			shortName := strings.TrimPrefix(topicName, "duploservices-"+config.TenantName+"-")
+			if strings.HasSuffix(shortName, ".fifo") {
+				shortName = strings.TrimSuffix(shortName, ".fifo") + "_fifo"
+			}
+			resourceName := common.GetResourceName(shortName)
+
+			hclFile := hclwrite.NewEmptyFile()
+			path := filepath.Join(workingDir, SNS_FILE_NAME_PREFIX+shortName+".tf")
+			tfFile, err := os.Create(path)
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			rootBody := hclFile.Body()
+
+			// Add aws_sns_topic resource
+			snsBlock := rootBody.AppendNewBlock("resource",
+				[]string{AWS_SNS_TOPIC,
+					resourceName})
+			snsBody := snsBlock.Body()
+			tfContext.References = append(tfContext.References, common.ResourceReference{
+				Value:   topicArn,
+				Address: AWS_SNS_TOPIC + "." + resourceName + ".arn",
+			})
+			name := common.Interpolate(topicName)
+			if strings.HasPrefix(topicName, "duploservices-"+config.TenantName+"-") {
+				name = common.Template("${local.tenant_prefix}-" + common.EscapeTemplate(topicName[len("duploservices-"+config.TenantName+"-"):]))
+			}
+			if err := common.SetAttributeTemplate(snsBody, SNS_NAME, name); err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			if displayName, ok := attributes["DisplayName"]; ok && len(displayName) > 0 {
+				snsBody.SetAttributeValue(SNS_DISPLAY_NAME,
+					cty.StringVal(displayName))
+			}
+			if attributes["FifoTopic"] == "true" {
+				snsBody.SetAttributeValue(SNS_FIFO_TOPIC,
+					cty.BoolVal(true))
+				if attributes["ContentBasedDeduplication"] == "true" {
+					snsBody.SetAttributeValue(SNS_CONTENT_BASED_DEDUPLICATION,
+						cty.BoolVal(true))
+				}
+			}
+			if kmsKeyId, ok := attributes["KmsMasterKeyId"]; ok && len(kmsKeyId) > 0 {
+				snsBody.SetAttributeValue(SNS_KMS_MASTER_KEY_ID,
+					cty.StringVal(kmsKeyId))
+			}
+			if config.GenerateTfState {
+				importConfigs = append(importConfigs, common.ImportConfig{
+					ResourceAddress: strings.Join([]string{
+						AWS_SNS_TOPIC,
+						resourceName,
+					}, "."),
+					ResourceId: topicArn,
+					WorkingDir: workingDir,
+				})
+				tfContext.ImportConfigs = importConfigs
+			}
+
+			// Add aws_sns_topic_subscription resources for subscriptions to tenant queues.
+			subscriptionsOutput, err := snsClient.ListSubscriptionsByTopic(context.TODO(), &sns.ListSubscriptionsByTopicInput{TopicArn: &topicArn})
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			for _, subscription := range subscriptionsOutput.Subscriptions {
+				if subscription.Protocol == nil || *subscription.Protocol != "sqs" || subscription.Endpoint == nil {
+					continue
+				}
+				queueName := duplosdk.UnwrapResoureNameFromAwsArn(*subscription.Endpoint)
+				if !common.Contains(tenantQueueNames, queueName) {
+					log.Printf("[TRACE] Skipping subscription (%s), endpoint is not a tenant queue.", *subscription.Endpoint)
+					continue
+				}
+				subscriptionResourceName := resourceName + "_" + common.GetResourceName(getSqsQueueShortName(config, queueName))
+				rootBody.AppendNewline()
+				subscriptionBlock := rootBody.AppendNewBlock("resource",
+					[]string{AWS_SNS_TOPIC_SUBSCRIPTION,
+						subscriptionResourceName})
+				subscriptionBody := subscriptionBlock.Body()
+				if err := common.SetAttributeReference(subscriptionBody, SNS_TOPIC_ARN, AWS_SNS_TOPIC+"."+resourceName+".arn"); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				subscriptionBody.SetAttributeValue(SNS_PROTOCOL,
+					cty.StringVal(*subscription.Protocol))
+				subscriptionBody.SetAttributeValue(SNS_ENDPOINT,
+					cty.StringVal(*subscription.Endpoint))
+				subscriptionAttributesOutput, err := snsClient.GetSubscriptionAttributes(context.TODO(), &sns.GetSubscriptionAttributesInput{
+					SubscriptionArn: subscription.SubscriptionArn,
+				})
+				if err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				if subscriptionAttributesOutput.Attributes["RawMessageDelivery"] == "true" {
+					subscriptionBody.SetAttributeValue(SNS_RAW_MESSAGE_DELIVERY,
+						cty.BoolVal(true))
+				}
+				if config.GenerateTfState {
+					importConfigs = append(importConfigs, common.ImportConfig{
+						ResourceAddress: strings.Join([]string{
+							AWS_SNS_TOPIC_SUBSCRIPTION,
+							subscriptionResourceName,
+						}, "."),
+						ResourceId: *subscription.SubscriptionArn,
+						WorkingDir: workingDir,
+					})
+					tfContext.ImportConfigs = importConfigs
+				}
+			}
+
+			_, err = tfFile.Write(hclFile.Bytes())
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			log.Printf("[TRACE] Terraform config is generated for sns topic : %s", shortName)
+		}
+		log.Println("[TRACE] <====== SNS topic TF generation done. =====>")
+	}
+	return &tfContext, nil
+}
@@ -0,0 +1,209 @@
+package tenant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"tenant-native-terraform-generator/duplosdk"
+	"tenant-native-terraform-generator/tf-generator/common"
+)
+
+const (
+	SQS_NAME                        string = "name"
+	SQS_FIFO_QUEUE                  string = "fifo_queue"
+	SQS_CONTENT_BASED_DEDUPLICATION string = "content_based_deduplication"
+	SQS_VISIBILITY_TIMEOUT_SECONDS  string = "visibility_timeout_seconds"
+	SQS_MESSAGE_RETENTION_SECONDS   string = "message_retention_seconds"
+	SQS_MAX_MESSAGE_SIZE            string = "max_message_size"
+	SQS_DELAY_SECONDS               string = "delay_seconds"
+	SQS_RECEIVE_WAIT_TIME_SECONDS   string = "receive_wait_time_seconds"
+	SQS_KMS_MASTER_KEY_ID           string = "kms_master_key_id"
+	SQS_MANAGED_SSE_ENABLED         string = "sqs_managed_sse_enabled"
+	SQS_REDRIVE_POLICY              string = "redrive_policy"
+)
+
+const AWS_SQS_QUEUE = "aws_sqs_queue"
+const SQS_FILE_NAME_PREFIX = "aws-sqs-"
+
+type AwsSqsQueue struct {
+}
+
+func (awsSqsQueue *AwsSqsQueue) Generate(config *common.Config, client *duplosdk.Client) (*common.TFContext, error) {
+	workingDir := filepath.Join(config.TFCodePath, config.TenantProject)
+	list, clientErr := client.TenantListSQS(config.TenantId)
+
+	if clientErr != nil {
+		fmt.Println(clientErr)
+		return nil, clientErr
+	}
+	tfContext := common.TFContext{}
+	importConfigs := []common.ImportConfig{}
+	if list != nil && len(*list) > 0 {
+		log.Println("[TRACE] <====== SQS queue TF generation started. =====>")
+		sqsClient := sqs.NewFromConfig(config.AwsClientConfig)
+		for _, queue := range *list {
+			queueName := duplosdk.UnwrapResoureNameFromAwsArn(queue.Name)
+			getQueueUrlOutput, err := sqsClient.GetQueueUrl(context.TODO(), &sqs.GetQueueUrlInput{QueueName: &queueName})
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, err
+			}
+			queueAttributesOutput, err := sqsClient.GetQueueAttributes(context.TODO(), &sqs.GetQueueAttributesInput{
+				QueueUrl:       getQueueUrlOutput.QueueUrl,
+				AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameAll},
+			})
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, err
+			}
+			attributes := queueAttributesOutput.Attributes
+			shortName := getSqsQueueShortName(config, queueName)
+			resourceName := common.GetResourceName(shortName)
+
+			hclFile := hclwrite.NewEmptyFile()
+			path := filepath.Join(workingDir, SQS_FILE_NAME_PREFIX+shortName+".tf")
+			tfFile, err := os.Create(path)
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			rootBody := hclFile.Body()
+
+			// Add aws_sqs_queue resource
+			sqsBlock := rootBody.AppendNewBlock("resource",
+				[]string{AWS_SQS_QUEUE,
+					resourceName})
+			sqsBody := sqsBlock.Body()
+			tfContext.References = append(tfContext.References, common.ResourceReference{
+				Value:   *getQueueUrlOutput.QueueUrl,
+				Address: AWS_SQS_QUEUE + "." + resourceName + ".url",
+			})
+			// Topic subscriptions take the queue arn as their endpoint.
+			if queueArn, ok := attributes[string(types.QueueAttributeNameQueueArn)]; ok {
+				tfContext.References = append(tfContext.References, common.ResourceReference{
+					Value:   queueArn,
+					Address: AWS_SQS_QUEUE + "." + resourceName + ".arn",
+				}, common.ResourceReference{
+					Value:      queueArn,
+					Address:    AWS_SQS_QUEUE + "." + resourceName + ".arn",
+					Attributes: []string{SNS_ENDPOINT},
+				})
+			}
+			name := common.Interpolate(queueName)
+			if strings.HasPrefix(queueName, "duploservices-"+config.TenantName+"-") {
+				name = common.Template("${local.tenant_prefix}-" + common.EscapeTemplate(queueName[len("duploservices-"+config.TenantName+"-"):]))
+			}
+			if err := common.SetAttributeTemplate(sqsBody, SQS_NAME, name); err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+
+			if attributes[string(types.QueueAttributeNameFifoQueue)] == "true" {
+				sqsBody.SetAttributeValue(SQS_FIFO_QUEUE,
+					cty.BoolVal(true))
+				if attributes[string(types.QueueAttributeNameContentBasedDeduplication)] == "true" {
+					sqsBody.SetAttributeValue(SQS_CONTENT_BASED_DEDUPLICATION,
+						cty.BoolVal(true))
+				}
+			}
+			for _, attr := range []struct {
+				tfName  string
+				awsName types.QueueAttributeName
+			}{
+				{SQS_VISIBILITY_TIMEOUT_SECONDS, types.QueueAttributeNameVisibilityTimeout},
+				{SQS_MESSAGE_RETENTION_SECONDS, types.QueueAttributeNameMessageRetentionPeriod},
+				{SQS_MAX_MESSAGE_SIZE, types.QueueAttributeNameMaximumMessageSize},
+				{SQS_DELAY_SECONDS, types.QueueAttributeNameDelaySeconds},
+				{SQS_RECEIVE_WAIT_TIME_SECONDS, types.QueueAttributeNameReceiveMessageWaitTimeSeconds},
+			} {
+				if val, ok := attributes[string(attr.awsName)]; ok {
+					if num, err := strconv.ParseInt(val, 10, 64); err == nil {
+						sqsBody.SetAttributeValue(attr.tfName,
+							cty.NumberIntVal(num))
+					}
+				}
+			}
+			if kmsKeyId, ok := attributes[string(types.QueueAttributeNameKmsMasterKeyId)]; ok && len(kmsKeyId) > 0 {
+				sqsBody.SetAttributeValue(SQS_KMS_MASTER_KEY_ID,
+					cty.StringVal(kmsKeyId))
+			} else if attributes[string(types.QueueAttributeNameSqsManagedSseEnabled)] == "true" {
+				sqsBody.SetAttributeValue(SQS_MANAGED_SSE_ENABLED,
+					cty.BoolVal(true))
+			}
+
+			if redrivePolicy, ok := attributes[string(types.QueueAttributeNameRedrivePolicy)]; ok && len(redrivePolicy) > 0 {
+				redrivePolicyMap := map[string]interface{}{}
+				if err := json.Unmarshal([]byte(redrivePolicy), &redrivePolicyMap); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				if err := common.SetAttributeJsonencode(sqsBody, SQS_REDRIVE_POLICY, redrivePolicyMap); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+			}
+
+			if config.GenerateTfState {
+				importConfigs = append(importConfigs, common.ImportConfig{
+					ResourceAddress: strings.Join([]string{
+						AWS_SQS_QUEUE,
+						resourceName,
+					}, "."),
+					ResourceId: *getQueueUrlOutput.QueueUrl,
+					WorkingDir: workingDir,
+				})
+				tfContext.ImportConfigs = importConfigs
+			}
+			_, err = tfFile.Write(hclFile.Bytes())
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			log.Printf("[TRACE] Terraform config is generated for sqs queue : %s", shortName)
+
+			tfContext.OutputVars = append(tfContext.OutputVars, common.OutputVarConfig{
+				Name: "sqs_" + resourceName + "_url",
+				ActualVal: strings.Join([]string{
+					AWS_SQS_QUEUE,
+					resourceName,
+					"url",
+				}, "."),
+				DescVal:       "The URL of the SQS queue.",
+				RootTraversal: true,
+			})
+		}
+		log.Println("[TRACE] <====== SQS queue TF generation done. =====>")
+	}
+	return &tfContext, nil
+}
+
+// getSqsQueueShortName strips the duplo tenant prefix from a queue name.
+// The .fifo suffix becomes _fifo, so that the queues foo and foo.fifo get different resource names.
+func getSqsQueueShortName(config *common.Config, queueName string) string {
+	shortName := strings.TrimPrefix(queueName, "duploservices-"+config.TenantName+"-")
+	if strings.HasSuffix(shortName, ".fifo") {
+		shortName = strings.TrimSuffix(shortName, ".fifo") + "_fifo"
+	}
+	return shortName
+}
+
+func getTenantSqsQueueNames(list *[]duplosdk.DuploAwsResource) []string {
+	names := []string{}
+	if list != nil {
+		for _, queue := range *list {
+			names = append(names, duplosdk.UnwrapResoureNameFromAwsArn(queue.Name))
+		}
+	}
+	return names
+}
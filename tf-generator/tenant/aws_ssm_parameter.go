@@ -0,0 +1,149 @@
+package tenant
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"tenant-native-terraform-generator/duplosdk"
+	"tenant-native-terraform-generator/tf-generator/common"
+)
+
+const (
+	SSM_NAME            string = "name"
+	SSM_TYPE            string = "type"
+	SSM_VALUE           string = "value"
+	SSM_DESCRIPTION     string = "description"
+	SSM_ALLOWED_PATTERN string = "allowed_pattern"
+	SSM_KEY_ID          string = "key_id"
+)
+
+const AWS_SSM_PARAMETER = "aws_ssm_parameter"
+const SSM_VAR_PREFIX = "ssm_"
+const SSM_FILE_NAME_PREFIX = "aws-ssm-"
+const SSM_SECRETS_EXAMPLE_FILE_NAME = "secrets.auto.tfvars.example"
+const SSM_SECURE_STRING = "SecureString"
+
+type AwsSsmParameter struct {
+}
+
+func (awsSsmParameter *AwsSsmParameter) Generate(config *common.Config, client *duplosdk.Client) (*common.TFContext, error) {
+	workingDir := filepath.Join(config.TFCodePath, config.TenantProject)
+	list, clientErr := client.SsmParameterList(config.TenantId)
+
+	if clientErr != nil {
+		fmt.Println(clientErr)
+		return nil, clientErr
+	}
+	tfContext := common.TFContext{}
+	importConfigs := []common.ImportConfig{}
+	if list != nil && len(*list) > 0 {
+		log.Println("[TRACE] <====== SSM parameter TF generation started. =====>")
+		tenantKms, clientErr := client.TenantGetTenantKmsKey(config.TenantId)
+		if clientErr != nil {
+			fmt.Println(clientErr)
+			return &tfContext, clientErr
+		}
+		secretsFile := hclwrite.NewEmptyFile()
+		secretsBody := secretsFile.Body()
+		for _, param := range *list {
+			resourceName := common.GetResourceName(strings.TrimPrefix(param.Name, "/"))
+			varFullPrefix := SSM_VAR_PREFIX + resourceName + "_"
+
+			hclFile := hclwrite.NewEmptyFile()
+			path := filepath.Join(workingDir, SSM_FILE_NAME_PREFIX+resourceName+".tf")
+			tfFile, err := os.Create(path)
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			rootBody := hclFile.Body()
+
+			// Add aws_ssm_parameter resource
+			ssmBlock := rootBody.AppendNewBlock("resource",
+				[]string{AWS_SSM_PARAMETER,
+					resourceName})
+			ssmBody := ssmBlock.Body()
+			ssmBody.SetAttributeValue(SSM_NAME,
+				cty.StringVal(param.Name))
+			ssmBody.SetAttributeValue(SSM_TYPE,
+				cty.StringVal(param.Type))
+			if len(param.Description) > 0 {
+				ssmBody.SetAttributeValue(SSM_DESCRIPTION,
+					cty.StringVal(param.Description))
+			}
+			if param.Type == SSM_SECURE_STRING {
+				// Secure values are never written to the generated code, they have to be supplied as input.
+				if err := common.SetAttributeReference(ssmBody, SSM_VALUE, "var."+varFullPrefix+"value"); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				tfContext.InputVars = append(tfContext.InputVars, common.VarConfig{
+					Name:      varFullPrefix + "value",
+					TypeVal:   "string",
+					DescVal:   "Value of the secure SSM parameter " + param.Name + ".",
+					Sensitive: true,
+				})
+				secretsBody.SetAttributeValue(varFullPrefix+"value",
+					cty.StringVal(""))
+				if isTenantKmsKey(tenantKms, param.KeyId) {
+					if err := common.SetAttributeReference(ssmBody, SSM_KEY_ID, AWS_KMS_KEY+"."+TENANT_KMS+".arn"); err != nil {
+						fmt.Println(err)
+						return &tfContext, common.NewResourceError(resourceName, err)
+					}
+				} else if len(param.KeyId) > 0 && param.KeyId != "alias/aws/ssm" {
+					ssmBody.SetAttributeValue(SSM_KEY_ID,
+						cty.StringVal(param.KeyId))
+				}
+			} else {
+				ssmBody.SetAttributeValue(SSM_VALUE,
+					cty.StringVal(param.Value))
+			}
+			if len(param.AllowedPattern) > 0 {
+				ssmBody.SetAttributeValue(SSM_ALLOWED_PATTERN,
+					cty.StringVal(param.AllowedPattern))
+			}
+
+			if config.GenerateTfState {
+				importConfigs = append(importConfigs, common.ImportConfig{
+					ResourceAddress: strings.Join([]string{
+						AWS_SSM_PARAMETER,
+						resourceName,
+					}, "."),
+					ResourceId: param.Name,
+					WorkingDir: workingDir,
+				})
+				tfContext.ImportConfigs = importConfigs
+			}
+
+			_, err = tfFile.Write(hclFile.Bytes())
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			log.Printf("[TRACE] Terraform config is generated for ssm parameter : %s", param.Name)
+		}
+
+		// Write the example tfvars listing the secure values to be supplied.
+		if len(secretsBody.Attributes()) > 0 {
+			path := filepath.Join(workingDir, SSM_SECRETS_EXAMPLE_FILE_NAME)
+			secretsTfFile, err := os.Create(path)
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, err
+			}
+			_, err = secretsTfFile.Write(secretsFile.Bytes())
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, err
+			}
+		}
+		log.Println("[TRACE] <====== SSM parameter TF generation done. =====>")
+	}
+	return &tfContext, nil
+}
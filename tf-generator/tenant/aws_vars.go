@@ -34,7 +34,7 @@ func (awsVars *AwsVars) Generate(config *common.Config, client *duplosdk.Client)
 	}
 	varConfigs["region"] = regionVar
 
-	vars := make([]common.VarConfig, len(varConfigs))
+	vars := make([]common.VarConfig, 0, len(varConfigs))
 	for _, v := range varConfigs {
 		vars = append(vars, v)
 	}
@@ -8,9 +8,6 @@ import (
 	"tenant-native-terraform-generator/duplosdk"
 	"tenant-native-terraform-generator/tf-generator/common"
 
-	"github.com/hashicorp/hcl/v2/hclsyntax"
-
-	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 )
 
@@ -54,78 +51,52 @@ func (tm *TenantMain) Generate(config *common.Config, client *duplosdk.Client) (
 		nil)
 	localsBlockBody := localsBlock.Body()
 
-	localsBlockBody.SetAttributeTraversal("account_id", hcl.Traversal{
-		hcl.TraverseRoot{
-			Name: "data.aws_caller_identity.current",
-		},
-		hcl.TraverseAttr{
-			Name: "account_id",
-		},
-	})
-	localsBlockBody.SetAttributeTraversal("region", hcl.Traversal{
-		hcl.TraverseRoot{
-			Name: "var",
-		},
-		hcl.TraverseAttr{
-			Name: "region",
-		},
-	})
-	localsBlockBody.SetAttributeTraversal("vpc_id", hcl.Traversal{
-		hcl.TraverseRoot{
-			Name: "var",
-		},
-		hcl.TraverseAttr{
-			Name: "vpc_id",
-		},
-	})
-	localsBlockBody.SetAttributeTraversal("tenant_name", hcl.Traversal{
-		hcl.TraverseRoot{
-			Name: "var",
-		},
-		hcl.TraverseAttr{
-			Name: "tenant_name",
-		},
-	})
-
-	tenantPrefix := "duploservices-${var.tenant_name}"
-	tenantPrefixTokens := hclwrite.Tokens{
-		{Type: hclsyntax.TokenOQuote, Bytes: []byte(`"`)},
-		{Type: hclsyntax.TokenIdent, Bytes: []byte(tenantPrefix)},
-		{Type: hclsyntax.TokenCQuote, Bytes: []byte(`"`)},
+	if err := common.SetAttributeReference(localsBlockBody, "account_id", "data.aws_caller_identity.current.account_id"); err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+	if err := common.SetAttributeReference(localsBlockBody, "region", "var.region"); err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+	if err := common.SetAttributeReference(localsBlockBody, "vpc_id", "var.vpc_id"); err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+	if err := common.SetAttributeReference(localsBlockBody, "tenant_name", "var.tenant_name"); err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+
+	tenantPrefix := common.Template("duploservices-${var.tenant_name}")
+	if err := common.SetAttributeTemplate(localsBlockBody, "tenant_prefix", tenantPrefix); err != nil {
+		fmt.Println(err)
+		return nil, err
 	}
-	localsBlockBody.SetAttributeRaw("tenant_prefix", tenantPrefixTokens)
 
-	tenantIAMRole := "duploservices-${var.tenant_name}"
-	tenantIAMRoleTokens := hclwrite.Tokens{
-		{Type: hclsyntax.TokenOQuote, Bytes: []byte(`"`)},
-		{Type: hclsyntax.TokenIdent, Bytes: []byte(tenantIAMRole)},
-		{Type: hclsyntax.TokenCQuote, Bytes: []byte(`"`)},
+	tenantIAMRole := common.Template("duploservices-${var.tenant_name}")
+	if err := common.SetAttributeTemplate(localsBlockBody, "tenant_iam_role_name", tenantIAMRole); err != nil {
+		fmt.Println(err)
+		return nil, err
 	}
-	localsBlockBody.SetAttributeRaw("tenant_iam_role_name", tenantIAMRoleTokens)
 
-	tenantSG := "duploservices-${var.tenant_name}"
-	tenantSGTokens := hclwrite.Tokens{
-		{Type: hclsyntax.TokenOQuote, Bytes: []byte(`"`)},
-		{Type: hclsyntax.TokenIdent, Bytes: []byte(tenantSG)},
-		{Type: hclsyntax.TokenCQuote, Bytes: []byte(`"`)},
+	tenantSG := common.Template("duploservices-${var.tenant_name}")
+	if err := common.SetAttributeTemplate(localsBlockBody, "tenant_sg_name", tenantSG); err != nil {
+		fmt.Println(err)
+		return nil, err
 	}
-	localsBlockBody.SetAttributeRaw("tenant_sg_name", tenantSGTokens)
 
-	tenantLBSG := "duploservices-${var.tenant_name}-lb"
-	tenantLBSGTokens := hclwrite.Tokens{
-		{Type: hclsyntax.TokenOQuote, Bytes: []byte(`"`)},
-		{Type: hclsyntax.TokenIdent, Bytes: []byte(tenantLBSG)},
-		{Type: hclsyntax.TokenCQuote, Bytes: []byte(`"`)},
+	tenantLBSG := common.Template("duploservices-${var.tenant_name}-lb")
+	if err := common.SetAttributeTemplate(localsBlockBody, "tenant_lb_sg_name", tenantLBSG); err != nil {
+		fmt.Println(err)
+		return nil, err
 	}
-	localsBlockBody.SetAttributeRaw("tenant_lb_sg_name", tenantLBSGTokens)
 
-	tenantALBSG := "duploservices-${var.tenant_name}-alb"
-	tenantALBSGTokens := hclwrite.Tokens{
-		{Type: hclsyntax.TokenOQuote, Bytes: []byte(`"`)},
-		{Type: hclsyntax.TokenIdent, Bytes: []byte(tenantALBSG)},
-		{Type: hclsyntax.TokenCQuote, Bytes: []byte(`"`)},
+	tenantALBSG := common.Template("duploservices-${var.tenant_name}-alb")
+	if err := common.SetAttributeTemplate(localsBlockBody, "tenant_alb_sg_name", tenantALBSG); err != nil {
+		fmt.Println(err)
+		return nil, err
 	}
-	localsBlockBody.SetAttributeRaw("tenant_alb_sg_name", tenantALBSGTokens)
 
 	rootBody.AppendNewline()
 
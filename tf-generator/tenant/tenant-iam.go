@@ -12,7 +12,6 @@ import (
 	"tenant-native-terraform-generator/duplosdk"
 
 	"github.com/aws/aws-sdk-go-v2/service/iam"
-	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/zclconf/go-cty/cty"
 
@@ -45,6 +44,12 @@ func (tenantIAM *TenantIAM) Generate(config *common.Config, client *duplosdk.Cli
 
 	importConfigs := []common.ImportConfig{}
 	iamRoleName := "duploservices-" + config.TenantName
+	// The longest value wins, the role name is interpolated before the tenant name it contains.
+	policyInterpolations := []common.Interpolation{
+		{Value: iamRoleName, Expr: "local.tenant_iam_role_name"},
+		{Value: config.TenantName, Expr: "local.tenant_name"},
+		{Value: config.AccountID, Expr: "local.account_id"},
+	}
 
 	iamClient := iam.NewFromConfig(config.AwsClientConfig)
 
@@ -52,7 +57,7 @@ func (tenantIAM *TenantIAM) Generate(config *common.Config, client *duplosdk.Cli
 	getRoleOutput, err := iamClient.GetRole(context.TODO(), &iam.GetRoleInput{RoleName: &iamRoleName})
 	if err != nil {
 		fmt.Println(err)
-		return nil, err
+		return &tfContext, err
 	}
 	log.Println("[TRACE] <====== Tenant IAM Role TF generation started. =====>")
 	log.Printf("Reading IAM role from AWS, Role - %s", iamRoleName)
@@ -67,7 +72,7 @@ func (tenantIAM *TenantIAM) Generate(config *common.Config, client *duplosdk.Cli
 		tfFile, err := os.Create(path)
 		if err != nil {
 			fmt.Println(err)
-			return nil, err
+			return &tfContext, common.NewResourceError(resourceName, err)
 		}
 		rootBody := hclFile.Body()
 
@@ -76,49 +81,49 @@ func (tenantIAM *TenantIAM) Generate(config *common.Config, client *duplosdk.Cli
 			[]string{AWS_IAM_ROLE,
 				resourceName})
 		iamRoleBody := iamRoleBlock.Body()
-
-		iamRoleBody.SetAttributeTraversal(ROLE_NAME, hcl.Traversal{
-			hcl.TraverseRoot{
-				Name: "local",
-			},
-			hcl.TraverseAttr{
-				Name: "tenant_iam_role_name",
-			},
+		// The instance profile of the tenant has the name of the role.
+		// Lambda and EMR take the role arn in attributes not named *_arn, EMR also takes the role name.
+		tfContext.References = append(tfContext.References, common.ResourceReference{
+			Value:   *iamRole.Arn,
+			Address: AWS_IAM_ROLE + "." + resourceName + ".arn",
+		}, common.ResourceReference{
+			Value:      *iamRole.Arn,
+			Address:    AWS_IAM_ROLE + "." + resourceName + ".arn",
+			Attributes: []string{LAMBDA_ROLE, EMR_SERVICE_ROLE, EMR_AUTOSCALING_ROLE},
+		}, common.ResourceReference{
+			Value:      iamRoleName,
+			Address:    AWS_IAM_ROLE + "." + resourceName + ".name",
+			Attributes: []string{IAM_INSTANCE_PROFILE, EMR_INSTANCE_PROFILE, EMR_SERVICE_ROLE, EMR_AUTOSCALING_ROLE},
 		})
+
+		if err := common.SetAttributeReference(iamRoleBody, ROLE_NAME, "local.tenant_iam_role_name"); err != nil {
+			fmt.Println(err)
+			return &tfContext, common.NewResourceError(resourceName, err)
+		}
 		// iamRoleBody.SetAttributeValue(NAME,
 		// 	cty.StringVal(*iamRole.RoleName))
 		decodedAssumeRolePolicyDocument, err := url.QueryUnescape(*iamRole.AssumeRolePolicyDocument)
 		if err != nil {
-			log.Fatal(err)
-			return nil, err
+			fmt.Println(err)
+			return &tfContext, common.NewResourceError(resourceName, err)
 		}
 		// Add 'assume_role_policy'
 		if len(decodedAssumeRolePolicyDocument) > 0 {
-
-			decodedAssumeRolePolicyDocument = strings.Replace(decodedAssumeRolePolicyDocument, iamRoleName, "${local.tenant_iam_role_name}", -1)
-			decodedAssumeRolePolicyDocument = strings.Replace(decodedAssumeRolePolicyDocument, config.TenantName, "${local.tenant_name}", -1)
-			accountIdStr := "${local.account_id}"
-			decodedAssumeRolePolicyDocument = strings.Replace(decodedAssumeRolePolicyDocument, config.AccountID, accountIdStr, -1)
 			assumeRolePolicyDocumentMap := make(map[string]interface{})
 			if err := json.Unmarshal([]byte(decodedAssumeRolePolicyDocument), &assumeRolePolicyDocumentMap); err != nil {
-				log.Fatal(err)
-				return nil, err
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
 			}
-			assumeRolePolicyDocumentStr, err := duplosdk.JSONMarshal(assumeRolePolicyDocumentMap)
-			if err != nil {
-				panic(err)
+			if err := common.SetAttributeJsonencode(iamRoleBody, ASSUME_ROLE_POLICY, common.InterpolateValues(assumeRolePolicyDocumentMap, policyInterpolations...)); err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
 			}
-			iamRoleBody.SetAttributeTraversal(ASSUME_ROLE_POLICY, hcl.Traversal{
-				hcl.TraverseRoot{
-					Name: "jsonencode(" + assumeRolePolicyDocumentStr + ")",
-				},
-			})
 		}
 		// Add 'inline_policy'
 		listRolePoliciesOutput, err := iamClient.ListRolePolicies(context.TODO(), &iam.ListRolePoliciesInput{RoleName: &iamRoleName})
 		if err != nil {
 			fmt.Println(err)
-			return nil, err
+			return &tfContext, common.NewResourceError(resourceName, err)
 		}
 
 		// Add 'inline_policy'
@@ -130,7 +135,7 @@ func (tenantIAM *TenantIAM) Generate(config *common.Config, client *duplosdk.Cli
 				})
 				if err != nil {
 					fmt.Println(err)
-					return nil, err
+					return &tfContext, common.NewResourceError(resourceName, err)
 				}
 
 				inlinePolicyBlock := iamRoleBody.AppendNewBlock("inline_policy",
@@ -140,28 +145,19 @@ func (tenantIAM *TenantIAM) Generate(config *common.Config, client *duplosdk.Cli
 					cty.StringVal(policyName))
 				if getRolePolicyOutput.PolicyDocument != nil {
 					decodedInlinePolicyDocument, err := url.QueryUnescape(*getRolePolicyOutput.PolicyDocument)
-					decodedInlinePolicyDocument = strings.Replace(decodedInlinePolicyDocument, iamRoleName, "${local.tenant_iam_role_name}", -1)
-					decodedInlinePolicyDocument = strings.Replace(decodedInlinePolicyDocument, config.TenantName, "${local.tenant_name}", -1)
-					accountIdStr := "${local.account_id}"
-					decodedInlinePolicyDocument = strings.Replace(decodedInlinePolicyDocument, config.AccountID, accountIdStr, -1)
 					if err != nil {
-						log.Fatal(err)
-						return nil, err
+						fmt.Println(err)
+						return &tfContext, common.NewResourceError(resourceName, err)
 					}
 					inlineRolePolicyDocumentMap := make(map[string]interface{})
 					if err := json.Unmarshal([]byte(decodedInlinePolicyDocument), &inlineRolePolicyDocumentMap); err != nil {
-						log.Fatal(err)
-						return nil, err
+						fmt.Println(err)
+						return &tfContext, common.NewResourceError(resourceName, err)
 					}
-					inlineRolePolicyDocumentStr, err := duplosdk.JSONMarshal(inlineRolePolicyDocumentMap)
-					if err != nil {
-						panic(err)
+					if err := common.SetAttributeJsonencode(inlinePolicyBody, POLICY, common.InterpolateValues(inlineRolePolicyDocumentMap, policyInterpolations...)); err != nil {
+						fmt.Println(err)
+						return &tfContext, common.NewResourceError(resourceName, err)
 					}
-					inlinePolicyBody.SetAttributeTraversal(POLICY, hcl.Traversal{
-						hcl.TraverseRoot{
-							Name: "jsonencode(" + inlineRolePolicyDocumentStr + ")",
-						},
-					})
 				}
 
 			}
@@ -170,7 +166,7 @@ func (tenantIAM *TenantIAM) Generate(config *common.Config, client *duplosdk.Cli
 		listAttachedRolePoliciesOutput, err := iamClient.ListAttachedRolePolicies(context.TODO(), &iam.ListAttachedRolePoliciesInput{RoleName: &iamRoleName})
 		if err != nil {
 			fmt.Println(err)
-			return nil, err
+			return &tfContext, common.NewResourceError(resourceName, err)
 		}
 		// Add 'aws_iam_policy' for managed policies
 		if listAttachedRolePoliciesOutput != nil && len(listAttachedRolePoliciesOutput.AttachedPolicies) > 0 {
@@ -180,7 +176,7 @@ func (tenantIAM *TenantIAM) Generate(config *common.Config, client *duplosdk.Cli
 				})
 				if err != nil {
 					fmt.Println(err)
-					return nil, err
+					return &tfContext, common.NewResourceError(resourceName, err)
 				}
 				policyDetails := *getPolicyOutput.Policy
 				policyResourceName := common.GetResourceName(*policyDetails.PolicyName)
@@ -189,6 +185,10 @@ func (tenantIAM *TenantIAM) Generate(config *common.Config, client *duplosdk.Cli
 					[]string{AWS_IAM_POLICY,
 						policyResourceName})
 				iamPolicyBody := iamPolicyBlock.Body()
+				tfContext.References = append(tfContext.References, common.ResourceReference{
+					Value:   *policy.PolicyArn,
+					Address: AWS_IAM_POLICY + "." + policyResourceName + ".arn",
+				})
 				iamPolicyBody.SetAttributeValue(ROLE_NAME,
 					cty.StringVal(*policyDetails.PolicyName))
 				if policyDetails.Path != nil {
@@ -205,32 +205,23 @@ func (tenantIAM *TenantIAM) Generate(config *common.Config, client *duplosdk.Cli
 				})
 				if err != nil {
 					fmt.Println(err)
-					return nil, err
+					return &tfContext, common.NewResourceError(resourceName, err)
 				}
 				if getPolicyVersionOutput != nil && getPolicyVersionOutput.PolicyVersion.Document != nil {
 					decodedManagedPolicyDocument, err := url.QueryUnescape(*getPolicyVersionOutput.PolicyVersion.Document)
-					decodedManagedPolicyDocument = strings.Replace(decodedManagedPolicyDocument, iamRoleName, "${local.tenant_iam_role_name}", -1)
-					decodedManagedPolicyDocument = strings.Replace(decodedManagedPolicyDocument, config.TenantName, "${local.tenant_name}", -1)
-					accountIdStr := "${local.account_id}"
-					decodedManagedPolicyDocument = strings.Replace(decodedManagedPolicyDocument, config.AccountID, accountIdStr, -1)
 					if err != nil {
-						log.Fatal(err)
-						return nil, err
+						fmt.Println(err)
+						return &tfContext, common.NewResourceError(resourceName, err)
 					}
 					managedRolePolicyDocumentMap := make(map[string]interface{})
 					if err := json.Unmarshal([]byte(decodedManagedPolicyDocument), &managedRolePolicyDocumentMap); err != nil {
-						log.Fatal(err)
-						return nil, err
+						fmt.Println(err)
+						return &tfContext, common.NewResourceError(resourceName, err)
 					}
-					managedRolePolicyDocumentStr, err := duplosdk.JSONMarshal(managedRolePolicyDocumentMap)
-					if err != nil {
-						panic(err)
+					if err := common.SetAttributeJsonencode(iamPolicyBody, POLICY, common.InterpolateValues(managedRolePolicyDocumentMap, policyInterpolations...)); err != nil {
+						fmt.Println(err)
+						return &tfContext, common.NewResourceError(resourceName, err)
 					}
-					iamPolicyBody.SetAttributeTraversal(POLICY, hcl.Traversal{
-						hcl.TraverseRoot{
-							Name: "jsonencode(" + managedRolePolicyDocumentStr + ")",
-						},
-					})
 				}
 				// Add 'aws_iam_role_policy_attachment' resource
 				rootBody.AppendNewline()
@@ -239,28 +230,22 @@ func (tenantIAM *TenantIAM) Generate(config *common.Config, client *duplosdk.Cli
 						common.GetResourceName(*policyDetails.PolicyName) + "_attach"})
 				iamPolicyAtatchBody := iamPolicyAttachBlock.Body()
 
-				iamPolicyAtatchBody.SetAttributeTraversal(ROLE, hcl.Traversal{
-					hcl.TraverseRoot{
-						Name: strings.Join([]string{
-							AWS_IAM_ROLE,
-							resourceName,
-						}, "."),
-					},
-					hcl.TraverseAttr{
-						Name: "name",
-					},
-				})
-				iamPolicyAtatchBody.SetAttributeTraversal(POLICY_ARN, hcl.Traversal{
-					hcl.TraverseRoot{
-						Name: strings.Join([]string{
-							AWS_IAM_POLICY,
-							policyResourceName,
-						}, "."),
-					},
-					hcl.TraverseAttr{
-						Name: "arn",
-					},
-				})
+				if err := common.SetAttributeReference(iamPolicyAtatchBody, ROLE, strings.Join([]string{
+					AWS_IAM_ROLE,
+					resourceName,
+					"name",
+				}, ".")); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
+				if err := common.SetAttributeReference(iamPolicyAtatchBody, POLICY_ARN, strings.Join([]string{
+					AWS_IAM_POLICY,
+					policyResourceName,
+					"arn",
+				}, ".")); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
 				if config.GenerateTfState {
 					importConfigs = append(importConfigs, common.ImportConfig{
 						ResourceAddress: strings.Join([]string{
@@ -297,7 +282,7 @@ func (tenantIAM *TenantIAM) Generate(config *common.Config, client *duplosdk.Cli
 		_, err = tfFile.Write(hclFile.Bytes())
 		if err != nil {
 			fmt.Println(err)
-			return nil, err
+			return &tfContext, common.NewResourceError(resourceName, err)
 		}
 	}
 	return &tfContext, nil
@@ -12,8 +12,6 @@ import (
 
 	"tenant-native-terraform-generator/tf-generator/common"
 
-	"github.com/hashicorp/hcl/v2"
-	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -47,7 +45,7 @@ func (tenantKeyPair *TenantKeyPair) Generate(config *common.Config, client *dupl
 	})
 	if err != nil {
 		fmt.Println(err)
-		return nil, err
+		return &tfContext, err
 	}
 	resourceName := TENANT_KEYPAIR
 	hclFile := hclwrite.NewEmptyFile()
@@ -55,7 +53,7 @@ func (tenantKeyPair *TenantKeyPair) Generate(config *common.Config, client *dupl
 	tfFile, err := os.Create(path)
 	if err != nil {
 		fmt.Println(err)
-		return nil, err
+		return &tfContext, common.NewResourceError(resourceName, err)
 	}
 
 	inputVars := generateTenantKeyPairVars(describeKeyPairsOutput)
@@ -78,22 +76,19 @@ func (tenantKeyPair *TenantKeyPair) Generate(config *common.Config, client *dupl
 		[]string{AWS_KEY_PAIR,
 			resourceName})
 	kpBody := kpBlock.Body()
-	kpBody.SetAttributeTraversal(KEYPAIR_KEY_NAME, hcl.Traversal{
-		hcl.TraverseRoot{
-			Name: "local",
-		},
-		hcl.TraverseAttr{
-			Name: "tenant_prefix",
-		},
-	})
-	kpBody.SetAttributeTraversal(KEYPAIR_PUBLIC_KEY, hcl.Traversal{
-		hcl.TraverseRoot{
-			Name: "var",
-		},
-		hcl.TraverseAttr{
-			Name: "tenant_key_pair_public_key",
-		},
+	tfContext.References = append(tfContext.References, common.ResourceReference{
+		Value:      keyPairName,
+		Address:    AWS_KEY_PAIR + "." + resourceName + ".key_name",
+		Attributes: []string{KEY_NAME},
 	})
+	if err := common.SetAttributeReference(kpBody, KEYPAIR_KEY_NAME, "local.tenant_prefix"); err != nil {
+		fmt.Println(err)
+		return &tfContext, common.NewResourceError(resourceName, err)
+	}
+	if err := common.SetAttributeReference(kpBody, KEYPAIR_PUBLIC_KEY, "var.tenant_key_pair_public_key"); err != nil {
+		fmt.Println(err)
+		return &tfContext, common.NewResourceError(resourceName, err)
+	}
 	if describeKeyPairsOutput != nil && len(describeKeyPairsOutput.KeyPairs) > 0 && len(describeKeyPairsOutput.KeyPairs[0].Tags) > 0 {
 		newMap := make(map[string]cty.Value)
 		for _, tag := range describeKeyPairsOutput.KeyPairs[0].Tags {
@@ -101,15 +96,12 @@ func (tenantKeyPair *TenantKeyPair) Generate(config *common.Config, client *dupl
 		}
 		kpBody.SetAttributeValue(KEYPAIR_TAGS, cty.MapVal(newMap))
 	}
-	lifecycleBlock := kpBody.AppendNewBlock("lifecycle", nil)
-	lifecycleBody := lifecycleBlock.Body()
-	ignoreChanges := "public_key"
-	ignoreChangesTokens := hclwrite.Tokens{
-		{Type: hclsyntax.TokenOQuote, Bytes: []byte(`[`)},
-		{Type: hclsyntax.TokenIdent, Bytes: []byte(ignoreChanges)},
-		{Type: hclsyntax.TokenCQuote, Bytes: []byte(`]`)},
+	if err := common.AppendLifecycleBlock(kpBody, common.Lifecycle{
+		IgnoreChanges: []string{"public_key"},
+	}); err != nil {
+		fmt.Println(err)
+		return &tfContext, common.NewResourceError(resourceName, err)
 	}
-	lifecycleBody.SetAttributeRaw("ignore_changes", ignoreChangesTokens)
 
 	if config.GenerateTfState {
 		importConfigs = append(importConfigs, common.ImportConfig{
@@ -125,7 +117,7 @@ func (tenantKeyPair *TenantKeyPair) Generate(config *common.Config, client *dupl
 	_, err = tfFile.Write(hclFile.Bytes())
 	if err != nil {
 		fmt.Println(err)
-		return nil, err
+		return &tfContext, common.NewResourceError(resourceName, err)
 	}
 	return &tfContext, nil
 }
@@ -140,7 +132,7 @@ func generateTenantKeyPairVars(keypair *ec2.DescribeKeyPairsOutput) []common.Var
 	}
 	varConfigs["tenant_key_pair_public_key"] = publicKeyVar
 
-	vars := make([]common.VarConfig, len(varConfigs))
+	vars := make([]common.VarConfig, 0, len(varConfigs))
 	for _, v := range varConfigs {
 		vars = append(vars, v)
 	}
@@ -13,8 +13,6 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/service/iam"
 	"github.com/aws/aws-sdk-go-v2/service/kms"
-	"github.com/hashicorp/hcl/v2"
-	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -46,13 +44,13 @@ func (tenantKMS *TenantKMS) Generate(config *common.Config, client *duplosdk.Cli
 	duplo, clientErr := client.TenantGetTenantKmsKey(config.TenantId)
 	if clientErr != nil {
 		fmt.Println(clientErr)
-		return nil, clientErr
+		return &tfContext, common.NewResourceError(resourceName, clientErr)
 	}
 	kmsClient := kms.NewFromConfig(config.AwsClientConfig)
 	describeKeyOutput, err := kmsClient.DescribeKey(context.TODO(), &kms.DescribeKeyInput{KeyId: &duplo.KeyID})
 	if err != nil {
 		fmt.Println(err)
-		return nil, err
+		return &tfContext, common.NewResourceError(resourceName, err)
 	}
 	b, err := json.Marshal(describeKeyOutput)
 	if err != nil {
@@ -65,7 +63,7 @@ func (tenantKMS *TenantKMS) Generate(config *common.Config, client *duplosdk.Cli
 	getKeyPolicyOutput, err := kmsClient.GetKeyPolicy(context.TODO(), &kms.GetKeyPolicyInput{KeyId: &duplo.KeyID, PolicyName: &defaultPolicy})
 	if err != nil {
 		fmt.Println(err)
-		return nil, err
+		return &tfContext, common.NewResourceError(resourceName, err)
 	}
 
 	if describeKeyOutput != nil {
@@ -74,7 +72,7 @@ func (tenantKMS *TenantKMS) Generate(config *common.Config, client *duplosdk.Cli
 		tfFile, err := os.Create(path)
 		if err != nil {
 			fmt.Println(err)
-			return nil, err
+			return &tfContext, common.NewResourceError(resourceName, err)
 		}
 		rootBody := hclFile.Body()
 
@@ -82,16 +80,21 @@ func (tenantKMS *TenantKMS) Generate(config *common.Config, client *duplosdk.Cli
 			[]string{AWS_KMS_KEY,
 				resourceName})
 		kmsBody := kmsBlock.Body()
+		if describeKeyOutput.KeyMetadata != nil && describeKeyOutput.KeyMetadata.Arn != nil {
+			tfContext.References = append(tfContext.References, common.ResourceReference{
+				Value:   *describeKeyOutput.KeyMetadata.Arn,
+				Address: AWS_KMS_KEY + "." + resourceName + ".arn",
+			}, common.ResourceReference{
+				Value:   *describeKeyOutput.KeyMetadata.KeyId,
+				Address: AWS_KMS_KEY + "." + resourceName + ".key_id",
+			})
+		}
 
 		if describeKeyOutput.KeyMetadata != nil && describeKeyOutput.KeyMetadata.Description != nil {
-			kmsBody.SetAttributeTraversal(KMS_DESCRIPTION, hcl.Traversal{
-				hcl.TraverseRoot{
-					Name: "local",
-				},
-				hcl.TraverseAttr{
-					Name: "tenant_prefix",
-				},
-			})
+			if err := common.SetAttributeReference(kmsBody, KMS_DESCRIPTION, "local.tenant_prefix"); err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
 		}
 		if describeKeyOutput.KeyMetadata != nil && len(describeKeyOutput.KeyMetadata.KeyUsage) > 0 {
 			kmsBody.SetAttributeValue(KMS_KEY_USAGE,
@@ -104,7 +107,7 @@ func (tenantKMS *TenantKMS) Generate(config *common.Config, client *duplosdk.Cli
 		keyRotationStatus, err := kmsClient.GetKeyRotationStatus(context.TODO(), &kms.GetKeyRotationStatusInput{KeyId: describeKeyOutput.KeyMetadata.KeyId})
 		if err != nil {
 			fmt.Println(err)
-			return nil, err
+			return &tfContext, common.NewResourceError(resourceName, err)
 		}
 		// b, err := json.Marshal(keyRotationStatus)
 		// if err != nil {
@@ -124,30 +127,21 @@ func (tenantKMS *TenantKMS) Generate(config *common.Config, client *duplosdk.Cli
 			getRoleOutput, err := iamClient.GetRole(context.TODO(), &iam.GetRoleInput{RoleName: &iamRoleName})
 			if err != nil {
 				fmt.Println(err)
-				return nil, err
+				return &tfContext, common.NewResourceError(resourceName, err)
 			}
 			var policyMap interface{}
 			err = json.Unmarshal([]byte(*getKeyPolicyOutput.Policy), &policyMap)
 			if err != nil {
-				panic(err)
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
 			}
-			policyMapStr, err := duplosdk.JSONMarshal(policyMap)
-			if err != nil {
-				panic(err)
+			policy := common.InterpolateValues(policyMap,
+				common.Interpolation{Value: *getRoleOutput.Role.Arn, Expr: AWS_IAM_ROLE + "." + TENANT_IAM + ".arn"},
+				common.Interpolation{Value: config.AccountID, Expr: "local.account_id"})
+			if err := common.SetAttributeJsonencode(kmsBody, KMS_POLICY, policy); err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
 			}
-			replaceStr := strings.Join([]string{"${" +
-				AWS_IAM_ROLE,
-				TENANT_IAM, "arn}",
-			}, ".")
-			accountIdStr := "${local.account_id}"
-			policyMapStr = strings.Replace(policyMapStr, *getRoleOutput.Role.Arn, replaceStr, -1)
-			policyMapStr = strings.Replace(policyMapStr, config.AccountID, accountIdStr, -1)
-
-			kmsBody.SetAttributeTraversal(KMS_POLICY, hcl.Traversal{
-				hcl.TraverseRoot{
-					Name: "jsonencode(" + policyMapStr + ")",
-				},
-			})
 		}
 		rootBody.AppendNewline()
 		kmsAliasBlock := rootBody.AppendNewBlock("resource",
@@ -155,22 +149,16 @@ func (tenantKMS *TenantKMS) Generate(config *common.Config, client *duplosdk.Cli
 				resourceName})
 		kmsAliasBody := kmsAliasBlock.Body()
 
-		alias := "alias/${local.tenant_prefix}"
-		aliasTokens := hclwrite.Tokens{
-			{Type: hclsyntax.TokenOQuote, Bytes: []byte(`"`)},
-			{Type: hclsyntax.TokenIdent, Bytes: []byte(alias)},
-			{Type: hclsyntax.TokenCQuote, Bytes: []byte(`"`)},
+		alias := common.Template("alias/${local.tenant_prefix}")
+		if err := common.SetAttributeTemplate(kmsAliasBody, KMS_NAME, alias); err != nil {
+			fmt.Println(err)
+			return &tfContext, common.NewResourceError(resourceName, err)
 		}
-		kmsAliasBody.SetAttributeRaw(KMS_NAME, aliasTokens)
 
-		kmsAliasBody.SetAttributeTraversal(KMS_TARGET_KEY_ID, hcl.Traversal{
-			hcl.TraverseRoot{
-				Name: "aws_kms_key." + resourceName,
-			},
-			hcl.TraverseAttr{
-				Name: "key_id",
-			},
-		})
+		if err := common.SetAttributeReference(kmsAliasBody, KMS_TARGET_KEY_ID, "aws_kms_key."+resourceName+".key_id"); err != nil {
+			fmt.Println(err)
+			return &tfContext, common.NewResourceError(resourceName, err)
+		}
 
 		if config.GenerateTfState {
 			importConfigs = append(importConfigs, common.ImportConfig{
@@ -193,9 +181,18 @@ func (tenantKMS *TenantKMS) Generate(config *common.Config, client *duplosdk.Cli
 		_, err = tfFile.Write(hclFile.Bytes())
 		if err != nil {
 			fmt.Println(err)
-			return nil, err
+			return &tfContext, common.NewResourceError(resourceName, err)
 		}
 	}
 
 	return &tfContext, nil
 }
+
+// isTenantKmsKey reports whether a key id, key ARN or alias ARN identifies the tenant KMS key.
+func isTenantKmsKey(tenantKms *duplosdk.DuploAwsKmsKey, keyId string) bool {
+	if tenantKms == nil || len(keyId) == 0 {
+		return false
+	}
+	return keyId == tenantKms.KeyID || keyId == tenantKms.KeyArn || keyId == tenantKms.Arn ||
+		strings.HasSuffix(keyId, ":key/"+tenantKms.KeyID)
+}
@@ -0,0 +1,304 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"tenant-native-terraform-generator/duplosdk"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"tenant-native-terraform-generator/tf-generator/common"
+)
+
+const (
+	SG_RULE_TYPE                     string = "type"
+	SG_RULE_SECURITY_GROUP_ID        string = "security_group_id"
+	SG_RULE_SOURCE_SECURITY_GROUP_ID string = "source_security_group_id"
+)
+
+const AWS_SECURITY_GROUP_RULE = "aws_security_group_rule"
+const SG_RULE_FILE_NAME = "tenant-sg-ext-conn.tf"
+
+type TenantSGRule struct {
+}
+
+// extConnSGRule is a single source of a duplo external connection rule, as it is written to terraform.
+type extConnSGRule struct {
+	ResourceName  string
+	Protocol      string
+	FromPort      int32
+	ToPort        int32
+	CidrBlock     string
+	SourceGroupId string
+	SourceTenant  string
+	Description   string
+}
+
+func (tenantSGRule *TenantSGRule) Generate(config *common.Config, client *duplosdk.Client) (*common.TFContext, error) {
+	workingDir := filepath.Join(config.TFCodePath, config.TenantProject)
+	ec2Client := ec2.NewFromConfig(config.AwsClientConfig)
+	rules, err := getExtConnSGRules(config, client, ec2Client)
+	if err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+	tfContext := common.TFContext{}
+	importConfigs := []common.ImportConfig{}
+	if len(rules) > 0 {
+		log.Println("[TRACE] <====== Tenant external connection SG rule TF generation started. =====>")
+		tenantSGId, err := getTenantSGId(config, ec2Client)
+		if err != nil {
+			fmt.Println(err)
+			return &tfContext, err
+		}
+		hclFile := hclwrite.NewEmptyFile()
+		path := filepath.Join(workingDir, SG_RULE_FILE_NAME)
+		tfFile, err := os.Create(path)
+		if err != nil {
+			fmt.Println(err)
+			return &tfContext, err
+		}
+		rootBody := hclFile.Body()
+		for _, rule := range rules {
+			// Add aws_security_group_rule resource
+			ruleBlock := rootBody.AppendNewBlock("resource",
+				[]string{AWS_SECURITY_GROUP_RULE,
+					rule.ResourceName})
+			ruleBody := ruleBlock.Body()
+			ruleBody.SetAttributeValue(SG_RULE_TYPE,
+				cty.StringVal(SG_INGRESS))
+			if err := common.SetAttributeReference(ruleBody, SG_RULE_SECURITY_GROUP_ID, AWS_SECURITY_GROUP+"."+common.GetResourceName("duploservices-"+config.TenantName)+".id"); err != nil {
+				fmt.Println(err)
+				return &tfContext, err
+			}
+			ruleBody.SetAttributeValue(SG_PROTOCOL,
+				cty.StringVal(rule.Protocol))
+			ruleBody.SetAttributeValue(SG_FROM_PORT,
+				cty.NumberIntVal(int64(rule.FromPort)))
+			ruleBody.SetAttributeValue(SG_TO_PORT,
+				cty.NumberIntVal(int64(rule.ToPort)))
+			if len(rule.CidrBlock) > 0 {
+				ruleBody.SetAttributeValue(SG_CIDR_BLOCKS,
+					cty.ListVal([]cty.Value{cty.StringVal(rule.CidrBlock)}))
+			} else if rule.SourceTenant == config.TenantName {
+				ruleBody.SetAttributeValue(SG_SELF,
+					cty.BoolVal(true))
+			} else {
+				ruleBody.SetAttributeValue(SG_RULE_SOURCE_SECURITY_GROUP_ID,
+					cty.StringVal(rule.SourceGroupId))
+			}
+			if len(rule.Description) > 0 {
+				ruleBody.SetAttributeValue(SG_DESCRIPTION,
+					cty.StringVal(rule.Description))
+			}
+			rootBody.AppendNewline()
+
+			if config.GenerateTfState {
+				source := rule.CidrBlock
+				if rule.SourceTenant == config.TenantName {
+					source = SG_SELF
+				} else if len(source) == 0 {
+					source = rule.SourceGroupId
+				}
+				protocol := rule.Protocol
+				if protocol == "-1" {
+					protocol = "all"
+				}
+				importConfigs = append(importConfigs, common.ImportConfig{
+					ResourceAddress: strings.Join([]string{
+						AWS_SECURITY_GROUP_RULE,
+						rule.ResourceName,
+					}, "."),
+					ResourceId: strings.Join([]string{
+						tenantSGId,
+						SG_INGRESS,
+						protocol,
+						strconv.Itoa(int(rule.FromPort)),
+						strconv.Itoa(int(rule.ToPort)),
+						source,
+					}, "_"),
+					WorkingDir: workingDir,
+				})
+				tfContext.ImportConfigs = importConfigs
+			}
+		}
+		_, err = tfFile.Write(hclFile.Bytes())
+		if err != nil {
+			fmt.Println(err)
+			return &tfContext, err
+		}
+		log.Println("[TRACE] <====== Tenant external connection SG rule TF generation done. =====>")
+	}
+	return &tfContext, nil
+}
+
+// extConnSGRules keeps the external connection rules of each tenant, TenantSG and TenantSGRule both need them
+// and run concurrently.
+var extConnSGRules = struct {
+	sync.Mutex
+	byTenant map[string][]extConnSGRule
+}{byTenant: map[string][]extConnSGRule{}}
+
+// getExtConnSGRules returns the external connection rules of the tenant, they are fetched once and shared.
+func getExtConnSGRules(config *common.Config, client *duplosdk.Client, ec2Client *ec2.Client) ([]extConnSGRule, error) {
+	extConnSGRules.Lock()
+	defer extConnSGRules.Unlock()
+	if rules, ok := extConnSGRules.byTenant[config.TenantId]; ok {
+		return rules, nil
+	}
+	rules, err := fetchExtConnSGRules(config, client, ec2Client)
+	if err != nil {
+		return nil, err
+	}
+	extConnSGRules.byTenant[config.TenantId] = rules
+	return rules, nil
+}
+
+// fetchExtConnSGRules flattens the duplo external connection rules of the tenant, one rule per source.
+// A source is either a CIDR block or the name of another tenant whose security group is allowed in.
+func fetchExtConnSGRules(config *common.Config, client *duplosdk.Client, ec2Client *ec2.Client) ([]extConnSGRule, error) {
+	list, clientErr := client.TenantGetExtConnSecurityGroupRules(config.TenantId)
+	if clientErr != nil {
+		return nil, clientErr
+	}
+	rules := []extConnSGRule{}
+	generatedNames := map[string]bool{}
+	for _, extRule := range *list {
+		if extRule.Sources == nil {
+			continue
+		}
+		protocol := strings.ToLower(extRule.Protocol)
+		if len(protocol) == 0 || protocol == "all" {
+			protocol = "-1"
+		}
+		for _, source := range *extRule.Sources {
+			rule := extConnSGRule{
+				Protocol:    protocol,
+				FromPort:    int32(extRule.FromPort),
+				ToPort:      int32(extRule.ToPort),
+				Description: source.Description,
+			}
+			if _, _, err := net.ParseCIDR(source.Value); err == nil {
+				rule.CidrBlock = source.Value
+			} else if ip := net.ParseIP(source.Value); ip != nil {
+				rule.CidrBlock = source.Value + "/32"
+			} else {
+				sourceTenantSGName := "duploservices-" + source.Value
+				groupIds, err := getSecurityGroupIdsByName(ec2Client, sourceTenantSGName)
+				if err != nil {
+					return nil, err
+				}
+				if len(groupIds) == 0 {
+					log.Printf("[TRACE] Skipping external connection rule source (%s), security group is not found.", source.Value)
+					continue
+				}
+				rule.SourceGroupId = groupIds[0]
+				rule.SourceTenant = source.Value
+			}
+			resourceName := common.GetResourceName(strings.Join([]string{
+				"ext_conn",
+				strings.Replace(protocol, "-1", "all", 1),
+				strconv.Itoa(extRule.FromPort),
+				strconv.Itoa(extRule.ToPort),
+				source.Value,
+			}, "_"))
+			if generatedNames[resourceName] {
+				continue
+			}
+			generatedNames[resourceName] = true
+			rule.ResourceName = resourceName
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+// removeExtConnPermissions drops the permissions which are generated as separate external connection rules.
+func removeExtConnPermissions(permissions []types.IpPermission, rules []extConnSGRule) []types.IpPermission {
+	filtered := []types.IpPermission{}
+	for _, permission := range permissions {
+		var fromPort, toPort int32
+		if permission.FromPort != nil {
+			fromPort = *permission.FromPort
+		}
+		if permission.ToPort != nil {
+			toPort = *permission.ToPort
+		}
+		matches := func(rule extConnSGRule) bool {
+			return permission.IpProtocol != nil && *permission.IpProtocol == rule.Protocol && fromPort == rule.FromPort && toPort == rule.ToPort
+		}
+		ipRanges := []types.IpRange{}
+		for _, ipRange := range permission.IpRanges {
+			found := false
+			for _, rule := range rules {
+				if matches(rule) && ipRange.CidrIp != nil && *ipRange.CidrIp == rule.CidrBlock {
+					found = true
+					break
+				}
+			}
+			if !found {
+				ipRanges = append(ipRanges, ipRange)
+			}
+		}
+		groupPairs := []types.UserIdGroupPair{}
+		for _, groupPair := range permission.UserIdGroupPairs {
+			found := false
+			for _, rule := range rules {
+				if matches(rule) && groupPair.GroupId != nil && *groupPair.GroupId == rule.SourceGroupId {
+					found = true
+					break
+				}
+			}
+			if !found {
+				groupPairs = append(groupPairs, groupPair)
+			}
+		}
+		permission.IpRanges = ipRanges
+		permission.UserIdGroupPairs = groupPairs
+		if len(permission.IpRanges) > 0 || len(permission.Ipv6Ranges) > 0 || len(permission.PrefixListIds) > 0 || len(permission.UserIdGroupPairs) > 0 {
+			filtered = append(filtered, permission)
+		}
+	}
+	return filtered
+}
+
+func getTenantSGId(config *common.Config, ec2Client *ec2.Client) (string, error) {
+	groupIds, err := getSecurityGroupIdsByName(ec2Client, "duploservices-"+config.TenantName)
+	if err != nil {
+		return "", err
+	}
+	if len(groupIds) == 0 {
+		return "", fmt.Errorf("tenant security group duploservices-%s is not found", config.TenantName)
+	}
+	return groupIds[0], nil
+}
+
+func getSecurityGroupIdsByName(ec2Client *ec2.Client, name string) ([]string, error) {
+	filterName := "group-name"
+	output, err := ec2Client.DescribeSecurityGroups(context.TODO(), &ec2.DescribeSecurityGroupsInput{
+		Filters: []types.Filter{
+			{
+				Name:   &filterName,
+				Values: []string{name},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	groupIds := []string{}
+	for _, sg := range output.SecurityGroups {
+		groupIds = append(groupIds, *sg.GroupId)
+	}
+	return groupIds, nil
+}
@@ -6,11 +6,11 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"tenant-native-terraform-generator/duplosdk"
 
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
-	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/zclconf/go-cty/cty"
 
@@ -30,7 +30,6 @@ const (
 	SG_IPV6_CIDR_BLOCKS string = "ipv6_cidr_blocks"
 	SG_TAGS             string = "tags"
 	SG_PREFIX_LIST_IDS  string = "prefix_list_ids"
-	SG_SECURITY_GROUPS  string = "security_groups"
 	SG_SELF             string = "self"
 	SG_INGRESS          string = "ingress"
 	SG_EGRESS           string = "egress"
@@ -59,6 +58,13 @@ func (tenantSG *TenantSG) Generate(config *common.Config, client *duplosdk.Clien
 			},
 		},
 	})
+	if err != nil {
+		fmt.Println(err)
+		return &tfContext, err
+	}
+
+	// The external connection rules are left out of the tenant security group, they would be generated twice otherwise.
+	extConnRules, err := getExtConnSGRules(config, client, ec2Client)
 	if err != nil {
 		fmt.Println(err)
 		return nil, err
@@ -70,7 +76,7 @@ func (tenantSG *TenantSG) Generate(config *common.Config, client *duplosdk.Clien
 		tfFile, err := os.Create(path)
 		if err != nil {
 			fmt.Println(err)
-			return nil, err
+			return &tfContext, err
 		}
 		// b, err := json.Marshal(describeSecurityGroupsOutput)
 		// if err != nil {
@@ -87,37 +93,29 @@ func (tenantSG *TenantSG) Generate(config *common.Config, client *duplosdk.Clien
 				[]string{AWS_SECURITY_GROUP,
 					resourceName})
 			sgBody := sgBlock.Body()
+			tfContext.References = append(tfContext.References, common.ResourceReference{
+				Value:   *sg.GroupId,
+				Address: AWS_SECURITY_GROUP + "." + resourceName + ".id",
+			})
 			// sgBody.SetAttributeValue(SG_NAME,
 			// 	cty.StringVal(*sg.GroupName))
 			if "duploservices-"+config.TenantName == *sg.GroupName {
-				sgBody.SetAttributeTraversal(SG_NAME, hcl.Traversal{
-					hcl.TraverseRoot{
-						Name: "local",
-					},
-					hcl.TraverseAttr{
-						Name: "tenant_sg_name",
-					},
-				})
+				if err := common.SetAttributeReference(sgBody, SG_NAME, "local.tenant_sg_name"); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
 			}
 			if "duploservices-"+config.TenantName+"-lb" == *sg.GroupName {
-				sgBody.SetAttributeTraversal(SG_NAME, hcl.Traversal{
-					hcl.TraverseRoot{
-						Name: "local",
-					},
-					hcl.TraverseAttr{
-						Name: "tenant_lb_sg_name",
-					},
-				})
+				if err := common.SetAttributeReference(sgBody, SG_NAME, "local.tenant_lb_sg_name"); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
 			}
 			if "duploservices-"+config.TenantName+"-alb" == *sg.GroupName {
-				sgBody.SetAttributeTraversal(SG_NAME, hcl.Traversal{
-					hcl.TraverseRoot{
-						Name: "local",
-					},
-					hcl.TraverseAttr{
-						Name: "tenant_alb_sg_name",
-					},
-				})
+				if err := common.SetAttributeReference(sgBody, SG_NAME, "local.tenant_alb_sg_name"); err != nil {
+					fmt.Println(err)
+					return &tfContext, common.NewResourceError(resourceName, err)
+				}
 			}
 			if sg.Description != nil && len(*sg.Description) > 0 {
 				// desc := *sg.Description
@@ -125,172 +123,15 @@ func (tenantSG *TenantSG) Generate(config *common.Config, client *duplosdk.Clien
 				sgBody.SetAttributeValue(SG_DESCRIPTION,
 					cty.StringVal(*sg.Description))
 			}
-			sgBody.SetAttributeTraversal(SG_VPC_ID, hcl.Traversal{
-				hcl.TraverseRoot{
-					Name: "local",
-				},
-				hcl.TraverseAttr{
-					Name: SG_VPC_ID,
-				},
-			})
-			if len(sg.IpPermissions) > 0 {
-				for _, ingress := range sg.IpPermissions {
-					ingressBlock := sgBody.AppendNewBlock(SG_INGRESS,
-						nil)
-					ingressBody := ingressBlock.Body()
-					if ingress.FromPort != nil {
-						ingressBody.SetAttributeValue(SG_FROM_PORT,
-							cty.NumberIntVal(int64(*ingress.FromPort)))
-					} else {
-						ingressBody.SetAttributeValue(SG_FROM_PORT,
-							cty.NumberIntVal(int64(0)))
-					}
-					if ingress.ToPort != nil {
-						ingressBody.SetAttributeValue(SG_TO_PORT,
-							cty.NumberIntVal(int64(*ingress.ToPort)))
-					} else {
-						ingressBody.SetAttributeValue(SG_TO_PORT,
-							cty.NumberIntVal(int64(0)))
-					}
-
-					ingressBody.SetAttributeValue(SG_PROTOCOL,
-						cty.StringVal(*ingress.IpProtocol))
-
-					if len(ingress.IpRanges) > 0 {
-						desc := ""
-						var vals []cty.Value
-						for _, s := range ingress.IpRanges {
-							if s.Description != nil {
-								desc = *s.Description
-							}
-							vals = append(vals, cty.StringVal(*s.CidrIp))
-						}
-						ingressBody.SetAttributeValue(SG_CIDR_BLOCKS,
-							cty.ListVal(vals))
-						if len(desc) > 0 {
-							ingressBody.SetAttributeValue(SG_DESCRIPTION,
-								cty.StringVal(desc))
-						}
-
-					}
-					if len(ingress.Ipv6Ranges) > 0 {
-						desc := ""
-						var vals []cty.Value
-						for _, s := range ingress.Ipv6Ranges {
-							if s.Description != nil {
-								desc = *s.Description
-							}
-							vals = append(vals, cty.StringVal(*s.CidrIpv6))
-						}
-						ingressBody.SetAttributeValue(SG_IPV6_CIDR_BLOCKS,
-							cty.ListVal(vals))
-						if len(desc) > 0 {
-							ingressBody.SetAttributeValue(SG_DESCRIPTION,
-								cty.StringVal(desc))
-						}
-					}
-					if len(ingress.PrefixListIds) > 0 {
-						var vals []cty.Value
-						for _, s := range ingress.PrefixListIds {
-							vals = append(vals, cty.StringVal(*s.PrefixListId))
-						}
-						ingressBody.SetAttributeValue(SG_PREFIX_LIST_IDS,
-							cty.ListVal(vals))
-					}
-					if len(ingress.UserIdGroupPairs) > 0 {
-						var vals []cty.Value
-						sgid := ""
-						desc := ""
-						for _, s := range ingress.UserIdGroupPairs {
-							vals = append(vals, cty.StringVal(*s.GroupId))
-							sgid = *s.GroupId
-							if s.Description != nil {
-								desc = *s.Description
-							}
-						}
-
-						if len(desc) > 0 {
-							ingressBody.SetAttributeValue(SG_DESCRIPTION,
-								cty.StringVal(desc))
-						}
-						if len(vals) == 1 && sgid == *sg.GroupId {
-							ingressBody.SetAttributeValue(SG_SELF,
-								cty.BoolVal(true))
-						} else {
-
-							ingressBody.SetAttributeValue(SG_SECURITY_GROUPS,
-								cty.ListVal(vals))
-						}
-					}
-
-				}
+			if err := common.SetAttributeReference(sgBody, SG_VPC_ID, "local."+SG_VPC_ID); err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
 			}
-			if len(sg.IpPermissionsEgress) > 0 {
-				for _, egress := range sg.IpPermissionsEgress {
-					egressBlock := sgBody.AppendNewBlock(SG_EGRESS,
-						nil)
-					egressBody := egressBlock.Body()
-					if egress.FromPort != nil {
-						egressBody.SetAttributeValue(SG_FROM_PORT,
-							cty.NumberIntVal(int64(*egress.FromPort)))
-					} else {
-						egressBody.SetAttributeValue(SG_FROM_PORT,
-							cty.NumberIntVal(int64(0)))
-					}
-					if egress.ToPort != nil {
-						egressBody.SetAttributeValue(SG_TO_PORT,
-							cty.NumberIntVal(int64(*egress.ToPort)))
-					} else {
-						egressBody.SetAttributeValue(SG_TO_PORT,
-							cty.NumberIntVal(int64(0)))
-					}
-
-					egressBody.SetAttributeValue(SG_PROTOCOL,
-						cty.StringVal(*egress.IpProtocol))
-
-					if len(egress.IpRanges) > 0 {
-						var vals []cty.Value
-						for _, s := range egress.IpRanges {
-							vals = append(vals, cty.StringVal(*s.CidrIp))
-						}
-						egressBody.SetAttributeValue(SG_CIDR_BLOCKS,
-							cty.ListVal(vals))
-					}
-					if len(egress.Ipv6Ranges) > 0 {
-						var vals []cty.Value
-						for _, s := range egress.Ipv6Ranges {
-							vals = append(vals, cty.StringVal(*s.CidrIpv6))
-						}
-						egressBody.SetAttributeValue(SG_IPV6_CIDR_BLOCKS,
-							cty.ListVal(vals))
-					}
-					if len(egress.PrefixListIds) > 0 {
-						var vals []cty.Value
-						for _, s := range egress.PrefixListIds {
-							vals = append(vals, cty.StringVal(*s.PrefixListId))
-						}
-						egressBody.SetAttributeValue(SG_PREFIX_LIST_IDS,
-							cty.ListVal(vals))
-					}
-					if len(egress.UserIdGroupPairs) > 0 {
-						var vals []cty.Value
-						sgid := ""
-						for _, s := range egress.UserIdGroupPairs {
-							vals = append(vals, cty.StringVal(*s.GroupId))
-							sgid = *s.GroupId
-						}
-						if len(vals) == 1 && sgid == *sg.GroupId {
-							egressBody.SetAttributeValue(SG_SELF,
-								cty.BoolVal(true))
-						} else {
-							egressBody.SetAttributeValue(SG_SECURITY_GROUPS,
-								cty.ListVal(vals))
-						}
-					}
-
-				}
+			// External connection rules are generated in their own file, they are left out here.
+			isExtConnSG := len(extConnRules) > 0 && "duploservices-"+config.TenantName == *sg.GroupName
+			if isExtConnSG {
+				sg.IpPermissions = removeExtConnPermissions(sg.IpPermissions, extConnRules)
 			}
-
 			if len(sg.Tags) > 0 {
 				newMap := make(map[string]cty.Value)
 				for _, tag := range sg.Tags {
@@ -313,13 +154,194 @@ func (tenantSG *TenantSG) Generate(config *common.Config, client *duplosdk.Clien
 				tfContext.ImportConfigs = importConfigs
 			}
 			rootBody.AppendNewline()
+
+			// Rules are separate resources, inline rules would revoke the external connection rules and
+			// security groups allowing each other in would depend on each other.
+			ingressImportConfigs, err := appendSGRules(config, rootBody, sg, resourceName, SG_INGRESS, sg.IpPermissions, workingDir)
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			egressImportConfigs, err := appendSGRules(config, rootBody, sg, resourceName, SG_EGRESS, sg.IpPermissionsEgress, workingDir)
+			if err != nil {
+				fmt.Println(err)
+				return &tfContext, common.NewResourceError(resourceName, err)
+			}
+			importConfigs = append(importConfigs, ingressImportConfigs...)
+			importConfigs = append(importConfigs, egressImportConfigs...)
+			if config.GenerateTfState {
+				tfContext.ImportConfigs = importConfigs
+			}
 			log.Printf("[TRACE] Terraform config generation done for aws security group (%s).", *sg.GroupName)
 		}
 		_, err = tfFile.Write(hclFile.Bytes())
 		if err != nil {
 			fmt.Println(err)
-			return nil, err
+			return &tfContext, err
 		}
 	}
 	return &tfContext, nil
 }
+
+// appendSGRules adds an aws_security_group_rule per permission and source of a security group,
+// a rule allows a single cidr block, prefix list, source security group or the group itself, with its own description.
+func appendSGRules(config *common.Config, rootBody *hclwrite.Body, sg types.SecurityGroup, sgResourceName string, ruleType string, permissions []types.IpPermission, workingDir string) ([]common.ImportConfig, error) {
+	importConfigs := []common.ImportConfig{}
+	generatedNames := map[string]bool{}
+	for _, permission := range permissions {
+		var fromPort, toPort int32
+		if permission.FromPort != nil {
+			fromPort = *permission.FromPort
+		}
+		if permission.ToPort != nil {
+			toPort = *permission.ToPort
+		}
+		protocol := *permission.IpProtocol
+		appendRule := func(sources []string, setSource func(ruleBody *hclwrite.Body), desc string) error {
+			resourceName := common.GetResourceName(strings.Join([]string{
+				sgResourceName,
+				ruleType,
+				strings.Replace(protocol, "-1", "all", 1),
+				strconv.Itoa(int(fromPort)),
+				strconv.Itoa(int(toPort)),
+				sources[0],
+			}, "_"))
+			for i := 2; generatedNames[resourceName]; i++ {
+				resourceName = common.GetResourceName(strings.Join([]string{sgResourceName, ruleType, strconv.Itoa(i)}, "_"))
+			}
+			generatedNames[resourceName] = true
+
+			ruleBlock := rootBody.AppendNewBlock("resource",
+				[]string{AWS_SECURITY_GROUP_RULE,
+					resourceName})
+			ruleBody := ruleBlock.Body()
+			ruleBody.SetAttributeValue(SG_RULE_TYPE,
+				cty.StringVal(ruleType))
+			if err := common.SetAttributeReference(ruleBody, SG_RULE_SECURITY_GROUP_ID, AWS_SECURITY_GROUP+"."+sgResourceName+".id"); err != nil {
+				return err
+			}
+			ruleBody.SetAttributeValue(SG_PROTOCOL,
+				cty.StringVal(protocol))
+			ruleBody.SetAttributeValue(SG_FROM_PORT,
+				cty.NumberIntVal(int64(fromPort)))
+			ruleBody.SetAttributeValue(SG_TO_PORT,
+				cty.NumberIntVal(int64(toPort)))
+			setSource(ruleBody)
+			if len(desc) > 0 {
+				ruleBody.SetAttributeValue(SG_DESCRIPTION,
+					cty.StringVal(desc))
+			}
+			rootBody.AppendNewline()
+
+			if config.GenerateTfState {
+				importProtocol := protocol
+				if importProtocol == "-1" {
+					importProtocol = "all"
+				}
+				importConfigs = append(importConfigs, common.ImportConfig{
+					ResourceAddress: strings.Join([]string{
+						AWS_SECURITY_GROUP_RULE,
+						resourceName,
+					}, "."),
+					ResourceId: strings.Join(append([]string{
+						*sg.GroupId,
+						ruleType,
+						importProtocol,
+						strconv.Itoa(int(fromPort)),
+						strconv.Itoa(int(toPort)),
+					}, sources...), "_"),
+					WorkingDir: workingDir,
+				})
+			}
+			return nil
+		}
+
+		for _, ipRange := range permission.IpRanges {
+			desc := ""
+			if ipRange.Description != nil {
+				desc = *ipRange.Description
+			}
+			cidr := *ipRange.CidrIp
+			err := appendRule([]string{cidr}, func(ruleBody *hclwrite.Body) {
+				ruleBody.SetAttributeValue(SG_CIDR_BLOCKS,
+					cty.ListVal([]cty.Value{cty.StringVal(cidr)}))
+			}, desc)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, ipv6Range := range permission.Ipv6Ranges {
+			desc := ""
+			if ipv6Range.Description != nil {
+				desc = *ipv6Range.Description
+			}
+			cidr := *ipv6Range.CidrIpv6
+			err := appendRule([]string{cidr}, func(ruleBody *hclwrite.Body) {
+				ruleBody.SetAttributeValue(SG_IPV6_CIDR_BLOCKS,
+					cty.ListVal([]cty.Value{cty.StringVal(cidr)}))
+			}, desc)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, prefixList := range permission.PrefixListIds {
+			desc := ""
+			if prefixList.Description != nil {
+				desc = *prefixList.Description
+			}
+			prefixListId := *prefixList.PrefixListId
+			err := appendRule([]string{prefixListId}, func(ruleBody *hclwrite.Body) {
+				ruleBody.SetAttributeValue(SG_PREFIX_LIST_IDS,
+					cty.ListVal([]cty.Value{cty.StringVal(prefixListId)}))
+			}, desc)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, groupPair := range permission.UserIdGroupPairs {
+			desc := ""
+			if groupPair.Description != nil {
+				desc = *groupPair.Description
+			}
+			groupId := *groupPair.GroupId
+			var err error
+			if groupId == *sg.GroupId {
+				err = appendRule([]string{SG_SELF}, func(ruleBody *hclwrite.Body) {
+					ruleBody.SetAttributeValue(SG_SELF,
+						cty.BoolVal(true))
+				}, desc)
+			} else {
+				err = appendRule([]string{groupId}, func(ruleBody *hclwrite.Body) {
+					ruleBody.SetAttributeValue(SG_RULE_SOURCE_SECURITY_GROUP_ID,
+						cty.StringVal(groupId))
+				}, desc)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return importConfigs, nil
+}
+
+// getSecurityGroupIdsTokens returns a tuple of security group ids, referencing the generated tenant security groups where possible.
+func getSecurityGroupIdsTokens(config *common.Config, ec2Client *ec2.Client, groupIds []string) (hclwrite.Tokens, error) {
+	tenantSGNames := []string{"duploservices-" + config.TenantName, "duploservices-" + config.TenantName + "-lb", "duploservices-" + config.TenantName + "-alb"}
+	describeSGOutput, err := ec2Client.DescribeSecurityGroups(context.TODO(), &ec2.DescribeSecurityGroupsInput{GroupIds: groupIds})
+	if err != nil {
+		return nil, err
+	}
+	sgTokens := []hclwrite.Tokens{}
+	for _, sg := range describeSGOutput.SecurityGroups {
+		if sg.GroupName != nil && common.Contains(tenantSGNames, *sg.GroupName) {
+			referenceTokens, err := common.TokensForReference(AWS_SECURITY_GROUP + "." + common.GetResourceName(*sg.GroupName) + ".id")
+			if err != nil {
+				return nil, err
+			}
+			sgTokens = append(sgTokens, referenceTokens)
+		} else {
+			sgTokens = append(sgTokens, hclwrite.TokensForValue(cty.StringVal(*sg.GroupId)))
+		}
+	}
+	return hclwrite.TokensForTuple(sgTokens), nil
+}